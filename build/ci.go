@@ -72,6 +72,7 @@ var (
 		executablePath("abigen"),
 		executablePath("bootnode"),
 		executablePath("echainspec"),
+		executablePath("ethashb3"),
 		executablePath("evm"),
 		executablePath("geth"),
 		executablePath("rlpdump"),
@@ -92,6 +93,10 @@ var (
 			BinaryName:  "echainspec",
 			Description: "Developer utility to manage chain external chain configuration",
 		},
+		{
+			BinaryName:  "ethashb3",
+			Description: "Developer utility to inspect, verify and benchmark the ethashb3 proof-of-work.",
+		},
 		{
 			BinaryName:  "evm",
 			Description: "Developer utility version of the EVM (Ethereum Virtual Machine) that is capable of running bytecode snippets within a configurable environment and execution mode.",
@@ -17,12 +17,14 @@
 package main
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
@@ -51,14 +53,14 @@ var (
 		Usage:     "Download a DNS discovery tree",
 		ArgsUsage: "<url> [ <directory> ]",
 		Action:    dnsSync,
-		Flags:     []cli.Flag{dnsTimeoutFlag},
+		Flags:     []cli.Flag{dnsTimeoutFlag, dnsTXTFileFlag},
 	}
 	dnsSignCommand = &cli.Command{
 		Name:      "sign",
 		Usage:     "Sign a DNS discovery tree",
-		ArgsUsage: "<tree-directory> <key-file>",
+		ArgsUsage: "<tree-directory> <key-file> [ <output-directory> ]",
 		Action:    dnsSign,
-		Flags:     []cli.Flag{dnsDomainFlag, dnsSeqFlag},
+		Flags:     []cli.Flag{dnsDomainFlag, dnsSeqFlag, dnsNetworkFlag},
 	}
 	dnsTXTCommand = &cli.Command{
 		Name:      "to-txt",
@@ -112,6 +114,14 @@ var (
 		Name:  "seq",
 		Usage: "New sequence number of the tree",
 	}
+	dnsNetworkFlag = &cli.StringFlag{
+		Name:  "network",
+		Usage: "Drop nodes not announcing the fork ID of the given network (mainnet, goerli, sepolia, classic, vecno)",
+	}
+	dnsTXTFileFlag = &cli.StringFlag{
+		Name:  "txt",
+		Usage: "Resolve the tree from a TXT records file (as created by to-txt) instead of DNS",
+	}
 )
 
 const (
@@ -153,9 +163,23 @@ func dnsSign(ctx *cli.Context) error {
 	var (
 		defdir  = ctx.Args().Get(0)
 		keyfile = ctx.Args().Get(1)
+		outdir  = defdir
 		def     = loadTreeDefinition(defdir)
 		domain  = directoryName(defdir)
 	)
+	if ctx.NArg() > 2 {
+		outdir = ctx.Args().Get(2)
+	}
+	if ctx.IsSet(dnsNetworkFlag.Name) {
+		if sameDirectory(defdir, outdir) {
+			return errors.New("need output directory as argument to filter nodes")
+		}
+		nodes, err := filterTreeNodes(def.Nodes, ctx.String(dnsNetworkFlag.Name))
+		if err != nil {
+			return err
+		}
+		def.Nodes = nodes
+	}
 	if def.Meta.URL != "" {
 		d, _, err := dnsdisc.ParseURL(def.Meta.URL)
 		if err != nil {
@@ -184,10 +208,42 @@ func dnsSign(ctx *cli.Context) error {
 
 	def = treeToDefinition(url, t)
 	def.Meta.LastModified = time.Now()
-	writeTreeMetadata(defdir, def)
+	writeTreeMetadata(outdir, def)
+	if !sameDirectory(defdir, outdir) {
+		writeTreeNodes(outdir, def)
+	}
 	return nil
 }
 
+// filterTreeNodes returns the nodes which announce the fork ID of the given
+// network.
+func filterTreeNodes(nodes []*enode.Node, network string) ([]*enode.Node, error) {
+	filter, err := ethFilter([]string{network})
+	if err != nil {
+		return nil, err
+	}
+	var kept []*enode.Node
+	for _, n := range nodes {
+		if filter(nodeJSON{N: n}) {
+			kept = append(kept, n)
+		}
+	}
+	return kept, nil
+}
+
+// sameDirectory reports whether a and b refer to the same directory.
+func sameDirectory(a, b string) bool {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		exit(err)
+	}
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		exit(err)
+	}
+	return absA == absB
+}
+
 // directoryName returns the directory name of the given path.
 // For example, when dir is "foo/bar", it returns "bar".
 // When dir is ".", and the working directory is "example/foo", it returns "foo".
@@ -271,9 +327,36 @@ func dnsClient(ctx *cli.Context) *dnsdisc.Client {
 	if commandHasFlag(ctx, dnsTimeoutFlag) {
 		cfg.Timeout = ctx.Duration(dnsTimeoutFlag.Name)
 	}
+	if commandHasFlag(ctx, dnsTXTFileFlag) {
+		cfg.Resolver = loadTXTResolver(ctx.String(dnsTXTFileFlag.Name))
+	}
 	return dnsdisc.NewClient(cfg)
 }
 
+// txtResolver resolves DNS TXT records from a file in 'TXT' format, allowing to
+// check a tree before deploying it.
+type txtResolver map[string]string
+
+// loadTXTResolver loads a file in 'TXT' format.
+func loadTXTResolver(file string) txtResolver {
+	var txt map[string]string
+	if err := common.LoadJSON(file, &txt); err != nil {
+		exit(err)
+	}
+	r := make(txtResolver, len(txt))
+	for name, value := range txt {
+		r[strings.ToLower(name)] = value
+	}
+	return r
+}
+
+func (r txtResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if value, ok := r[strings.ToLower(name)]; ok {
+		return []string{value}, nil
+	}
+	return nil, fmt.Errorf("no TXT record for %s", name)
+}
+
 // There are two file formats for DNS node trees on disk:
 //
 // The 'TXT' format is a single JSON file containing DNS TXT records
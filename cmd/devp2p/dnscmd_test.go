@@ -0,0 +1,119 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// newEthNode creates a node announcing the fork ID of the given network.
+func newEthNode(t *testing.T, config ctypes.ChainConfigurator, genesis *genesisT.Genesis) *enode.Node {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eth := struct {
+		ForkID forkid.ID
+		Tail   []rlp.RawValue `rlp:"tail"`
+	}{ForkID: forkid.NewID(config, core.GenesisToBlock(genesis, nil), 0, 0)}
+
+	var r enr.Record
+	r.Set(enr.WithEntry("eth", &eth))
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	n, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// Tests the Vecno tree workflow: filtering a crawled node set by fork ID,
+// signing the tree and syncing it back from its TXT records.
+func TestDNSVecnoTree(t *testing.T) {
+	const domain = "nodes.example.org"
+	var (
+		dir   = filepath.Join(t.TempDir(), domain)
+		vecno = []*enode.Node{
+			newEthNode(t, params.VecnoChainConfig, params.DefaultVecnoGenesisBlock()),
+			newEthNode(t, params.VecnoChainConfig, params.DefaultVecnoGenesisBlock()),
+		}
+		mainnet = newEthNode(t, params.MainnetChainConfig, params.DefaultGenesisBlock())
+	)
+	ns := make(nodeSet)
+	ns.add(vecno...)
+	ns.add(mainnet)
+	writeTreeMetadata(dir, &dnsDefinition{Meta: dnsMetaJSON{Links: []string{}}})
+	writeTreeNodes(dir, &dnsDefinition{Nodes: ns.nodes()})
+
+	def := loadTreeDefinition(dir)
+	nodes, err := filterTreeNodes(def.Nodes, "vecno")
+	if err != nil {
+		t.Fatalf("failed to filter nodes: %v", err)
+	}
+	// Filtering must leave the input definition untouched
+	if n := len(loadTreeDefinition(dir).Nodes); n != 3 {
+		t.Fatalf("input node count changed: have %d, want 3", n)
+	}
+	tree, err := dnsdisc.MakeTree(1, nodes, nil)
+	if err != nil {
+		t.Fatalf("failed to create tree: %v", err)
+	}
+	key, _ := crypto.GenerateKey()
+	url, err := tree.Sign(key, domain)
+	if err != nil {
+		t.Fatalf("failed to sign tree: %v", err)
+	}
+	txtFile := filepath.Join(t.TempDir(), "txt.json")
+	writeTXTJSON(txtFile, tree.ToTXT(domain))
+
+	client := dnsdisc.NewClient(dnsdisc.Config{Resolver: loadTXTResolver(txtFile)})
+	synced, err := client.SyncTree(url)
+	if err != nil {
+		t.Fatalf("failed to sync tree: %v", err)
+	}
+	have, want := synced.Nodes(), vecno
+	sort.Slice(have, func(i, j int) bool { return have[i].ID().String() < have[j].ID().String() })
+	sort.Slice(want, func(i, j int) bool { return want[i].ID().String() < want[j].ID().String() })
+	if len(have) != len(want) {
+		t.Fatalf("node count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i].ID() != want[i].ID() {
+			t.Errorf("node %d mismatch: have %v, want %v", i, have[i].ID(), want[i].ID())
+		}
+	}
+	// Records missing from the file must not resolve
+	if _, err := loadTXTResolver(txtFile).LookupTXT(context.Background(), "missing."+domain); err == nil {
+		t.Error("expected error for missing record")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethashb3"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	durationFlag = &cli.DurationFlag{
+		Name:  "duration",
+		Usage: "the duration of each benchmark run",
+		Value: 10 * time.Second,
+	}
+	threadsFlag = &cli.IntFlag{
+		Name:  "threads",
+		Usage: "the number of CPU threads to hash on",
+		Value: runtime.NumCPU(),
+	}
+	lightFlag = &cli.BoolFlag{
+		Name:  "light",
+		Usage: "only benchmark light verification, skipping the dataset generation",
+	}
+)
+
+type outputBench struct {
+	Epoch     uint64
+	Threads   int
+	CacheGen  time.Duration `json:",omitempty"`
+	DagGen    time.Duration `json:",omitempty"`
+	Full      uint64        `json:",omitempty"` // Hashes per second using the full dataset
+	Light     uint64        // Hashes per second using the verification cache
+	FullLight float64       `json:",omitempty"` // Speedup of the full dataset over the cache
+}
+
+var commandBench = &cli.Command{
+	Name:  "bench",
+	Usage: "benchmark the CPU hashrate of full mining and light verification",
+	Description: `
+Measure the hashrate of the ethashb3 algorithm on the CPU, using both the full
+mining dataset (hashimotoFull) and the light verification cache (hashimotoLight)
+of an epoch.
+
+Generating the full dataset of a real epoch takes several minutes and over a
+gigabyte of memory. Use --light to skip it, or --test to benchmark on the tiny
+sizes of the ethashb3 test mode.`,
+	Flags: []cli.Flag{
+		epochFlag,
+		testFlag,
+		durationFlag,
+		threadsFlag,
+		lightFlag,
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		var (
+			epoch    = ctx.Uint64(epochFlag.Name)
+			test     = ctx.Bool(testFlag.Name)
+			duration = ctx.Duration(durationFlag.Name)
+			threads  = ctx.Int(threadsFlag.Name)
+			asJSON   = ctx.Bool(jsonFlag.Name)
+		)
+		if threads < 1 {
+			threads = 1
+		}
+		out := outputBench{Epoch: epoch, Threads: threads}
+		_, dsize := sizes(epoch, test)
+
+		start := time.Now()
+		cache := makeCache(epoch, test)
+		out.CacheGen = time.Since(start)
+
+		if !ctx.Bool(lightFlag.Name) {
+			start = time.Now()
+			dataset := makeDataset(epoch, test, cache)
+			out.DagGen = time.Since(start)
+
+			out.Full = benchmark(threads, duration, func(hash []byte, nonce uint64) {
+				ethashb3.HashimotoFull(dataset, hash, nonce)
+			})
+			if !asJSON {
+				fmt.Printf("Full:  %d H/s (dataset generated in %v)\n", out.Full, common.PrettyDuration(out.DagGen))
+			}
+		}
+		out.Light = benchmark(threads, duration, func(hash []byte, nonce uint64) {
+			ethashb3.HashimotoLight(dsize, cache, hash, nonce)
+		})
+		if out.Full > 0 && out.Light > 0 {
+			out.FullLight = float64(out.Full) / float64(out.Light)
+		}
+		if asJSON {
+			mustPrintJSON(out)
+		} else {
+			fmt.Printf("Light: %d H/s (cache generated in %v)\n", out.Light, common.PrettyDuration(out.CacheGen))
+			if out.FullLight > 0 {
+				fmt.Printf("Full mining is %.1fx faster than light verification\n", out.FullLight)
+			}
+		}
+		return nil
+	},
+}
+
+// benchmark runs the given hash function on multiple threads for the given
+// duration, returning the number of hashes per second.
+func benchmark(threads int, duration time.Duration, hash func(hash []byte, nonce uint64)) uint64 {
+	var (
+		hashes atomic.Uint64
+		abort  = make(chan struct{})
+		pend   sync.WaitGroup
+	)
+	start := time.Now()
+	for i := 0; i < threads; i++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+
+			var seed [32 + 8]byte
+			rand.Read(seed[:])
+			nonce := binary.BigEndian.Uint64(seed[32:])
+			for {
+				select {
+				case <-abort:
+					return
+				default:
+				}
+				hash(seed[:32], nonce)
+				hashes.Add(1)
+				nonce++
+			}
+		}()
+	}
+	time.Sleep(duration)
+	close(abort)
+	pend.Wait()
+
+	return uint64(float64(hashes.Load()) / time.Since(start).Seconds())
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethashb3"
+	"github.com/urfave/cli/v2"
+)
+
+type outputEpoch struct {
+	Epoch       uint64
+	EpochLength uint64
+	FirstBlock  uint64
+	SeedHash    common.Hash
+	CacheSize   uint64
+	DatasetSize uint64
+}
+
+var commandEpoch = &cli.Command{
+	Name:      "epoch",
+	Usage:     "show the seed hash, cache and dataset sizes of an epoch",
+	ArgsUsage: "[<block number>]",
+	Description: `
+Print the seed hash, verification cache size and mining dataset size of the
+epoch a block belongs to. Instead of a block number, the epoch may be given
+directly using the --epoch flag.`,
+	Flags: []cli.Flag{
+		epochFlag,
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		length := epochLength()
+
+		epoch := ctx.Uint64(epochFlag.Name)
+		if ctx.Args().Present() {
+			if ctx.IsSet(epochFlag.Name) {
+				utils.Fatalf("Block number and --%s are mutually exclusive", epochFlag.Name)
+			}
+			block, err := strconv.ParseUint(ctx.Args().First(), 0, 64)
+			if err != nil {
+				utils.Fatalf("Invalid block number: %v", err)
+			}
+			epoch = ethashb3.CalcEpoch(block, length)
+		}
+		out := outputEpoch{
+			Epoch:       epoch,
+			EpochLength: length,
+			FirstBlock:  epoch * length,
+			SeedHash:    common.BytesToHash(ethashb3.SeedHash(epoch, length)),
+			CacheSize:   ethashb3.CacheSize(epoch),
+			DatasetSize: ethashb3.DatasetSize(epoch),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Epoch:       ", out.Epoch)
+			fmt.Println("Blocks:      ", out.FirstBlock, "-", out.FirstBlock+length-1)
+			fmt.Println("Seed hash:   ", out.SeedHash.Hex())
+			fmt.Println("Cache size:  ", out.CacheSize, common.StorageSize(out.CacheSize).String())
+			fmt.Println("Dataset size:", out.DatasetSize, common.StorageSize(out.DatasetSize).String())
+		}
+		return nil
+	},
+}
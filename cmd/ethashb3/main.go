@@ -0,0 +1,67 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// ethashb3 is a utility to inspect, verify and benchmark the ethashb3
+// proof-of-work algorithm.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	testCacheSize   = 1024      // Verification cache size used by ethashb3.ModeTest
+	testDatasetSize = 32 * 1024 // Mining dataset size used by ethashb3.ModeTest
+)
+
+var app *cli.App
+
+func init() {
+	app = flags.NewApp("EthashB3 proof-of-work utility")
+	app.Commands = []*cli.Command{
+		commandEpoch,
+		commandVerify,
+		commandBench,
+		commandVectors,
+	}
+}
+
+// Commonly used command line flags.
+var (
+	epochFlag = &cli.Uint64Flag{
+		Name:  "epoch",
+		Usage: "the epoch to operate on",
+	}
+	testFlag = &cli.BoolFlag{
+		Name:  "test",
+		Usage: "use the tiny cache and dataset sizes of the ethashb3 test mode",
+	}
+	jsonFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "output JSON instead of human-readable format",
+	}
+)
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
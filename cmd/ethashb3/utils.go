@@ -0,0 +1,73 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/consensus/ethashb3"
+)
+
+// epochLength returns the number of blocks in an epoch. EthashB3 epochs have a
+// fixed length, independent of the block number.
+func epochLength() uint64 {
+	return ethashb3.CalcEpochLength(0)
+}
+
+// sizes returns the verification cache and mining dataset sizes of an epoch,
+// optionally replaced by the tiny sizes of the ethashb3 test mode.
+func sizes(epoch uint64, test bool) (uint64, uint64) {
+	if test {
+		return testCacheSize, testDatasetSize
+	}
+	return ethashb3.CacheSize(epoch), ethashb3.DatasetSize(epoch)
+}
+
+// makeCache generates the verification cache of an epoch.
+func makeCache(epoch uint64, test bool) []uint32 {
+	csize, _ := sizes(epoch, test)
+	return ethashb3.GenerateCache(csize, epoch, epochLength())
+}
+
+// makeDataset generates the mining dataset of an epoch from its cache.
+func makeDataset(epoch uint64, test bool, cache []uint32) []uint32 {
+	_, dsize := sizes(epoch, test)
+	return ethashb3.GenerateDataset(dsize, epoch, epochLength(), cache)
+}
+
+// littleEndian serializes a cache or dataset into its canonical little endian
+// byte representation, independent of the machine byte order.
+func littleEndian(words []uint32) []byte {
+	blob := make([]byte, len(words)*4)
+	for i, word := range words {
+		binary.LittleEndian.PutUint32(blob[i*4:], word)
+	}
+	return blob
+}
+
+// mustPrintJSON prints the JSON encoding of the given object and
+// exits the program with an error message when the marshaling fails.
+func mustPrintJSON(jsonObject interface{}) {
+	str, err := json.MarshalIndent(jsonObject, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to marshal JSON object: %v", err)
+	}
+	fmt.Println(string(str))
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethashb3"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	itemsFlag = &cli.IntFlag{
+		Name:  "items",
+		Usage: "the number of dataset items to emit",
+		Value: 8,
+	}
+	hashesFlag = &cli.IntFlag{
+		Name:  "hashes",
+		Usage: "the number of hashimoto results to emit",
+		Value: 8,
+	}
+	outputFlag = &cli.PathFlag{
+		Name:      "output",
+		Usage:     "the file to write the vectors to (default = stdout)",
+		TakesFile: true,
+	}
+)
+
+// vectorSet is a set of reference test vectors of a single epoch.
+type vectorSet struct {
+	Epoch        uint64          `json:"epoch"`
+	EpochLength  uint64          `json:"epochLength"`
+	SeedHash     common.Hash     `json:"seedHash"`
+	CacheSize    uint64          `json:"cacheSize"`
+	DatasetSize  uint64          `json:"datasetSize"`
+	CacheHash    common.Hash     `json:"cacheHash"` // Keccak256 of the little endian cache
+	DatasetItems []datasetVector `json:"datasetItems"`
+	Hashimoto    []powVector     `json:"hashimoto"`
+}
+
+// datasetVector is a single 64 byte item of the mining dataset.
+type datasetVector struct {
+	Index uint32        `json:"index"`
+	Item  hexutil.Bytes `json:"item"`
+}
+
+// powVector is the proof-of-work of a header hash and nonce.
+type powVector struct {
+	Hash      common.Hash      `json:"hash"`
+	Nonce     types.BlockNonce `json:"nonce"`
+	MixDigest common.Hash      `json:"mixDigest"`
+	Result    common.Hash      `json:"result"`
+}
+
+var commandVectors = &cli.Command{
+	Name:  "vectors",
+	Usage: "emit reference test vectors of the ethashb3 algorithm",
+	Description: `
+Emit a JSON set of reference test vectors for an epoch: the seed hash, the cache
+and dataset sizes, the hash of the verification cache, a number of dataset items
+spread over the whole dataset and the mix digests and results of a number of
+deterministic header hashes and nonces.
+
+The vectors allow third party miner implementations to check their output
+against the reference implementation. The --test flag produces vectors of the
+tiny sizes used in the ethashb3 test mode.`,
+	Flags: []cli.Flag{
+		epochFlag,
+		testFlag,
+		itemsFlag,
+		hashesFlag,
+		outputFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		vectors := makeVectors(ctx.Uint64(epochFlag.Name), ctx.Bool(testFlag.Name), ctx.Int(itemsFlag.Name), ctx.Int(hashesFlag.Name))
+
+		blob, err := json.MarshalIndent(vectors, "", "  ")
+		if err != nil {
+			utils.Fatalf("Failed to marshal vectors: %v", err)
+		}
+		if path := ctx.Path(outputFlag.Name); path != "" {
+			if err := os.WriteFile(path, append(blob, '\n'), 0644); err != nil {
+				utils.Fatalf("Failed to write vectors: %v", err)
+			}
+			return nil
+		}
+		fmt.Println(string(blob))
+		return nil
+	},
+}
+
+// makeVectors generates the reference test vectors of an epoch. The header
+// hashes and nonces are derived deterministically from their index.
+func makeVectors(epoch uint64, test bool, items int, hashes int) *vectorSet {
+	var (
+		length       = epochLength()
+		csize, dsize = sizes(epoch, test)
+		cache        = makeCache(epoch, test)
+	)
+	vectors := &vectorSet{
+		Epoch:       epoch,
+		EpochLength: length,
+		SeedHash:    common.BytesToHash(ethashb3.SeedHash(epoch, length)),
+		CacheSize:   csize,
+		DatasetSize: dsize,
+		CacheHash:   crypto.Keccak256Hash(littleEndian(cache)),
+	}
+	rows := uint32(dsize / 64)
+	for i := 0; i < items; i++ {
+		index := uint32(uint64(i) * uint64(rows) / uint64(items))
+		vectors.DatasetItems = append(vectors.DatasetItems, datasetVector{
+			Index: index,
+			Item:  ethashb3.DatasetItem(cache, index),
+		})
+	}
+	for i := 0; i < hashes; i++ {
+		var seed [8]byte
+		binary.BigEndian.PutUint64(seed[:], uint64(i))
+
+		hash := crypto.Keccak256Hash(seed[:])
+		nonce := binary.BigEndian.Uint64(crypto.Keccak256(hash[:])[:8])
+		digest, result := ethashb3.HashimotoLight(dsize, cache, hash[:], nonce)
+
+		vectors.Hashimoto = append(vectors.Hashimoto, powVector{
+			Hash:      hash,
+			Nonce:     types.EncodeNonce(nonce),
+			MixDigest: common.BytesToHash(digest),
+			Result:    common.BytesToHash(result),
+		})
+	}
+	return vectors
+}
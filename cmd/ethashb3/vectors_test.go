@@ -0,0 +1,85 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethashb3"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that the emitted vectors are deterministic and that the full dataset
+// produces the same proof-of-work as the light verification cache.
+func TestVectors(t *testing.T) {
+	vectors := makeVectors(0, true, 4, 4)
+	if !reflect.DeepEqual(vectors, makeVectors(0, true, 4, 4)) {
+		t.Fatalf("vectors not deterministic")
+	}
+	if len(vectors.DatasetItems) != 4 || len(vectors.Hashimoto) != 4 {
+		t.Fatalf("vector count mismatch: have %d/%d, want 4/4", len(vectors.DatasetItems), len(vectors.Hashimoto))
+	}
+	cache := makeCache(0, true)
+	dataset := makeDataset(0, true, cache)
+	full := littleEndian(dataset)
+
+	for i, item := range vectors.DatasetItems {
+		if want := full[item.Index*64 : (item.Index+1)*64]; !bytes.Equal(item.Item, want) {
+			t.Errorf("item %d: dataset mismatch: have %x, want %x", i, item.Item, want)
+		}
+	}
+	for i, pow := range vectors.Hashimoto {
+		digest, result := ethashb3.HashimotoFull(dataset, pow.Hash[:], pow.Nonce.Uint64())
+		if common.BytesToHash(digest) != pow.MixDigest {
+			t.Errorf("hash %d: mix digest mismatch: have %x, want %x", i, digest, pow.MixDigest)
+		}
+		if common.BytesToHash(result) != pow.Result {
+			t.Errorf("hash %d: result mismatch: have %x, want %x", i, result, pow.Result)
+		}
+	}
+}
+
+// Tests that header seals are verified correctly.
+func TestVerifyHeader(t *testing.T) {
+	header := &types.Header{
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+		GasLimit:   8000000,
+		Nonce:      types.EncodeNonce(0x1234),
+	}
+	// Seal the header with the correct mix digest, any result meets the target.
+	out := verifyHeader(header, true)
+	header.MixDigest = out.MixDigest
+	if out = verifyHeader(header, true); !out.Valid {
+		t.Fatalf("valid seal rejected: %s", out.Error)
+	}
+	// Tamper with the nonce, invalidating the mix digest.
+	header.Nonce = types.EncodeNonce(0x1235)
+	if out = verifyHeader(header, true); out.Valid {
+		t.Fatalf("invalid mix digest accepted")
+	}
+	// Raise the difficulty beyond reach, keeping the mix digest correct.
+	header.Difficulty = new(big.Int).Lsh(big.NewInt(1), 255)
+	header.MixDigest = verifyHeader(header, true).MixDigest
+	if out = verifyHeader(header, true); out.Valid || out.Error != "result above target" {
+		t.Fatalf("result above target accepted: %v", out.Error)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethashb3"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/urfave/cli/v2"
+)
+
+// two256 is a big integer representing 2^256
+var two256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
+
+type outputVerify struct {
+	Number    uint64
+	Hash      common.Hash
+	SealHash  common.Hash
+	Nonce     types.BlockNonce
+	MixDigest common.Hash // Mix digest recomputed from the seal
+	Result    common.Hash // Proof-of-work result recomputed from the seal
+	Target    common.Hash // Boundary condition, 2^256/difficulty
+	Valid     bool
+	Error     string `json:",omitempty"`
+}
+
+var commandVerify = &cli.Command{
+	Name:      "verify",
+	Usage:     "verify the proof-of-work seal of an RLP encoded header",
+	ArgsUsage: "<headerfile>",
+	Description: `
+Verify the ethashb3 seal of the block header stored in the given file, which
+may contain either raw or hex encoded RLP. The verification cache of the
+header's epoch is generated in memory.
+
+The command exits with a non-zero status if the seal is invalid.`,
+	Flags: []cli.Flag{
+		testFlag,
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.Args().Len() != 1 {
+			utils.Fatalf("This command requires a header file as its only argument")
+		}
+		header, err := readHeader(ctx.Args().First())
+		if err != nil {
+			utils.Fatalf("Failed to read header: %v", err)
+		}
+		out := verifyHeader(header, ctx.Bool(testFlag.Name))
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Block:     ", out.Number, out.Hash.Hex())
+			fmt.Println("Seal hash: ", out.SealHash.Hex())
+			fmt.Println("Nonce:     ", hex.EncodeToString(out.Nonce[:]))
+			fmt.Println("Mix digest:", out.MixDigest.Hex())
+			fmt.Println("Result:    ", out.Result.Hex())
+			fmt.Println("Target:    ", out.Target.Hex())
+		}
+		if !out.Valid {
+			utils.Fatalf("Invalid seal: %s", out.Error)
+		}
+		if !ctx.Bool(jsonFlag.Name) {
+			fmt.Println("Seal is valid")
+		}
+		return nil
+	},
+}
+
+// readHeader decodes a block header from a file containing either raw or hex
+// encoded RLP.
+func readHeader(path string) (*types.Header, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if text := bytes.TrimPrefix(bytes.TrimSpace(blob), []byte("0x")); len(text) > 0 {
+		if dec, err := hex.DecodeString(string(text)); err == nil {
+			blob = dec
+		}
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(blob, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// verifyHeader recomputes the proof-of-work of a header using the light
+// verification cache and checks it against the header's seal.
+func verifyHeader(header *types.Header, test bool) *outputVerify {
+	out := &outputVerify{
+		Number:    header.Number.Uint64(),
+		Hash:      header.Hash(),
+		SealHash:  ethashb3.NewFaker().SealHash(header),
+		Nonce:     header.Nonce,
+		MixDigest: header.MixDigest,
+	}
+	if header.Difficulty.Sign() <= 0 {
+		out.Error = "non-positive difficulty"
+		return out
+	}
+	target := new(big.Int).Div(two256, header.Difficulty)
+	out.Target = common.BigToHash(target)
+
+	epoch := ethashb3.CalcEpoch(out.Number, epochLength())
+	_, dsize := sizes(epoch, test)
+	digest, result := ethashb3.HashimotoLight(dsize, makeCache(epoch, test), out.SealHash.Bytes(), header.Nonce.Uint64())
+	out.MixDigest = common.BytesToHash(digest)
+	out.Result = common.BytesToHash(result)
+
+	switch {
+	case out.MixDigest != header.MixDigest:
+		out.Error = fmt.Sprintf("mix digest mismatch: have %x, want %x", header.MixDigest, out.MixDigest)
+	case new(big.Int).SetBytes(result).Cmp(target) > 0:
+		out.Error = "result above target"
+	default:
+		out.Valid = true
+	}
+	return out
+}
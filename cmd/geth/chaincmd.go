@@ -101,6 +101,7 @@ if one is set.  Otherwise it prints the genesis from the datadir.`,
 			utils.TxLookupLimitFlag,
 			utils.TransactionHistoryFlag,
 			utils.StateHistoryFlag,
+			utils.SupplyIndexFlag,
 		}, utils.DatabaseFlags),
 		Description: `
 The import command imports blocks from an RLP-encoded form. The form can be one file
@@ -149,7 +149,11 @@ func loadBaseConfig(ctx *cli.Context) gethConfig {
 
 // makeConfigNode loads geth configuration and creates a blank node instance.
 func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
-	cfg := loadBaseConfig(ctx)
+	return newConfigNode(ctx, loadBaseConfig(ctx))
+}
+
+// newConfigNode creates a blank node instance from the given base configuration.
+func newConfigNode(ctx *cli.Context, cfg gethConfig) (*node.Node, gethConfig) {
 	stack, err := node.New(&cfg.Node)
 	if err != nil {
 		utils.Fatalf("Failed to create the protocol stack: %v", err)
@@ -170,7 +174,13 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 
 // makeFullNode loads geth configuration and creates the Ethereum backend.
 func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
-	stack, cfg := makeConfigNode(ctx)
+	return newFullNode(ctx, loadBaseConfig(ctx))
+}
+
+// newFullNode creates the node and the Ethereum backend from the given base
+// configuration.
+func newFullNode(ctx *cli.Context, base gethConfig) (*node.Node, ethapi.Backend) {
+	stack, cfg := newConfigNode(ctx, base)
 	if ctx.IsSet(utils.ECBP1100Flag.Name) {
 		if n := ctx.Uint64(utils.ECBP1100Flag.Name); n != math.MaxUint64 {
 			cfg.Eth.OverrideECBP1100 = &n
@@ -223,6 +233,10 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, filterSystem, &cfg.Node)
 	}
+	// Configure the gRPC server if requested.
+	if ctx.IsSet(utils.GRPCEnabledFlag.Name) {
+		utils.RegisterGRPCService(stack, backend, filterSystem, ctx.String(utils.GRPCListenAddrFlag.Name), ctx.Int(utils.GRPCPortFlag.Name))
+	}
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
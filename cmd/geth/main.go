@@ -90,6 +90,14 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolPersistFlag,
+		utils.TxPoolPersistLimitFlag,
+		utils.TxPoolPersistLifetimeFlag,
+		utils.TxPoolHistoryLimitFlag,
+		utils.TxPoolOriginRateFlag,
+		utils.TxPoolOriginBurstFlag,
+		utils.TxPoolOriginSlotsFlag,
+		utils.TxPoolOriginWhitelistFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
@@ -100,6 +108,7 @@ var (
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
 		utils.TransactionHistoryFlag,
+		utils.SupplyIndexFlag,
 		utils.StateHistoryFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
@@ -133,6 +142,9 @@ var (
 		utils.MiningEnabledFlag,
 		utils.MinerThreadsFlag,
 		utils.MinerNotifyFlag,
+		utils.MinerStratumFlag,
+		utils.MinerStratumDifficultyFlag,
+		utils.MinerStratumShareTimeFlag,
 		utils.MinerGasLimitFlag,
 		utils.MinerGasPriceFlag,
 		utils.MinerEtherbaseFlag,
@@ -140,6 +152,12 @@ var (
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerifyFlag,
 		utils.MinerNewPayloadTimeout,
+		utils.MinerOrderingFlag,
+		utils.MinerPriorityFlag,
+		utils.MinerMinTxsFlag,
+		utils.MinerMinFeesFlag,
+		utils.MinerFillTimeFlag,
+		utils.MinerPauseEmptyFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV4Flag,
@@ -186,6 +204,9 @@ var (
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
+		utils.GRPCEnabledFlag,
+		utils.GRPCListenAddrFlag,
+		utils.GRPCPortFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
 		utils.WSEnabledFlag,
@@ -200,9 +221,18 @@ var (
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCCacheDepthFlag,
+		utils.RPCCacheSizeFlag,
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCAPIKeysFlag,
+		utils.RPCRequireAPIKeyFlag,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateBurstFlag,
+		utils.RPCKeyRateLimitFlag,
+		utils.RPCKeyRateBurstFlag,
+		utils.RPCMethodLimitsFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -254,6 +284,8 @@ func init() {
 		licenseCommand,
 		// See config.go
 		dumpConfigCommand,
+		// See openrpccmd.go
+		openrpcCommand,
 		// see dbcmd.go
 		dbCommand,
 		// See cmd/utils/flags_legacy.go
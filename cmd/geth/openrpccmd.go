@@ -0,0 +1,68 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	openrpcCommand = &cli.Command{
+		Name:  "openrpc",
+		Usage: "OpenRPC API description commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "dump",
+				Usage:     "Export the OpenRPC document of the RPC APIs",
+				ArgsUsage: "[<namespace> ...]",
+				Action:    dumpOpenRPC,
+				Flags:     flags.Merge(nodeFlags, rpcFlags),
+				Description: `
+geth openrpc dump [<namespace> ...]
+This command prints the OpenRPC document served by rpc.discover to stdout,
+restricted to the given namespaces if any. The node isn't started and its
+databases are kept in memory, so no data directory is touched.
+`,
+			},
+		},
+	}
+)
+
+// dumpOpenRPC creates the full node with in-memory databases and prints the
+// OpenRPC document of its APIs.
+func dumpOpenRPC(ctx *cli.Context) error {
+	cfg := loadBaseConfig(ctx)
+	cfg.Node.DataDir = ""
+
+	stack, _ := newFullNode(ctx, cfg)
+	defer stack.Close()
+
+	doc, err := stack.OpenRPCDocument(ctx.Args().Slice())
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
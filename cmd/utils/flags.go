@@ -61,6 +61,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
+	"github.com/ethereum/go-ethereum/ethgrpc"
 	"github.com/ethereum/go-ethereum/ethstats"
 	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -334,6 +335,11 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	SupplyIndexFlag = &cli.BoolFlag{
+		Name:     "history.supply",
+		Usage:    "Index the total issued supply of each canonical block (reported by vecno_getBlockRewards)",
+		Category: flags.StateCategory,
+	}
 	// Light server and client settings
 	LightServeFlag = &cli.IntFlag{
 		Name:     "light.serve",
@@ -505,6 +511,52 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPersistFlag = &cli.BoolFlag{
+		Name:     "txpool.persist",
+		Usage:    "Persist all pooled transactions, local and remote, into the database to survive node restarts",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPersistLimitFlag = &cli.Uint64Flag{
+		Name:     "txpool.persistlimit",
+		Usage:    "Maximum number of persisted transactions restored at startup",
+		Value:    ethconfig.Defaults.TxPool.PersistLimit,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPersistLifetimeFlag = &cli.DurationFlag{
+		Name:     "txpool.persistlifetime",
+		Usage:    "Maximum age of restored remote transactions and of the dropped transaction history",
+		Value:    ethconfig.Defaults.TxPool.PersistLifetime,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolHistoryLimitFlag = &cli.Uint64Flag{
+		Name:     "txpool.historylimit",
+		Usage:    "Maximum number of dropped transactions remembered by txpool_history",
+		Value:    ethconfig.Defaults.TxPool.HistoryLimit,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolOriginRateFlag = &cli.Float64Flag{
+		Name:     "txpool.originrate",
+		Usage:    "Transactions per second admitted from a single RPC client or peer (0 = unlimited)",
+		Value:    ethconfig.Defaults.TxPool.OriginRate,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolOriginBurstFlag = &cli.Uint64Flag{
+		Name:     "txpool.originburst",
+		Usage:    "Maximum burst of transactions admitted from a single RPC client or peer",
+		Value:    ethconfig.Defaults.TxPool.OriginBurst,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolOriginSlotsFlag = &cli.Uint64Flag{
+		Name:     "txpool.originslots",
+		Usage:    "Maximum number of transactions pooled from a single RPC client or peer (0 = unlimited)",
+		Value:    ethconfig.Defaults.TxPool.OriginSlots,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolOriginWhitelistFlag = &cli.StringFlag{
+		Name:     "txpool.originwhitelist",
+		Usage:    "Comma separated RPC client IPs and peer IDs exempt from the per-origin admission quotas",
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -605,6 +657,23 @@ var (
 		Usage:    "Notify with pending block headers instead of work packages",
 		Category: flags.MinerCategory,
 	}
+	MinerStratumFlag = &cli.StringFlag{
+		Name:     "miner.stratum",
+		Usage:    "Listen address of the built-in stratum server for remote miners (ethashb3 only)",
+		Category: flags.MinerCategory,
+	}
+	MinerStratumDifficultyFlag = &cli.Uint64Flag{
+		Name:     "miner.stratum.diff",
+		Usage:    "Initial share difficulty assigned to stratum workers",
+		Value:    ethconfig.Defaults.EthashB3.Stratum.Difficulty,
+		Category: flags.MinerCategory,
+	}
+	MinerStratumShareTimeFlag = &cli.DurationFlag{
+		Name:     "miner.stratum.sharetime",
+		Usage:    "Target time between shares of a stratum worker (0 = fixed difficulty)",
+		Value:    ethconfig.Defaults.EthashB3.Stratum.ShareTime,
+		Category: flags.MinerCategory,
+	}
 	MinerGasLimitFlag = &cli.Uint64Flag{
 		Name:     "miner.gaslimit",
 		Usage:    "Target gas ceiling for mined blocks",
@@ -644,6 +713,37 @@ var (
 		Value:    ethconfig.Defaults.Miner.NewPayloadTimeout,
 		Category: flags.MinerCategory,
 	}
+	MinerOrderingFlag = &cli.StringFlag{
+		Name:     "miner.ordering",
+		Usage:    "Transaction ordering policy of mined blocks (price, fifo, priority, bundle)",
+		Value:    miner.OrderingPrice,
+		Category: flags.MinerCategory,
+	}
+	MinerPriorityFlag = &cli.StringFlag{
+		Name:     "miner.priority",
+		Usage:    "Comma separated list of senders and contracts whose transactions are mined first by the priority ordering policy",
+		Category: flags.MinerCategory,
+	}
+	MinerMinTxsFlag = &cli.Uint64Flag{
+		Name:     "miner.mintxs",
+		Usage:    "Minimum number of transactions before non-empty work replaces the empty block being mined (0 = disabled)",
+		Category: flags.MinerCategory,
+	}
+	MinerMinFeesFlag = &flags.BigFlag{
+		Name:     "miner.minfees",
+		Usage:    "Minimum fees in wei before non-empty work replaces the empty block being mined (0 = disabled)",
+		Category: flags.MinerCategory,
+	}
+	MinerFillTimeFlag = &cli.DurationFlag{
+		Name:     "miner.filltime",
+		Usage:    "Maximum time spent filling transactions into the block being mined (0 = unlimited)",
+		Category: flags.MinerCategory,
+	}
+	MinerPauseEmptyFlag = &cli.BoolFlag{
+		Name:     "miner.pauseempty",
+		Usage:    "Pause mining empty blocks while the transaction pool has pending transactions",
+		Category: flags.MinerCategory,
+	}
 
 	// Account settings
 	UnlockedAccountFlag = &cli.StringFlag{
@@ -696,6 +796,18 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCCacheDepthFlag = &cli.Uint64Flag{
+		Name:     "rpc.cache.depth",
+		Usage:    "Confirmations of a block before caching the RPC results of historical queries about it (0 = cache disabled)",
+		Value:    ethconfig.Defaults.RPCCacheDepth,
+		Category: flags.APICategory,
+	}
+	RPCCacheSizeFlag = &cli.IntFlag{
+		Name:     "rpc.cache.size",
+		Usage:    "Size of the RPC response cache of historical queries (in megabytes)",
+		Value:    ethconfig.Defaults.RPCCacheSize,
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -820,6 +932,23 @@ var (
 		Value:    strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
 		Category: flags.APICategory,
 	}
+	GRPCEnabledFlag = &cli.BoolFlag{
+		Name:     "grpc",
+		Usage:    "Enable the gRPC server of blocks, receipts, logs, state and traces",
+		Category: flags.APICategory,
+	}
+	GRPCListenAddrFlag = &cli.StringFlag{
+		Name:     "grpc.addr",
+		Usage:    "gRPC server listening interface",
+		Value:    ethgrpc.DefaultHost,
+		Category: flags.APICategory,
+	}
+	GRPCPortFlag = &cli.IntFlag{
+		Name:     "grpc.port",
+		Usage:    "gRPC server listening port",
+		Value:    ethgrpc.DefaultPort,
+		Category: flags.APICategory,
+	}
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:     "ws",
 		Usage:    "Enable the WS-RPC server",
@@ -882,6 +1011,41 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCAPIKeysFlag = &cli.StringFlag{
+		Name:     "rpc.apikeys",
+		Usage:    "Comma separated API keys accepted by the HTTP, WS and gRPC endpoints, in the X-API-Key header or as last HTTP/WS URL path element",
+		Category: flags.APICategory,
+	}
+	RPCRequireAPIKeyFlag = &cli.BoolFlag{
+		Name:     "rpc.apikeys.required",
+		Usage:    "Reject HTTP, WS and gRPC requests without a valid API key",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Calls per second served to a single client IP over HTTP, WS and gRPC (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateBurstFlag = &cli.IntFlag{
+		Name:     "rpc.ratelimit.burst",
+		Usage:    "Maximum burst of calls served to a single client IP over HTTP, WS and gRPC",
+		Category: flags.APICategory,
+	}
+	RPCKeyRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.key",
+		Usage:    "Calls per second served to a single API key over HTTP, WS and gRPC (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCKeyRateBurstFlag = &cli.IntFlag{
+		Name:     "rpc.ratelimit.keyburst",
+		Usage:    "Maximum burst of calls served to a single API key over HTTP, WS and gRPC",
+		Category: flags.APICategory,
+	}
+	RPCMethodLimitsFlag = &cli.StringFlag{
+		Name:     "rpc.methodlimits",
+		Usage:    "Comma separated per namespace or method limits as name=rate[/burst[/concurrency]] (e.g. debug=1/2/1,eth_getLogs=10)",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -1226,17 +1390,12 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 	cfg.BootstrapNodes = mustParseBootnodes(urls)
 }
 
+// mustParseBootnodes parses and validates the given bootstrap node URLs, exiting
+// with an error describing the first invalid entry.
 func mustParseBootnodes(urls []string) []*enode.Node {
-	nodes := make([]*enode.Node, 0, len(urls))
-	for _, url := range urls {
-		if url != "" {
-			node, err := enode.Parse(enode.ValidSchemes, url)
-			if err != nil {
-				log.Crit("Bootstrap URL invalid", "enode", url, "err", err)
-				return nil
-			}
-			nodes = append(nodes, node)
-		}
+	nodes, err := p2p.ParseBootnodes(urls)
+	if err != nil {
+		Fatalf("Invalid bootstrap nodes: %v", err)
 	}
 	return nodes
 }
@@ -1258,17 +1417,7 @@ func setBootstrapNodesV5(ctx *cli.Context, cfg *p2p.Config) {
 		return // already set, don't apply defaults.
 	}
 
-	cfg.BootstrapNodesV5 = make([]*enode.Node, 0, len(urls))
-	for _, url := range urls {
-		if url != "" {
-			node, err := enode.Parse(enode.ValidSchemes, url)
-			if err != nil {
-				log.Error("Bootstrap URL invalid", "enode", url, "err", err)
-				continue
-			}
-			cfg.BootstrapNodesV5 = append(cfg.BootstrapNodesV5, node)
-		}
-	}
+	cfg.BootstrapNodesV5 = mustParseBootnodes(urls)
 }
 
 // setListenAddress creates TCP/UDP listening address strings from set command
@@ -1357,6 +1506,61 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+	setRPCLimits(ctx, &cfg.RPCLimits)
+}
+
+// setRPCLimits applies the RPC API key and rate limit flags to the config.
+func setRPCLimits(ctx *cli.Context, cfg *node.RPCLimitConfig) {
+	if ctx.IsSet(RPCAPIKeysFlag.Name) {
+		cfg.APIKeys = SplitAndTrim(ctx.String(RPCAPIKeysFlag.Name))
+	}
+	if ctx.IsSet(RPCRequireAPIKeyFlag.Name) {
+		cfg.RequireKey = ctx.Bool(RPCRequireAPIKeyFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		cfg.Rate = ctx.Float64(RPCRateLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCRateBurstFlag.Name) {
+		cfg.Burst = ctx.Int(RPCRateBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCKeyRateLimitFlag.Name) {
+		cfg.KeyRate = ctx.Float64(RPCKeyRateLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCKeyRateBurstFlag.Name) {
+		cfg.KeyBurst = ctx.Int(RPCKeyRateBurstFlag.Name)
+	}
+	if !ctx.IsSet(RPCMethodLimitsFlag.Name) {
+		return
+	}
+	cfg.Methods = make(map[string]node.RPCMethodLimit)
+	for _, entry := range SplitAndTrim(ctx.String(RPCMethodLimitsFlag.Name)) {
+		parts := strings.Split(entry, "=")
+		if len(parts) != 2 || parts[0] == "" {
+			Fatalf("Invalid RPC method limit entry: %s", entry)
+		}
+		values := strings.Split(parts[1], "/")
+		if len(values) > 3 {
+			Fatalf("Invalid RPC method limit entry: %s", entry)
+		}
+		var (
+			limit node.RPCMethodLimit
+			err   error
+		)
+		if limit.Rate, err = strconv.ParseFloat(values[0], 64); err != nil {
+			Fatalf("Invalid RPC method rate %s: %v", values[0], err)
+		}
+		if len(values) > 1 {
+			if limit.Burst, err = strconv.Atoi(values[1]); err != nil {
+				Fatalf("Invalid RPC method burst %s: %v", values[1], err)
+			}
+		}
+		if len(values) > 2 {
+			if limit.Concurrency, err = strconv.Atoi(values[2]); err != nil {
+				Fatalf("Invalid RPC method concurrency %s: %v", values[2], err)
+			}
+		}
+		cfg.Methods[parts[0]] = limit
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1802,6 +2006,34 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolPersistFlag.Name) {
+		cfg.Persist = ctx.Bool(TxPoolPersistFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPersistLimitFlag.Name) {
+		cfg.PersistLimit = ctx.Uint64(TxPoolPersistLimitFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPersistLifetimeFlag.Name) {
+		cfg.PersistLifetime = ctx.Duration(TxPoolPersistLifetimeFlag.Name)
+	}
+	if ctx.IsSet(TxPoolHistoryLimitFlag.Name) {
+		cfg.HistoryLimit = ctx.Uint64(TxPoolHistoryLimitFlag.Name)
+	}
+	if ctx.IsSet(TxPoolOriginRateFlag.Name) {
+		cfg.OriginRate = ctx.Float64(TxPoolOriginRateFlag.Name)
+	}
+	if ctx.IsSet(TxPoolOriginBurstFlag.Name) {
+		cfg.OriginBurst = ctx.Uint64(TxPoolOriginBurstFlag.Name)
+	}
+	if ctx.IsSet(TxPoolOriginSlotsFlag.Name) {
+		cfg.OriginSlots = ctx.Uint64(TxPoolOriginSlotsFlag.Name)
+	}
+	if ctx.IsSet(TxPoolOriginWhitelistFlag.Name) {
+		for _, origin := range strings.Split(ctx.String(TxPoolOriginWhitelistFlag.Name), ",") {
+			if trimmed := strings.TrimSpace(origin); trimmed != "" {
+				cfg.OriginWhitelist = append(cfg.OriginWhitelist, trimmed)
+			}
+		}
+	}
 }
 
 func homeDir() string {
@@ -1872,6 +2104,18 @@ func setEthash(ctx *cli.Context, cfg *eth.Config) {
 	}
 }
 
+func setStratum(ctx *cli.Context, cfg *eth.Config) {
+	if ctx.IsSet(MinerStratumFlag.Name) {
+		cfg.EthashB3.Stratum.Addr = ctx.String(MinerStratumFlag.Name)
+	}
+	if ctx.IsSet(MinerStratumDifficultyFlag.Name) {
+		cfg.EthashB3.Stratum.Difficulty = ctx.Uint64(MinerStratumDifficultyFlag.Name)
+	}
+	if ctx.IsSet(MinerStratumShareTimeFlag.Name) {
+		cfg.EthashB3.Stratum.ShareTime = ctx.Duration(MinerStratumShareTimeFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerNotifyFlag.Name) {
 		cfg.Notify = strings.Split(ctx.String(MinerNotifyFlag.Name), ",")
@@ -1896,6 +2140,35 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerNewPayloadTimeout.Name) {
 		cfg.NewPayloadTimeout = ctx.Duration(MinerNewPayloadTimeout.Name)
 	}
+	if ctx.IsSet(MinerOrderingFlag.Name) {
+		cfg.Ordering = ctx.String(MinerOrderingFlag.Name)
+	}
+	switch cfg.Ordering {
+	case "", miner.OrderingPrice, miner.OrderingFIFO, miner.OrderingPriority, miner.OrderingBundle:
+	default:
+		Fatalf("Invalid transaction ordering policy %q", cfg.Ordering)
+	}
+	if ctx.IsSet(MinerPriorityFlag.Name) {
+		cfg.PriorityAddresses = nil
+		for _, addr := range SplitAndTrim(ctx.String(MinerPriorityFlag.Name)) {
+			if !common.IsHexAddress(addr) {
+				Fatalf("Invalid priority address %q", addr)
+			}
+			cfg.PriorityAddresses = append(cfg.PriorityAddresses, common.HexToAddress(addr))
+		}
+	}
+	if ctx.IsSet(MinerMinTxsFlag.Name) {
+		cfg.MinTxs = ctx.Uint64(MinerMinTxsFlag.Name)
+	}
+	if ctx.IsSet(MinerMinFeesFlag.Name) {
+		cfg.MinFees = flags.GlobalBig(ctx, MinerMinFeesFlag.Name)
+	}
+	if ctx.IsSet(MinerFillTimeFlag.Name) {
+		cfg.FillTimeout = ctx.Duration(MinerFillTimeFlag.Name)
+	}
+	if ctx.IsSet(MinerPauseEmptyFlag.Name) {
+		cfg.PauseEmpty = ctx.Bool(MinerPauseEmptyFlag.Name)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1978,6 +2251,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setGPO(ctx, &cfg.GPO, ctx.String(SyncModeFlag.Name) == "light")
 	setTxPool(ctx, &cfg.TxPool)
 	setEthash(ctx, cfg)
+	setStratum(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -2043,6 +2317,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		log.Warn("The config option 'TxLookupLimit' is deprecated and will be removed, please use 'TransactionHistory'")
 		cfg.TransactionHistory = cfg.TxLookupLimit
 	}
+	if ctx.IsSet(SupplyIndexFlag.Name) {
+		cfg.SupplyIndex = ctx.Bool(SupplyIndexFlag.Name)
+	}
 	if ctx.IsSet(TransactionHistoryFlag.Name) {
 		cfg.TransactionHistory = ctx.Uint64(TransactionHistoryFlag.Name)
 	} else if ctx.IsSet(TxLookupLimitFlag.Name) {
@@ -2112,6 +2389,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCCacheDepthFlag.Name) {
+		cfg.RPCCacheDepth = ctx.Uint64(RPCCacheDepthFlag.Name)
+	}
+	if ctx.IsSet(RPCCacheSizeFlag.Name) {
+		cfg.RPCCacheSize = ctx.Int(RPCCacheSizeFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -2205,6 +2488,10 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		SetDNSDiscoveryDefaults(cfg, params.GoerliGenesisHash)
 	case ctx.Bool(ClassicFlag.Name):
 		SetDNSDiscoveryDefaults2(cfg, params.ClassicDNSNetwork1)
+	case ctx.Bool(VecnoFlag.Name):
+		if params.VecnoDNSNetwork != "" {
+			SetDNSDiscoveryDefaults2(cfg, params.VecnoDNSNetwork)
+		}
 	default:
 		// No --<chain> flag was given.
 	}
@@ -2352,6 +2639,17 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 	}
 }
 
+// RegisterGRPCService adds the gRPC server of the chain data to the node.
+func RegisterGRPCService(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, host string, port int) {
+	grpcBackend, ok := backend.(ethgrpc.Backend)
+	if !ok {
+		Fatalf("Failed to register the gRPC service: backend doesn't support tracing")
+	}
+	if _, err := ethgrpc.New(stack, grpcBackend, filterSystem, host, port); err != nil {
+		Fatalf("Failed to register the gRPC service: %v", err)
+	}
+}
+
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	isLightClient := ethcfg.SyncMode == downloader.LightSync
@@ -2580,6 +2878,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
 		StateScheme:         scheme,
 		StateHistory:        ctx.Uint64(StateHistoryFlag.Name),
+		SupplyIndex:         ctx.Bool(SupplyIndexFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
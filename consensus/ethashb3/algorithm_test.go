@@ -0,0 +1,198 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethashb3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/sha3"
+)
+
+// epochVector is the expected seed hash and sizes of a single epoch, as stored
+// in testdata/epochs.json.
+type epochVector struct {
+	Epoch       uint64      `json:"epoch"`
+	SeedHash    common.Hash `json:"seedHash"`
+	CacheSize   uint64      `json:"cacheSize"`
+	DatasetSize uint64      `json:"datasetSize"`
+}
+
+// vectorSet is the expected cache, dataset items and proof-of-work results of
+// a single epoch, as stored in testdata/vectors.json. The format is the output
+// of the `ethashb3 vectors` command.
+type vectorSet struct {
+	Epoch        uint64      `json:"epoch"`
+	EpochLength  uint64      `json:"epochLength"`
+	SeedHash     common.Hash `json:"seedHash"`
+	CacheSize    uint64      `json:"cacheSize"`
+	DatasetSize  uint64      `json:"datasetSize"`
+	CacheHash    common.Hash `json:"cacheHash"` // Keccak256 of the little endian cache
+	DatasetItems []struct {
+		Index uint32        `json:"index"`
+		Item  hexutil.Bytes `json:"item"`
+	} `json:"datasetItems"`
+	Hashimoto []struct {
+		Hash      common.Hash      `json:"hash"`
+		Nonce     types.BlockNonce `json:"nonce"`
+		MixDigest common.Hash      `json:"mixDigest"`
+		Result    common.Hash      `json:"result"`
+	} `json:"hashimoto"`
+}
+
+// loadFixture decodes a JSON fixture from the testdata folder.
+func loadFixture(t *testing.T, name string, v interface{}) {
+	t.Helper()
+
+	blob, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if err := json.Unmarshal(blob, v); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+}
+
+// Tests whether calcEpoch returns the correct epoch
+func TestCalcEpoch(t *testing.T) {
+	tests := []struct {
+		block, epoch uint64
+	}{
+		{0, 0},
+		{epochLengthDefault - 1, 0},
+		{epochLengthDefault, 1},
+		{120000, 2},
+		{maxEpoch*epochLengthDefault - 1, maxEpoch - 1},
+	}
+	for i, tt := range tests {
+		if epoch := calcEpoch(tt.block, calcEpochLength(tt.block)); epoch != tt.epoch {
+			t.Errorf("test %d: epoch mismatch: have %d, want %d", i, epoch, tt.epoch)
+		}
+	}
+}
+
+// Tests whether the dataset size calculator works correctly by cross checking the
+// hard coded lookup table with the value generated by it.
+func TestSizeCalculations(t *testing.T) {
+	// Verify all the cache and dataset sizes from the lookup table.
+	for epoch, want := range cacheSizes {
+		if size := calcCacheSize(uint64(epoch)); size != want {
+			t.Errorf("cache %d: cache size mismatch: have %d, want %d", epoch, size, want)
+		}
+	}
+	for epoch, want := range datasetSizes {
+		if size := calcDatasetSize(uint64(epoch)); size != want {
+			t.Errorf("dataset %d: dataset size mismatch: have %d, want %d", epoch, size, want)
+		}
+	}
+}
+
+// Tests the seed hashes, cache sizes and dataset sizes of all the epochs up to
+// maxEpoch against the reference fixtures.
+func TestEpochVectors(t *testing.T) {
+	var tests []epochVector
+	loadFixture(t, "epochs.json", &tests)
+	if len(tests) != maxEpoch {
+		t.Fatalf("epoch count mismatch: have %d, want %d", len(tests), maxEpoch)
+	}
+	for i, tt := range tests {
+		if tt.Epoch != uint64(i) {
+			t.Fatalf("vector %d: epoch mismatch: have %d, want %d", i, tt.Epoch, i)
+		}
+		if seed := common.BytesToHash(seedHash(tt.Epoch, epochLengthDefault)); seed != tt.SeedHash {
+			t.Errorf("epoch %d: seed hash mismatch: have %x, want %x", tt.Epoch, seed, tt.SeedHash)
+		}
+		if size := cacheSize(tt.Epoch); size != tt.CacheSize {
+			t.Errorf("epoch %d: cache size mismatch: have %d, want %d", tt.Epoch, size, tt.CacheSize)
+		}
+		if size := datasetSize(tt.Epoch); size != tt.DatasetSize {
+			t.Errorf("epoch %d: dataset size mismatch: have %d, want %d", tt.Epoch, size, tt.DatasetSize)
+		}
+	}
+}
+
+// Tests the verification cache generation, the dataset item generation and the
+// light and full proof-of-work calculation against the reference fixtures.
+func TestAlgorithmVectors(t *testing.T) {
+	var tests []vectorSet
+	loadFixture(t, "vectors.json", &tests)
+
+	for i, tt := range tests {
+		if testing.Short() && tt.CacheSize > 1024 {
+			t.Logf("vector %d: skipping full sized epoch in short mode", i)
+			continue
+		}
+		if tt.EpochLength != calcEpochLength(tt.Epoch*tt.EpochLength) {
+			t.Fatalf("vector %d: epoch length mismatch: have %d, want %d", i, calcEpochLength(tt.Epoch*tt.EpochLength), tt.EpochLength)
+		}
+		seed := seedHash(tt.Epoch, tt.EpochLength)
+		if common.BytesToHash(seed) != tt.SeedHash {
+			t.Errorf("vector %d: seed hash mismatch: have %x, want %x", i, seed, tt.SeedHash)
+		}
+		// Verify the verification cache through its canonical little endian hash
+		cache := make([]uint32, tt.CacheSize/4)
+		generateCache(cache, tt.Epoch, tt.EpochLength, seed)
+
+		blob := make([]byte, len(cache)*4)
+		for j, word := range cache {
+			binary.LittleEndian.PutUint32(blob[j*4:], word)
+		}
+		if hash := crypto.Keccak256Hash(blob); hash != tt.CacheHash {
+			t.Errorf("vector %d: cache hash mismatch: have %x, want %x", i, hash, tt.CacheHash)
+		}
+		// Verify the individual dataset items
+		keccak512 := makeHasher(sha3.NewLegacyKeccak512())
+		for j, item := range tt.DatasetItems {
+			if have := generateDatasetItem(cache, item.Index, keccak512); !bytes.Equal(have, item.Item) {
+				t.Errorf("vector %d, item %d: dataset item mismatch: have %x, want %x", i, j, have, item.Item)
+			}
+		}
+		// Verify the proof-of-work results, also on the full dataset if small
+		var dataset []uint32
+		if tt.DatasetSize <= 32*1024 {
+			dataset = make([]uint32, tt.DatasetSize/4)
+			generateDataset(dataset, tt.Epoch, tt.EpochLength, cache)
+		}
+		for j, pow := range tt.Hashimoto {
+			digest, result := hashimotoLight(tt.DatasetSize, cache, pow.Hash[:], pow.Nonce.Uint64())
+			if common.BytesToHash(digest) != pow.MixDigest {
+				t.Errorf("vector %d, hash %d: light mix digest mismatch: have %x, want %x", i, j, digest, pow.MixDigest)
+			}
+			if common.BytesToHash(result) != pow.Result {
+				t.Errorf("vector %d, hash %d: light result mismatch: have %x, want %x", i, j, result, pow.Result)
+			}
+			if dataset == nil {
+				continue
+			}
+			digest, result = hashimotoFull(dataset, pow.Hash[:], pow.Nonce.Uint64())
+			if common.BytesToHash(digest) != pow.MixDigest {
+				t.Errorf("vector %d, hash %d: full mix digest mismatch: have %x, want %x", i, j, digest, pow.MixDigest)
+			}
+			if common.BytesToHash(result) != pow.Result {
+				t.Errorf("vector %d, hash %d: full result mismatch: have %x, want %x", i, j, result, pow.Result)
+			}
+		}
+	}
+}
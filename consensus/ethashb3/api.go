@@ -64,7 +64,10 @@ func (api *API) GetWork() ([4]string, error) {
 // SubmitWork can be used by external miner to submit their POW solution.
 // It returns an indication if the work was accepted.
 // Note either an invalid solution, a stale work a non-existent work will return false.
-func (api *API) SubmitWork(nonce types.BlockNonce, hash, digest common.Hash) bool {
+//
+// The optional id is the identifier the miner submits its hash rate with, used
+// to account the submission to the miner in GetWorkers.
+func (api *API) SubmitWork(nonce types.BlockNonce, hash, digest common.Hash, id *common.Hash) bool {
 	if api.ethashb3.remote == nil {
 		return false
 	}
@@ -75,6 +78,7 @@ func (api *API) SubmitWork(nonce types.BlockNonce, hash, digest common.Hash) boo
 		nonce:     nonce,
 		mixDigest: digest,
 		hash:      hash,
+		id:        id,
 		errc:      errc,
 	}:
 	case <-api.ethashb3.remote.exitCh:
@@ -111,3 +115,33 @@ func (api *API) SubmitHashrate(rate hexutil.Uint64, id common.Hash) bool {
 func (api *API) GetHashrate() uint64 {
 	return uint64(api.ethashb3.Hashrate())
 }
+
+// MiningAPI exposes the statistics of the miners connected to this node. It is
+// only available in the ethashb3 namespace.
+type MiningAPI struct {
+	ethashb3 *EthashB3
+}
+
+// GetStratumStats returns the share accounting of the built-in stratum server,
+// including the per-worker share counts and estimated hashrates.
+func (api *MiningAPI) GetStratumStats() (*StratumStats, error) {
+	if api.ethashb3.remote == nil || api.ethashb3.remote.stratum == nil {
+		return nil, errors.New("stratum server not running")
+	}
+	return api.ethashb3.remote.stratum.stats(), nil
+}
+
+// GetWorkers returns the submission accounting of the remote miners, both per
+// hash rate identifier and per coinbase of the submitted work.
+func (api *MiningAPI) GetWorkers() (*WorkerReport, error) {
+	if api.ethashb3.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	var req = make(chan *WorkerReport, 1)
+	select {
+	case api.ethashb3.remote.fetchStatsCh <- req:
+	case <-api.ethashb3.remote.exitCh:
+		return nil, errEthashB3Stopped
+	}
+	return <-req, nil
+}
@@ -115,6 +115,11 @@ func (ethashb3 *EthashB3) VerifyHeaders(chain consensus.ChainHeaderReader, heade
 		abort   = make(chan struct{})
 		unixNow = time.Now().Unix()
 	)
+	// Scheduled difficulty algorithms look beyond the parent, which might not yet
+	// be part of the chain, so resolve the ancestry from the batch too.
+	if len(chain.Config().GetEthashDifficultyAlgorithmSchedule()) > 0 {
+		chain = newBatchHeaderReader(chain, headers)
+	}
 	for i := 0; i < workers; i++ {
 		go func() {
 			for index := range inputs {
@@ -295,7 +300,13 @@ func (ethashb3 *EthashB3) verifyHeader(chain consensus.ChainHeaderReader, header
 // CalcDifficulty is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time
 // given the parent block's time and difficulty.
+//
+// If the chain configuration schedules a difficulty algorithm for the new
+// block, it supersedes the legacy adjustment.
 func (ethashb3 *EthashB3) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	if algorithm := scheduledDifficultyAlgorithm(chain.Config(), new(big.Int).Add(parent.Number, big1)); algorithm != nil {
+		return algorithm.calcDifficulty(chain, parent)
+	}
 	return CalcDifficulty(chain.Config(), time, parent)
 }
 
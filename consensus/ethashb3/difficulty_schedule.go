@@ -0,0 +1,285 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethashb3
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	lrupkg "github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+// difficultyAlgorithm is a difficulty adjustment algorithm which can be
+// scheduled by the chain configuration, replacing the legacy adjustment of
+// CalcDifficulty. Implementations derive the difficulty from the ancestry of
+// the parent only, so the timestamp chosen by the miner of the new block does
+// not influence its own difficulty.
+type difficultyAlgorithm interface {
+	calcDifficulty(chain consensus.ChainHeaderReader, parent *types.Header) *big.Int
+}
+
+// asertAnchors caches the anchors of the side chain headers, which are found by
+// walking their ancestry back to the anchor height.
+var asertAnchors = lrupkg.NewCache[asertAnchorKey, common.Hash](4096)
+
+// asertAnchorKey identifies the anchor at the given height of a header's ancestry.
+type asertAnchorKey struct {
+	hash   common.Hash
+	anchor uint64
+}
+
+// difficultyAlgorithms maps the algorithm names of the chain configuration to
+// the constructors of their implementations.
+var difficultyAlgorithms = map[string]func(config *ctypes.DifficultyAlgorithmConfig, activation uint64) difficultyAlgorithm{
+	ctypes.DifficultyAlgorithmLWMA:  newLWMA,
+	ctypes.DifficultyAlgorithmASERT: newASERT,
+}
+
+// scheduledDifficultyAlgorithm returns the difficulty algorithm scheduled for
+// the given block, or nil if the legacy adjustment applies.
+func scheduledDifficultyAlgorithm(config ctypes.ChainConfigurator, number *big.Int) difficultyAlgorithm {
+	algorithm, activation := ctypes.EthashDifficultyAlgorithm(config, number)
+	if algorithm == nil {
+		return nil
+	}
+	if create, ok := difficultyAlgorithms[algorithm.Algorithm]; ok {
+		return create(algorithm, activation)
+	}
+	return nil
+}
+
+// lwma is the linearly weighted moving average difficulty algorithm (zawy12's
+// LWMA-1). It targets the average difficulty of the recent blocks, scaled by the
+// ratio of the target block time and their solve times, where the solve times of
+// the more recent blocks weigh linearly more.
+type lwma struct {
+	target uint64 // Target block time, in seconds
+	window uint64 // Number of blocks averaged over
+}
+
+func newLWMA(config *ctypes.DifficultyAlgorithmConfig, activation uint64) difficultyAlgorithm {
+	return &lwma{target: config.TargetBlockTime, window: config.Window}
+}
+
+func (a *lwma) calcDifficulty(chain consensus.ChainHeaderReader, parent *types.Header) *big.Int {
+	// Shorten the window near genesis, there's not enough history otherwise
+	n := a.window
+	if number := parent.Number.Uint64(); number < n {
+		n = number
+	}
+	if n == 0 {
+		return new(big.Int).Set(parent.Difficulty)
+	}
+	// Gather the headers of the window, along with the one preceding it
+	headers := make([]*types.Header, n+1)
+	headers[n] = parent
+	for i := n; i > 0; i-- {
+		if headers[i-1] = chain.GetHeader(headers[i].ParentHash, headers[i].Number.Uint64()-1); headers[i-1] == nil {
+			// Unknown ancestry, the parent itself can't be verified either
+			return new(big.Int).Set(parent.Difficulty)
+		}
+	}
+	var (
+		weighted uint64 // Sum of the solve times, weighted by recency
+		total    = new(big.Int)
+	)
+	for i := uint64(1); i <= n; i++ {
+		// Limit the solve times to 6x the target, avoiding a single block with an
+		// excessive timestamp to drag the difficulty down.
+		solvetime := uint64(1)
+		if headers[i].Time > headers[i-1].Time {
+			solvetime = headers[i].Time - headers[i-1].Time
+		}
+		if solvetime > 6*a.target {
+			solvetime = 6 * a.target
+		}
+		weighted += solvetime * i
+		total.Add(total, headers[i].Difficulty)
+	}
+	// Limit the difficulty increase to 10x of the average
+	k := n * (n + 1) / 2
+	if min := k * a.target / 10; weighted < min {
+		weighted = min
+	}
+	if weighted == 0 {
+		weighted = 1
+	}
+	// next = average difficulty * target * k / weighted solve times
+	next := new(big.Int).Mul(total, new(big.Int).SetUint64(a.target*k))
+	next.Div(next, new(big.Int).SetUint64(n*weighted))
+
+	if next.Cmp(MinimumDifficulty) < 0 {
+		next.Set(MinimumDifficulty)
+	}
+	return next
+}
+
+// asert is the absolutely scheduled exponentially rising targets difficulty
+// algorithm (BCH's aserti3-2d). The difficulty is derived from an anchor block
+// and rises or falls exponentially with how much the chain is ahead of or
+// behind the ideal block schedule since the anchor. The anchor is the parent of
+// the activation block in the ancestry of each block, so side chains forked
+// below it have anchors of their own.
+type asert struct {
+	target   uint64 // Target block time, in seconds
+	halfLife uint64 // Schedule drift which halves or doubles the difficulty, in seconds
+	anchor   uint64 // Number of the anchor block
+}
+
+func newASERT(config *ctypes.DifficultyAlgorithmConfig, activation uint64) difficultyAlgorithm {
+	var anchor uint64
+	if activation > 0 {
+		anchor = activation - 1
+	}
+	return &asert{target: config.TargetBlockTime, halfLife: config.HalfLife, anchor: anchor}
+}
+
+func (a *asert) calcDifficulty(chain consensus.ChainHeaderReader, parent *types.Header) *big.Int {
+	anchor := a.anchorOf(chain, parent)
+	if anchor == nil {
+		// Unknown ancestry, the parent itself can't be verified either
+		return new(big.Int).Set(parent.Difficulty)
+	}
+	var (
+		heights = int64(parent.Number.Uint64() - anchor.Number.Uint64())
+		elapsed = int64(parent.Time) - int64(anchor.Time)
+	)
+	// The exponent is the schedule drift in half lives, in 16.16 fixed point.
+	// A chain ahead of schedule has a positive exponent, raising the difficulty.
+	exponent := (int64(a.target)*heights - elapsed) * 65536 / int64(a.halfLife)
+
+	// Decompose the exponent into the integer shifts and a fractional part, the
+	// latter approximated by a cubic polynomial: 2^x ~ 1 + 0.695502049*x +
+	// 0.2262698*x^2 + 0.0782318*x^3 for 0 <= x < 1.
+	shifts := exponent >> 16
+	frac := uint64(exponent & 0xffff)
+	factor := 65536 + ((195766423245049*frac + 971821376*frac*frac + 5127*frac*frac*frac + (1 << 47)) >> 48)
+
+	next := new(big.Int).Mul(anchor.Difficulty, new(big.Int).SetUint64(factor))
+	shifts -= 16
+	switch {
+	case shifts > 256:
+		next.Lsh(next, 256)
+	case shifts > 0:
+		next.Lsh(next, uint(shifts))
+	case shifts < -256:
+		next.SetUint64(0)
+	case shifts < 0:
+		next.Rsh(next, uint(-shifts))
+	}
+	if next.Cmp(MinimumDifficulty) < 0 {
+		next.Set(MinimumDifficulty)
+	}
+	return next
+}
+
+// anchorOf returns the anchor in the ancestry of the given header, which may be
+// on a side chain forked below the anchor. The ancestry is walked back until it
+// joins the canonical chain, whose anchor is then shared, or reaches the anchor
+// height.
+func (a *asert) anchorOf(chain consensus.ChainHeaderReader, header *types.Header) *types.Header {
+	// The headers of a batch being verified are not canonical yet, so only the
+	// chain beneath is checked for canonicity.
+	canonical := chain
+	if batch, ok := chain.(*batchHeaderReader); ok {
+		canonical = batch.ChainHeaderReader
+	}
+	key := asertAnchorKey{hash: header.Hash(), anchor: a.anchor}
+	for header.Number.Uint64() > a.anchor {
+		hash := header.Hash()
+		if cached, ok := asertAnchors.Get(asertAnchorKey{hash: hash, anchor: a.anchor}); ok {
+			if anchor := chain.GetHeader(cached, a.anchor); anchor != nil {
+				asertAnchors.Add(key, cached)
+				return anchor
+			}
+		}
+		number := header.Number.Uint64()
+		if isCanonical(canonical, hash, number) {
+			anchor := canonical.GetHeaderByNumber(a.anchor)
+			// Recheck in case of a reorg in between, as the anchor might not be
+			// in the ancestry of the header anymore.
+			if anchor != nil && isCanonical(canonical, hash, number) {
+				asertAnchors.Add(key, anchor.Hash())
+				return anchor
+			}
+		}
+		if header = chain.GetHeader(header.ParentHash, number-1); header == nil {
+			return nil
+		}
+	}
+	if header.Number.Uint64() != a.anchor {
+		return nil
+	}
+	asertAnchors.Add(key, header.Hash())
+	return header
+}
+
+// isCanonical reports whether the header with the given hash and number is part
+// of the canonical chain.
+func isCanonical(chain consensus.ChainHeaderReader, hash common.Hash, number uint64) bool {
+	header := chain.GetHeaderByNumber(number)
+	return header != nil && header.Hash() == hash
+}
+
+// batchHeaderReader is a chain reader which also resolves the headers of a
+// batch being verified, which are not yet part of the chain. It is needed by
+// the scheduled difficulty algorithms, which look beyond the parent header.
+type batchHeaderReader struct {
+	consensus.ChainHeaderReader
+	hashes  map[common.Hash]*types.Header
+	numbers map[uint64]*types.Header
+}
+
+func newBatchHeaderReader(chain consensus.ChainHeaderReader, headers []*types.Header) *batchHeaderReader {
+	r := &batchHeaderReader{
+		ChainHeaderReader: chain,
+		hashes:            make(map[common.Hash]*types.Header, len(headers)),
+		numbers:           make(map[uint64]*types.Header, len(headers)),
+	}
+	for _, header := range headers {
+		r.hashes[header.Hash()] = header
+		r.numbers[header.Number.Uint64()] = header
+	}
+	return r
+}
+
+// GetHeader retrieves a header by hash and number, from the batch if present.
+func (r *batchHeaderReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := r.hashes[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return r.ChainHeaderReader.GetHeader(hash, number)
+}
+
+// GetHeaderByNumber retrieves a header by number, from the batch if present.
+func (r *batchHeaderReader) GetHeaderByNumber(number uint64) *types.Header {
+	if header := r.numbers[number]; header != nil {
+		return header
+	}
+	return r.ChainHeaderReader.GetHeaderByNumber(number)
+}
+
+// GetHeaderByHash retrieves a header by hash, from the batch if present.
+func (r *batchHeaderReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	if header := r.hashes[hash]; header != nil {
+		return header
+	}
+	return r.ChainHeaderReader.GetHeaderByHash(hash)
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethashb3
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+// simulatedChain is a header chain mined by a simulated hashrate, where each
+// block takes exactly its expected solve time.
+type simulatedChain struct {
+	consensus.ChainHeaderReader
+	config  ctypes.ChainConfigurator
+	headers []*types.Header
+}
+
+func (c *simulatedChain) Config() ctypes.ChainConfigurator { return c.config }
+
+func (c *simulatedChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+func (c *simulatedChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number < uint64(len(c.headers)) {
+		return c.headers[number]
+	}
+	return nil
+}
+
+// mine extends the chain by the given number of blocks at the given hashrate
+// and returns the average solve time of the last tail blocks.
+func (c *simulatedChain) mine(engine *EthashB3, hashrate int64, blocks int, tail int) float64 {
+	var elapsed uint64
+	for i := 0; i < blocks; i++ {
+		parent := c.headers[len(c.headers)-1]
+		difficulty := engine.CalcDifficulty(c, parent.Time+1, parent)
+
+		solvetime := new(big.Int).Div(difficulty, big.NewInt(hashrate)).Uint64()
+		if solvetime == 0 {
+			solvetime = 1
+		}
+		c.headers = append(c.headers, &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, big1),
+			Time:       parent.Time + solvetime,
+			Difficulty: difficulty,
+		})
+		if i >= blocks-tail {
+			elapsed += solvetime
+		}
+	}
+	return float64(elapsed) / float64(tail)
+}
+
+// Tests that the scheduled difficulty algorithms keep the block time on target
+// across a sudden influx and departure of rented hashpower.
+func TestDifficultyAlgorithmHashrateSwings(t *testing.T) {
+	const (
+		target   = 13
+		hashrate = 1000000
+	)
+	tests := []*ctypes.DifficultyAlgorithmConfig{
+		{Algorithm: ctypes.DifficultyAlgorithmLWMA, TargetBlockTime: target, Window: 60},
+		{Algorithm: ctypes.DifficultyAlgorithmASERT, TargetBlockTime: target, HalfLife: 600},
+	}
+	for _, algorithm := range tests {
+		config := &coregeth.CoreGethChainConfig{
+			EthashB3: &ctypes.EthashB3Config{},
+			DifficultyAlgorithmSchedule: ctypes.Uint64DifficultyAlgorithmMapEncodesHex{
+				1: algorithm,
+			},
+		}
+		chain := &simulatedChain{
+			config: config,
+			headers: []*types.Header{{
+				Number:     new(big.Int),
+				Difficulty: big.NewInt(hashrate * target),
+			}},
+		}
+		engine := NewFaker()
+
+		// Steady hashrate, a 10x spike of rented hashpower, then its departure
+		phases := []struct {
+			name     string
+			hashrate int64
+		}{
+			{"steady", hashrate},
+			{"spike", 10 * hashrate},
+			{"drop", hashrate},
+		}
+		for _, phase := range phases {
+			have := chain.mine(engine, phase.hashrate, 600, 100)
+			if have < target*0.8 || have > target*1.2 {
+				t.Errorf("%s: %s phase: block time mismatch: have %.2fs, want %ds", algorithm.Algorithm, phase.name, have, target)
+			}
+		}
+	}
+}
+
+// Tests that the legacy adjustment applies until a scheduled algorithm
+// activates, and again once the schedule returns to it.
+func TestDifficultyAlgorithmSchedule(t *testing.T) {
+	config := &coregeth.CoreGethChainConfig{
+		EthashB3: &ctypes.EthashB3Config{},
+		DifficultyAlgorithmSchedule: ctypes.Uint64DifficultyAlgorithmMapEncodesHex{
+			10: {Algorithm: ctypes.DifficultyAlgorithmLWMA, TargetBlockTime: 13, Window: 60},
+			20: {Algorithm: ctypes.DifficultyAlgorithmLegacy},
+		},
+	}
+	tests := []struct {
+		number uint64
+		want   bool
+	}{
+		{0, false}, {9, false}, {10, true}, {19, true}, {20, false}, {100, false},
+	}
+	for _, tt := range tests {
+		if have := scheduledDifficultyAlgorithm(config, new(big.Int).SetUint64(tt.number)) != nil; have != tt.want {
+			t.Errorf("block %d: scheduled mismatch: have %v, want %v", tt.number, have, tt.want)
+		}
+	}
+}
+
+// forkedChain is a simulated chain which also knows the headers of a side chain.
+type forkedChain struct {
+	*simulatedChain
+	side map[common.Hash]*types.Header
+}
+
+func (c *forkedChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.side[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return c.simulatedChain.GetHeader(hash, number)
+}
+
+// Tests that the ASERT difficulty of a side chain forked below the anchor is
+// derived from its own anchor, regardless of the canonical chain.
+func TestASERTSideChainAnchor(t *testing.T) {
+	config := &coregeth.CoreGethChainConfig{
+		EthashB3: &ctypes.EthashB3Config{},
+		DifficultyAlgorithmSchedule: ctypes.Uint64DifficultyAlgorithmMapEncodesHex{
+			10: {Algorithm: ctypes.DifficultyAlgorithmASERT, TargetBlockTime: 13, HalfLife: 600},
+		},
+	}
+	genesis := &types.Header{Number: new(big.Int), Difficulty: big.NewInt(13000000)}
+	engine := NewFaker()
+
+	// Mine the canonical chain and a side chain forked at block 5, at different
+	// hashrates so their anchors differ.
+	canonical := &simulatedChain{config: config, headers: []*types.Header{genesis}}
+	canonical.mine(engine, 1000000, 20, 1)
+	side := &simulatedChain{config: config, headers: append([]*types.Header{}, canonical.headers[:6]...)}
+	side.mine(engine, 3000000, 14, 1)
+
+	if canonical.headers[9].Hash() == side.headers[9].Hash() {
+		t.Fatal("side chain shares the anchor of the canonical chain")
+	}
+	forked := &forkedChain{simulatedChain: canonical, side: make(map[common.Hash]*types.Header)}
+	for _, header := range side.headers[6:] {
+		forked.side[header.Hash()] = header
+	}
+	algorithm := scheduledDifficultyAlgorithm(config, big.NewInt(10))
+	for number := 9; number < len(side.headers); number++ {
+		parent := side.headers[number]
+		want := algorithm.calcDifficulty(side, parent)
+		if have := algorithm.calcDifficulty(forked, parent); have.Cmp(want) != 0 {
+			t.Errorf("block %d: difficulty mismatch: have %v, want %v", number+1, have, want)
+		}
+	}
+	// The canonical chain is unaffected by the side chain anchors being cached.
+	for number := 9; number < len(canonical.headers); number++ {
+		parent := canonical.headers[number]
+		want := algorithm.calcDifficulty(canonical, parent)
+		if have := algorithm.calcDifficulty(forked, parent); have.Cmp(want) != 0 {
+			t.Errorf("canonical block %d: difficulty mismatch: have %v, want %v", number+1, have, want)
+		}
+	}
+}
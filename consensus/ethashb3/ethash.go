@@ -42,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/crypto/sha3"
 )
 
 var ErrInvalidDumpMagic = errors.New("invalid dump magic")
@@ -554,6 +555,10 @@ type Config struct {
 	// be block header JSON objects instead of work package arrays.
 	NotifyFull bool
 
+	// Stratum configures the built-in stratum server serving the work
+	// packages of the remote sealer to pool-less mining rigs.
+	Stratum StratumConfig
+
 	Log log.Logger `toml:"-"`
 }
 
@@ -821,6 +826,10 @@ func (ethashb3 *EthashB3) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 			Namespace: "ethashb3",
 			Service:   &API{ethashb3},
 		},
+		{
+			Namespace: "ethashb3",
+			Service:   &MiningAPI{ethashb3},
+		},
 	}
 }
 
@@ -839,3 +848,47 @@ func CalcEpochLength(block uint64) uint64 {
 func CalcEpoch(block uint64, epochLength uint64) uint64 {
 	return calcEpoch(block, epochLength)
 }
+
+// CacheSize returns the size of the ethashb3 verification cache of an epoch.
+func CacheSize(epoch uint64) uint64 {
+	return cacheSize(epoch)
+}
+
+// DatasetSize returns the size of the ethashb3 mining dataset of an epoch.
+func DatasetSize(epoch uint64) uint64 {
+	return datasetSize(epoch)
+}
+
+// GenerateCache generates a verification cache of the given size for an epoch
+// in memory, in machine byte order.
+func GenerateCache(size uint64, epoch uint64, epochLength uint64) []uint32 {
+	cache := make([]uint32, size/4)
+	generateCache(cache, epoch, epochLength, seedHash(epoch, epochLength))
+	return cache
+}
+
+// GenerateDataset generates a mining dataset of the given size for an epoch in
+// memory from its verification cache, in machine byte order.
+func GenerateDataset(size uint64, epoch uint64, epochLength uint64, cache []uint32) []uint32 {
+	dataset := make([]uint32, size/4)
+	generateDataset(dataset, epoch, epochLength, cache)
+	return dataset
+}
+
+// DatasetItem calculates a single 64 byte item of the mining dataset from its
+// verification cache.
+func DatasetItem(cache []uint32, index uint32) []byte {
+	return generateDatasetItem(cache, index, makeHasher(sha3.NewLegacyKeccak512()))
+}
+
+// HashimotoLight calculates the mix digest and the result of a header hash and
+// nonce, generating the needed dataset items on the fly from the cache.
+func HashimotoLight(size uint64, cache []uint32, hash []byte, nonce uint64) ([]byte, []byte) {
+	return hashimotoLight(size, cache, hash, nonce)
+}
+
+// HashimotoFull calculates the mix digest and the result of a header hash and
+// nonce using the full mining dataset.
+func HashimotoFull(dataset []uint32, hash []byte, nonce uint64) ([]byte, []byte) {
+	return hashimotoFull(dataset, hash, nonce)
+}
@@ -239,6 +239,7 @@ const remoteSealerTimeout = 1 * time.Second
 type remoteSealer struct {
 	works        map[common.Hash]*types.Block
 	rates        map[common.Hash]hashrate
+	workers      *workerTracker // Per hashrate ID and per coinbase submission accounting
 	currentBlock *types.Block
 	currentWork  [4]string
 	notifyCtx    context.Context
@@ -246,14 +247,16 @@ type remoteSealer struct {
 	reqWG        sync.WaitGroup     // tracks notification request goroutines
 
 	ethash       *EthashB3
+	stratum      *stratumServer // Built-in stratum server, nil if disabled
 	noverify     bool
 	notifyURLs   []string
 	results      chan<- *types.Block
-	workCh       chan *sealTask   // Notification channel to push new work and relative result channel to remote sealer
-	fetchWorkCh  chan *sealWork   // Channel used for remote sealer to fetch mining work
-	submitWorkCh chan *mineResult // Channel used for remote sealer to submit their mining result
-	fetchRateCh  chan chan uint64 // Channel used to gather submitted hash rate for local or remote sealer.
-	submitRateCh chan *hashrate   // Channel used for remote sealer to submit their mining hashrate
+	workCh       chan *sealTask          // Notification channel to push new work and relative result channel to remote sealer
+	fetchWorkCh  chan *sealWork          // Channel used for remote sealer to fetch mining work
+	submitWorkCh chan *mineResult        // Channel used for remote sealer to submit their mining result
+	fetchRateCh  chan chan uint64        // Channel used to gather submitted hash rate for local or remote sealer.
+	submitRateCh chan *hashrate          // Channel used for remote sealer to submit their mining hashrate
+	fetchStatsCh chan chan *WorkerReport // Channel used to gather the per worker submission accounting
 	requestExit  chan struct{}
 	exitCh       chan struct{}
 }
@@ -269,6 +272,7 @@ type mineResult struct {
 	nonce     types.BlockNonce
 	mixDigest common.Hash
 	hash      common.Hash
+	id        *common.Hash // Hashrate ID of the submitting miner, if known
 
 	errc chan error
 }
@@ -298,14 +302,24 @@ func startRemoteSealer(ethash *EthashB3, urls []string, noverify bool) *remoteSe
 		cancelNotify: cancel,
 		works:        make(map[common.Hash]*types.Block),
 		rates:        make(map[common.Hash]hashrate),
+		workers:      newWorkerTracker(),
 		workCh:       make(chan *sealTask),
 		fetchWorkCh:  make(chan *sealWork),
 		submitWorkCh: make(chan *mineResult),
 		fetchRateCh:  make(chan chan uint64),
 		submitRateCh: make(chan *hashrate),
+		fetchStatsCh: make(chan chan *WorkerReport),
 		requestExit:  make(chan struct{}),
 		exitCh:       make(chan struct{}),
 	}
+	if ethash.config.Stratum.Addr != "" {
+		stratum, err := startStratumServer(ethash, s, ethash.config.Stratum)
+		if err != nil {
+			ethash.config.Log.Error("Failed to start stratum server", "addr", ethash.config.Stratum.Addr, "err", err)
+		} else {
+			s.stratum = stratum
+		}
+	}
 	go s.loop()
 	return s
 }
@@ -313,6 +327,9 @@ func startRemoteSealer(ethash *EthashB3, urls []string, noverify bool) *remoteSe
 func (s *remoteSealer) loop() {
 	defer func() {
 		s.ethash.config.Log.Trace("EthashB3 remote sealer is exiting")
+		if s.stratum != nil {
+			s.stratum.close()
+		}
 		s.cancelNotify()
 		s.reqWG.Wait()
 		close(s.exitCh)
@@ -329,6 +346,9 @@ func (s *remoteSealer) loop() {
 			s.results = work.results
 			s.makeWork(work.block)
 			s.notifyWork()
+			if s.stratum != nil {
+				s.stratum.setWork(work.block, s.currentWork)
+			}
 
 		case work := <-s.fetchWorkCh:
 			// Return current mining work to remote miner.
@@ -340,7 +360,14 @@ func (s *remoteSealer) loop() {
 
 		case result := <-s.submitWorkCh:
 			// Verify submitted PoW solution based on maintained mining blocks.
-			if s.submitWork(result.nonce, result.mixDigest, result.hash) {
+			var coinbase *common.Address
+			if block := s.works[result.hash]; block != nil {
+				addr := block.Coinbase()
+				coinbase = &addr
+			}
+			outcome := s.submitWork(result.nonce, result.mixDigest, result.hash)
+			s.workers.submitted(result.id, coinbase, outcome)
+			if outcome == submitAccepted {
 				result.errc <- nil
 			} else {
 				result.errc <- errInvalidSealResult
@@ -349,6 +376,7 @@ func (s *remoteSealer) loop() {
 		case result := <-s.submitRateCh:
 			// Trace remote sealer's hash rate by submitted value.
 			s.rates[result.id] = hashrate{rate: result.rate, ping: time.Now()}
+			s.workers.reported(result.id, result.rate)
 			close(result.done)
 
 		case req := <-s.fetchRateCh:
@@ -360,6 +388,10 @@ func (s *remoteSealer) loop() {
 			}
 			req <- total
 
+		case req := <-s.fetchStatsCh:
+			// Gather the submission accounting of all remote miners.
+			req <- s.workers.report()
+
 		case <-ticker.C:
 			// Clear stale submitted hash rate.
 			for id, rate := range s.rates {
@@ -367,6 +399,7 @@ func (s *remoteSealer) loop() {
 					delete(s.rates, id)
 				}
 			}
+			s.workers.refresh()
 			// Clear stale pending blocks
 			if s.currentBlock != nil {
 				for hash, block := range s.works {
@@ -447,18 +480,18 @@ func (s *remoteSealer) sendNotification(ctx context.Context, url string, json []
 }
 
 // submitWork verifies the submitted pow solution, returning
-// whether the solution was accepted or not (not can be both a bad pow as well as
-// any other error, like no pending work or stale mining result).
-func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash, sealhash common.Hash) bool {
+// whether the solution was accepted, stale (no pending work, stale mining
+// result or result not consumed) or invalid (bad pow).
+func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash, sealhash common.Hash) submitOutcome {
 	if s.currentBlock == nil {
 		s.ethash.config.Log.Error("Pending work without block", "sealhash", sealhash)
-		return false
+		return submitStale
 	}
 	// Make sure the work submitted is present
 	block := s.works[sealhash]
 	if block == nil {
 		s.ethash.config.Log.Warn("Work submitted but none pending", "sealhash", sealhash, "curnumber", s.currentBlock.NumberU64())
-		return false
+		return submitStale
 	}
 	// Verify the correctness of submitted result.
 	header := block.Header()
@@ -469,13 +502,13 @@ func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash,
 	if !s.noverify {
 		if err := s.ethash.verifySeal(nil, header, true); err != nil {
 			s.ethash.config.Log.Warn("Invalid proof-of-work submitted", "sealhash", sealhash, "elapsed", common.PrettyDuration(time.Since(start)), "err", err)
-			return false
+			return submitInvalid
 		}
 	}
 	// Make sure the result channel is assigned.
 	if s.results == nil {
 		s.ethash.config.Log.Warn("EthashB3 result channel is empty, submitted mining result is rejected")
-		return false
+		return submitStale
 	}
 	s.ethash.config.Log.Trace("Verified correct proof-of-work", "sealhash", sealhash, "elapsed", common.PrettyDuration(time.Since(start)))
 
@@ -487,13 +520,13 @@ func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash,
 		select {
 		case s.results <- solution:
 			s.ethash.config.Log.Debug("Work submitted is acceptable", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
-			return true
+			return submitAccepted
 		default:
 			s.ethash.config.Log.Warn("Sealing result is not read by miner", "mode", "remote", "sealhash", sealhash)
-			return false
+			return submitStale
 		}
 	}
 	// The submitted block is too old to accept, drop it.
 	s.ethash.config.Log.Warn("Work submitted is too old", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
-	return false
+	return submitStale
 }
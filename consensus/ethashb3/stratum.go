@@ -0,0 +1,970 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethashb3
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	stratumProtocolV1 = "EthereumStratum/1.0.0" // NiceHash EthereumStratum/1.0.0
+	stratumProtocolV2 = "EthereumStratum/2.0.0" // NiceHash EthereumStratum/2.0.0
+
+	stratumReadTimeout     = 10 * time.Minute // Maximum time a worker may stay silent before being dropped
+	stratumWriteTimeout    = 10 * time.Second // Maximum time a single message may take to be written
+	stratumMaxMessageSize  = 16 * 1024        // Maximum size of a single line-delimited message
+	stratumMaxErrors       = 64               // Consecutive protocol errors before a worker is dropped
+	stratumExtranonceBytes = 2                // Nonce prefix bytes assigned to each session
+	stratumRetargetShares  = 8                // Number of shares after which the share difficulty is retargeted
+	stratumHashrateWindow  = 10 * time.Minute // Window over which the worker hashrate is estimated
+	stratumMaxWorkers      = 1024             // Maximum number of worker names tracked at once
+	stratumWorkerExpiry    = time.Hour        // Time after which a worker without sessions is dropped
+
+	// stratumBaseDifficulty is the share difficulty corresponding to a stratum
+	// difficulty of 1, as per the EthereumStratum/1.0.0 specification.
+	stratumBaseDifficulty = 1 << 32
+)
+
+var (
+	stratumAcceptedMeter = metrics.NewRegisteredMeter("ethashb3/stratum/shares/accepted", nil)
+	stratumStaleMeter    = metrics.NewRegisteredMeter("ethashb3/stratum/shares/stale", nil)
+	stratumInvalidMeter  = metrics.NewRegisteredMeter("ethashb3/stratum/shares/invalid", nil)
+	stratumBlockMeter    = metrics.NewRegisteredMeter("ethashb3/stratum/blocks", nil)
+	stratumSessionGauge  = metrics.NewRegisteredGauge("ethashb3/stratum/sessions", nil)
+)
+
+// Error codes as defined by the EthereumStratum/2.0.0 specification.
+var (
+	errStratumUnknown       = &stratumError{20, "other/unknown"}
+	errStratumJobNotFound   = &stratumError{21, "job not found (stale)"}
+	errStratumDuplicate     = &stratumError{22, "duplicate share"}
+	errStratumLowDifficulty = &stratumError{23, "low difficulty share"}
+	errStratumUnauthorized  = &stratumError{24, "unauthorized worker"}
+	errStratumNotSubscribed = &stratumError{25, "not subscribed"}
+	errStratumInvalidParams = &stratumError{-32602, "invalid params"}
+	errStratumNoMethod      = &stratumError{-32601, "method not found"}
+	errStratumWorkersFull   = &stratumError{24, "too many workers"}
+
+	errStratumSessionsFull = errors.New("no free extranonce available")
+)
+
+// StratumConfig are the configuration parameters of the built-in stratum server.
+type StratumConfig struct {
+	Addr          string        // Listen address of the stratum server (empty = disabled)
+	Difficulty    uint64        // Initial share difficulty assigned to new workers
+	MinDifficulty uint64        // Lower bound for the variable share difficulty
+	MaxDifficulty uint64        // Upper bound for the variable share difficulty
+	ShareTime     time.Duration // Target time between shares of a single worker (0 = fixed difficulty)
+}
+
+// DefaultStratumConfig contains the default settings of the stratum server.
+var DefaultStratumConfig = StratumConfig{
+	Difficulty:    stratumBaseDifficulty,
+	MinDifficulty: stratumBaseDifficulty / 256,
+	MaxDifficulty: stratumBaseDifficulty * 1024 * 1024,
+	ShareTime:     10 * time.Second,
+}
+
+// StratumWorker is the share accounting of a single worker connected to the
+// built-in stratum server. A worker is identified by its login name and may
+// be connected through multiple sessions.
+type StratumWorker struct {
+	Name      string    `json:"name"`
+	Sessions  int       `json:"sessions"`
+	Accepted  uint64    `json:"accepted"`
+	Stale     uint64    `json:"stale"`
+	Invalid   uint64    `json:"invalid"`
+	Blocks    uint64    `json:"blocks"`
+	Hashrate  uint64    `json:"hashrate"`
+	LastShare time.Time `json:"lastShare"`
+
+	shares   []stratumShare // Accepted shares within the hashrate window
+	lastSeen time.Time      // Last authorization, disconnection or share, for expiry
+}
+
+// trimShares drops the shares which fell out of the hashrate window.
+func (w *StratumWorker) trimShares(now time.Time) {
+	var i int
+	for i < len(w.shares) && now.Sub(w.shares[i].time) > stratumHashrateWindow {
+		i++
+	}
+	w.shares = w.shares[i:]
+}
+
+// StratumStats is the aggregated share accounting of the built-in stratum server.
+type StratumStats struct {
+	Addr     string           `json:"addr"`
+	Sessions int              `json:"sessions"`
+	Accepted uint64           `json:"accepted"`
+	Stale    uint64           `json:"stale"`
+	Invalid  uint64           `json:"invalid"`
+	Blocks   uint64           `json:"blocks"`
+	Workers  []*StratumWorker `json:"workers"`
+}
+
+// stratumShare is an accepted share, used to estimate the hashrate of a worker.
+type stratumShare struct {
+	time       time.Time
+	difficulty uint64
+}
+
+// stratumError is a protocol level error returned to stratum workers.
+type stratumError struct {
+	code    int
+	message string
+}
+
+func (e *stratumError) Error() string { return e.message }
+
+// stratumJob is a mining job derived from the remote sealer's current work.
+type stratumJob struct {
+	id       string
+	number   uint64
+	epoch    uint64
+	sealhash common.Hash
+	seedhash common.Hash
+	target   *big.Int            // Block target, 2^256/difficulty
+	nonces   map[uint64]struct{} // Nonces submitted for this job, to reject duplicates
+}
+
+// stratumRequest is a line-delimited JSON-RPC request sent by a worker.
+type stratumRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// stratumResponse is a line-delimited JSON-RPC response sent to a worker.
+type stratumResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+// stratumNotification is a server initiated message sent to a worker.
+type stratumNotification struct {
+	ID     interface{} `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+// stratumServer is a TCP server speaking the EthereumStratum/1.0.0 and
+// EthereumStratum/2.0.0 protocols, serving work packages of the remote sealer.
+type stratumServer struct {
+	ethash   *EthashB3
+	sealer   *remoteSealer
+	config   StratumConfig
+	listener net.Listener
+
+	lock      sync.Mutex
+	job       *stratumJob                // Most recent job sent to the workers
+	jobs      map[string]*stratumJob     // Jobs still acceptable for share submission
+	jobSeq    uint64                     // Sequence number of the last created job
+	sessions  map[uint16]*stratumSession // Connected sessions by their extranonce
+	nextNonce uint16                     // Next extranonce to try assigning
+	workers   map[string]*StratumWorker  // Share accounting by worker name
+	accepted  uint64
+	stale     uint64
+	invalid   uint64
+	blocks    uint64
+
+	notifyCh chan struct{} // Signals a new job to be broadcast
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// startStratumServer starts listening for stratum workers on the configured
+// address, serving the work maintained by the given remote sealer.
+func startStratumServer(ethash *EthashB3, sealer *remoteSealer, config StratumConfig) (*stratumServer, error) {
+	if config.Difficulty == 0 {
+		config.Difficulty = DefaultStratumConfig.Difficulty
+	}
+	if config.MinDifficulty == 0 {
+		config.MinDifficulty = DefaultStratumConfig.MinDifficulty
+	}
+	if config.MaxDifficulty == 0 {
+		config.MaxDifficulty = DefaultStratumConfig.MaxDifficulty
+	}
+	if config.MinDifficulty > config.MaxDifficulty {
+		return nil, fmt.Errorf("invalid stratum difficulty bounds: min %d > max %d", config.MinDifficulty, config.MaxDifficulty)
+	}
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &stratumServer{
+		ethash:   ethash,
+		sealer:   sealer,
+		config:   config,
+		listener: listener,
+		jobs:     make(map[string]*stratumJob),
+		sessions: make(map[uint16]*stratumSession),
+		workers:  make(map[string]*StratumWorker),
+		notifyCh: make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+	}
+	s.wg.Add(2)
+	go s.acceptLoop()
+	go s.notifyLoop()
+
+	ethash.config.Log.Info("Stratum server started", "addr", listener.Addr())
+	return s, nil
+}
+
+// close stops the server, disconnecting all workers.
+func (s *stratumServer) close() {
+	close(s.quit)
+	s.listener.Close()
+
+	s.lock.Lock()
+	for _, sess := range s.sessions {
+		sess.conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	s.ethash.config.Log.Info("Stratum server stopped", "addr", s.listener.Addr())
+}
+
+// setWork is called by the remote sealer whenever it creates new work. It
+// must not block, as the sealer's loop is waiting on it.
+func (s *stratumServer) setWork(block *types.Block, work [4]string) {
+	sealhash := common.HexToHash(work[0])
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The same work may be pushed twice, e.g. when changing CPU threads.
+	if s.job != nil && s.job.sealhash == sealhash {
+		return
+	}
+	s.jobSeq++
+	number := block.NumberU64()
+	job := &stratumJob{
+		id:       fmt.Sprintf("%08x", s.jobSeq),
+		number:   number,
+		epoch:    calcEpoch(number, calcEpochLength(number)),
+		sealhash: sealhash,
+		seedhash: common.HexToHash(work[1]),
+		target:   new(big.Int).Div(two256, block.Difficulty()),
+		nonces:   make(map[uint64]struct{}),
+	}
+	s.job = job
+	s.jobs[job.id] = job
+
+	// Drop the jobs which are too old to be accepted by the remote sealer.
+	for id, old := range s.jobs {
+		if old.number+staleThreshold <= number {
+			delete(s.jobs, id)
+		}
+	}
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// currentJob returns the most recent job, if any.
+func (s *stratumServer) currentJob() *stratumJob {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.job
+}
+
+// acceptLoop accepts incoming worker connections until the server is closed.
+func (s *stratumServer) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+			}
+			s.ethash.config.Log.Warn("Stratum accept failed", "err", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		sess, err := s.newSession(conn)
+		if err != nil {
+			s.ethash.config.Log.Warn("Rejected stratum connection", "remote", conn.RemoteAddr(), "err", err)
+			conn.Close()
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			sess.serve()
+		}()
+	}
+}
+
+// notifyLoop broadcasts new jobs to all the subscribed workers.
+func (s *stratumServer) notifyLoop() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.notifyCh:
+			job := s.currentJob()
+			if job == nil {
+				continue
+			}
+			s.lock.Lock()
+			sessions := make([]*stratumSession, 0, len(s.sessions))
+			for _, sess := range s.sessions {
+				sessions = append(sessions, sess)
+			}
+			s.lock.Unlock()
+
+			for _, sess := range sessions {
+				if err := sess.sendJob(job, true); err != nil {
+					s.ethash.config.Log.Debug("Failed to notify stratum worker", "remote", sess.conn.RemoteAddr(), "err", err)
+					sess.conn.Close()
+				}
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// newSession registers a new session for the given connection, assigning it
+// an unused extranonce.
+func (s *stratumServer) newSession(conn net.Conn) (*stratumSession, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.sessions) >= 1<<(8*stratumExtranonceBytes) {
+		return nil, errStratumSessionsFull
+	}
+	for {
+		if _, ok := s.sessions[s.nextNonce]; !ok {
+			break
+		}
+		s.nextNonce++
+	}
+	sess := &stratumSession{
+		server:       s,
+		conn:         conn,
+		extranonce:   s.nextNonce,
+		protocol:     stratumProtocolV1,
+		difficulty:   s.config.Difficulty,
+		lastRetarget: time.Now(),
+	}
+	s.sessions[sess.extranonce] = sess
+	s.nextNonce++
+	stratumSessionGauge.Update(int64(len(s.sessions)))
+	return sess, nil
+}
+
+// removeSession unregisters a disconnected session.
+func (s *stratumServer) removeSession(sess *stratumSession) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.sessions, sess.extranonce)
+	if name := sess.workerName(); name != "" {
+		if worker := s.workers[name]; worker != nil {
+			worker.Sessions--
+			worker.lastSeen = time.Now()
+		}
+	}
+	stratumSessionGauge.Update(int64(len(s.sessions)))
+}
+
+// authorize registers the session as belonging to the named worker. New workers
+// are rejected once the maximum number of workers is tracked.
+func (s *stratumServer) authorize(name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	worker := s.workers[name]
+	if worker == nil {
+		if len(s.workers) >= stratumMaxWorkers {
+			s.expireWorkers(now)
+		}
+		if len(s.workers) >= stratumMaxWorkers {
+			return errStratumWorkersFull
+		}
+		worker = &StratumWorker{Name: name}
+		s.workers[name] = worker
+	}
+	worker.Sessions++
+	worker.lastSeen = now
+	return nil
+}
+
+// expireWorkers drops the workers without sessions which have been idle for
+// longer than the expiry time. The caller must hold the lock.
+func (s *stratumServer) expireWorkers(now time.Time) {
+	for name, worker := range s.workers {
+		if worker.Sessions == 0 && now.Sub(worker.lastSeen) > stratumWorkerExpiry {
+			delete(s.workers, name)
+		}
+	}
+}
+
+// hashShare computes the digest and result of the given nonce for a job,
+// using the light verification cache.
+func (s *stratumServer) hashShare(job *stratumJob, nonce uint64) ([]byte, []byte) {
+	cache := s.ethash.cache(job.number)
+	size := datasetSize(job.epoch)
+	if s.ethash.config.PowMode == ModeTest {
+		size = 32 * 1024
+	}
+	digest, result := hashimotoLight(size, cache.cache, job.sealhash.Bytes(), nonce)
+
+	// Caches are unmapped in a finalizer. Ensure that the cache stays alive
+	// until after the call to hashimotoLight so it's not unmapped while being used.
+	runtime.KeepAlive(cache)
+	return digest, result
+}
+
+// submitShare verifies a share submitted by a worker against the session's
+// share target, forwarding it to the remote sealer if it also satisfies the
+// block target.
+func (s *stratumServer) submitShare(worker string, jobID string, nonce uint64, difficulty uint64) error {
+	s.lock.Lock()
+	job := s.jobs[jobID]
+	if job == nil {
+		s.account(worker, 0, false, errStratumJobNotFound)
+		s.lock.Unlock()
+		return errStratumJobNotFound
+	}
+	if _, ok := job.nonces[nonce]; ok {
+		s.account(worker, 0, false, errStratumDuplicate)
+		s.lock.Unlock()
+		return errStratumDuplicate
+	}
+	job.nonces[nonce] = struct{}{}
+	s.lock.Unlock()
+
+	digest, result := s.hashShare(job, nonce)
+	shareTarget := new(big.Int).Div(two256, new(big.Int).SetUint64(difficulty))
+
+	var err error
+	pow := new(big.Int).SetBytes(result)
+	if pow.Cmp(shareTarget) > 0 {
+		err = errStratumLowDifficulty
+	}
+	var block bool
+	if err == nil && pow.Cmp(job.target) <= 0 {
+		if s.submitBlock(job, nonce, digest) {
+			block = true
+			s.ethash.config.Log.Info("Stratum worker found block", "worker", worker, "number", job.number, "sealhash", job.sealhash)
+		} else {
+			s.ethash.config.Log.Warn("Stratum block solution rejected", "worker", worker, "number", job.number, "sealhash", job.sealhash)
+		}
+	}
+	s.lock.Lock()
+	s.account(worker, difficulty, block, err)
+	s.lock.Unlock()
+
+	return err
+}
+
+// submitBlock forwards a block solution to the remote sealer.
+func (s *stratumServer) submitBlock(job *stratumJob, nonce uint64, digest []byte) bool {
+	var errc = make(chan error, 1)
+	select {
+	case s.sealer.submitWorkCh <- &mineResult{
+		nonce:     types.EncodeNonce(nonce),
+		mixDigest: common.BytesToHash(digest),
+		hash:      job.sealhash,
+		errc:      errc,
+	}:
+	case <-s.quit:
+		return false
+	case <-s.sealer.exitCh:
+		return false
+	}
+	return <-errc == nil
+}
+
+// account updates the share statistics of a worker. The worker is only tracked
+// while authorized, so the shares of an unknown one only count towards the
+// totals. The caller must hold the lock.
+func (s *stratumServer) account(name string, difficulty uint64, block bool, err error) {
+	worker := s.workers[name]
+	if worker == nil {
+		worker = new(StratumWorker) // Discarded
+	}
+	now := time.Now()
+	worker.lastSeen = now
+	switch err {
+	case nil:
+		worker.Accepted++
+		worker.LastShare = now
+		worker.trimShares(now)
+		worker.shares = append(worker.shares, stratumShare{time: now, difficulty: difficulty})
+		s.accepted++
+		stratumAcceptedMeter.Mark(1)
+	case errStratumJobNotFound:
+		worker.Stale++
+		s.stale++
+		stratumStaleMeter.Mark(1)
+	default:
+		worker.Invalid++
+		s.invalid++
+		stratumInvalidMeter.Mark(1)
+	}
+	if block {
+		worker.Blocks++
+		s.blocks++
+		stratumBlockMeter.Mark(1)
+	}
+}
+
+// stats returns a snapshot of the share accounting of the server.
+func (s *stratumServer) stats() *StratumStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats := &StratumStats{
+		Addr:     s.listener.Addr().String(),
+		Sessions: len(s.sessions),
+		Accepted: s.accepted,
+		Stale:    s.stale,
+		Invalid:  s.invalid,
+		Blocks:   s.blocks,
+		Workers:  make([]*StratumWorker, 0, len(s.workers)),
+	}
+	now := time.Now()
+	s.expireWorkers(now)
+	for _, worker := range s.workers {
+		worker.trimShares(now)
+
+		var work uint64
+		for _, share := range worker.shares {
+			work += share.difficulty
+		}
+		worker.Hashrate = uint64(float64(work) / stratumHashrateWindow.Seconds())
+
+		cpy := *worker
+		cpy.shares = nil
+		stats.Workers = append(stats.Workers, &cpy)
+	}
+	sort.Slice(stats.Workers, func(i, j int) bool {
+		return stats.Workers[i].Name < stats.Workers[j].Name
+	})
+	return stats
+}
+
+// stratumRetarget calculates the share difficulty which brings the observed
+// share rate of a worker to the configured share time. The adjustment is
+// bounded to a factor of two per retarget, and to the configured range.
+func stratumRetarget(config *StratumConfig, difficulty uint64, shares int, elapsed time.Duration) uint64 {
+	factor := 0.5
+	if shares > 0 && elapsed > 0 {
+		factor = float64(config.ShareTime) * float64(shares) / float64(elapsed)
+	}
+	if factor < 0.5 {
+		factor = 0.5
+	}
+	if factor > 2 {
+		factor = 2
+	}
+	next := uint64(float64(difficulty) * factor)
+	if next < config.MinDifficulty {
+		next = config.MinDifficulty
+	}
+	if next > config.MaxDifficulty {
+		next = config.MaxDifficulty
+	}
+	if next == 0 {
+		next = 1
+	}
+	return next
+}
+
+// stratumSession is a single worker connection to the stratum server.
+type stratumSession struct {
+	server     *stratumServer
+	conn       net.Conn
+	extranonce uint16
+
+	writeLock sync.Mutex // Serializes writes to the connection
+
+	lock         sync.Mutex // Protects the session state below
+	protocol     string
+	subscribed   bool
+	worker       string
+	difficulty   uint64
+	epoch        *uint64 // Epoch last announced to an EthereumStratum/2.0.0 worker
+	shares       int     // Accepted shares since the last retarget
+	lastRetarget time.Time
+}
+
+// serve reads and handles requests until the connection is closed.
+func (sess *stratumSession) serve() {
+	defer func() {
+		sess.conn.Close()
+		sess.server.removeSession(sess)
+	}()
+	log := sess.server.ethash.config.Log
+	log.Debug("Stratum worker connected", "remote", sess.conn.RemoteAddr(), "extranonce", sess.extranonceHex())
+
+	scanner := bufio.NewScanner(sess.conn)
+	scanner.Buffer(make([]byte, 0, 1024), stratumMaxMessageSize)
+
+	var errs int
+	for {
+		sess.conn.SetReadDeadline(time.Now().Add(stratumReadTimeout))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				log.Debug("Stratum worker disconnected", "remote", sess.conn.RemoteAddr(), "err", err)
+			}
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var req stratumRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			log.Debug("Malformed stratum request", "remote", sess.conn.RemoteAddr(), "err", err)
+			return
+		}
+		result, err := sess.handle(&req)
+		if err != nil {
+			if errs++; errs > stratumMaxErrors {
+				log.Debug("Too many stratum errors, dropping worker", "remote", sess.conn.RemoteAddr())
+				return
+			}
+		} else {
+			errs = 0
+		}
+		if err := sess.reply(&req, result, err); err != nil {
+			return
+		}
+		// Once authorized, hand out the share target and the current job.
+		if err == nil && req.Method == "mining.authorize" {
+			if err := sess.sendTarget(); err != nil {
+				return
+			}
+			if job := sess.server.currentJob(); job != nil {
+				if err := sess.sendJob(job, true); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// handle dispatches a single request, returning its result.
+func (sess *stratumSession) handle(req *stratumRequest) (interface{}, error) {
+	switch req.Method {
+	case "mining.hello":
+		var hello struct {
+			Agent string `json:"agent"`
+			Proto string `json:"proto"`
+		}
+		if err := json.Unmarshal(req.Params, &hello); err != nil {
+			return nil, errStratumInvalidParams
+		}
+		if hello.Proto != stratumProtocolV2 {
+			return nil, errStratumInvalidParams
+		}
+		sess.lock.Lock()
+		sess.protocol = stratumProtocolV2
+		sess.lock.Unlock()
+
+		return map[string]interface{}{
+			"proto":     stratumProtocolV2,
+			"encoding":  "plain",
+			"resume":    "0",
+			"timeout":   fmt.Sprintf("%x", int(stratumReadTimeout.Seconds())),
+			"maxerrors": fmt.Sprintf("%x", stratumMaxErrors),
+			"node":      "ethashb3",
+		}, nil
+
+	case "mining.subscribe":
+		sess.lock.Lock()
+		defer sess.lock.Unlock()
+
+		sess.subscribed = true
+		if sess.protocol == stratumProtocolV2 {
+			return sess.extranonceHex(), nil
+		}
+		return []interface{}{
+			[]string{"mining.notify", sess.extranonceHex(), stratumProtocolV1},
+			sess.extranonceHex(),
+		}, nil
+
+	case "mining.extranonce.subscribe":
+		return true, nil
+
+	case "mining.authorize":
+		var params []string
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params) == 0 || params[0] == "" {
+			return nil, errStratumInvalidParams
+		}
+		sess.lock.Lock()
+		if !sess.subscribed {
+			sess.lock.Unlock()
+			return nil, errStratumNotSubscribed
+		}
+		if sess.worker != "" {
+			sess.lock.Unlock()
+			return true, nil
+		}
+		sess.lock.Unlock()
+
+		// Requests of a session are handled sequentially, so the worker can't
+		// be authorized concurrently.
+		if err := sess.server.authorize(params[0]); err != nil {
+			return nil, err
+		}
+		sess.lock.Lock()
+		sess.worker = params[0]
+		sess.lock.Unlock()
+		return true, nil
+
+	case "mining.submit":
+		var params []string
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params) < 3 {
+			return nil, errStratumInvalidParams
+		}
+		sess.lock.Lock()
+		worker, protocol, difficulty := sess.worker, sess.protocol, sess.difficulty
+		sess.lock.Unlock()
+		if worker == "" {
+			return nil, errStratumUnauthorized
+		}
+		// EthereumStratum/1.0.0: [worker, job, nonce], EthereumStratum/2.0.0: [job, nonce, worker]
+		jobID, nonceHex := params[1], params[2]
+		if protocol == stratumProtocolV2 {
+			jobID, nonceHex = params[0], params[1]
+		}
+		nonce, err := sess.parseNonce(nonceHex)
+		if err != nil {
+			return nil, errStratumInvalidParams
+		}
+		if err := sess.server.submitShare(worker, jobID, nonce, difficulty); err != nil {
+			return nil, err
+		}
+		sess.shareAccepted()
+		return true, nil
+
+	default:
+		return nil, errStratumNoMethod
+	}
+}
+
+// parseNonce assembles the full nonce from the session's extranonce and the
+// submitted nonce suffix. Workers submitting the full nonce are also accepted,
+// as long as it carries the session's extranonce.
+func (sess *stratumSession) parseNonce(input string) (uint64, error) {
+	input = strings.TrimPrefix(input, "0x")
+	prefix := sess.extranonceHex()
+	switch len(input) {
+	case 2 * (8 - stratumExtranonceBytes):
+		input = prefix + input
+	case 2 * 8:
+		if !strings.EqualFold(input[:len(prefix)], prefix) {
+			return 0, errors.New("extranonce mismatch")
+		}
+	default:
+		return 0, errors.New("invalid nonce length")
+	}
+	b, err := hex.DecodeString(input)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// shareAccepted counts an accepted share towards the session's share rate,
+// retargeting the share difficulty if enough shares were seen.
+func (sess *stratumSession) shareAccepted() {
+	if sess.server.config.ShareTime == 0 {
+		return
+	}
+	sess.lock.Lock()
+	sess.shares++
+	retarget := sess.shares >= stratumRetargetShares
+	sess.lock.Unlock()
+
+	if retarget {
+		sess.retarget()
+	}
+}
+
+// retarget recalculates the session's share difficulty from the observed share
+// rate, notifying the worker if it changed.
+func (sess *stratumSession) retarget() {
+	sess.lock.Lock()
+	now := time.Now()
+	next := stratumRetarget(&sess.server.config, sess.difficulty, sess.shares, now.Sub(sess.lastRetarget))
+	changed := next != sess.difficulty
+	sess.difficulty, sess.shares, sess.lastRetarget = next, 0, now
+	sess.lock.Unlock()
+
+	if changed {
+		if err := sess.sendTarget(); err != nil {
+			sess.conn.Close()
+		}
+	}
+}
+
+// workerName returns the name of the authorized worker, if any.
+func (sess *stratumSession) workerName() string {
+	sess.lock.Lock()
+	defer sess.lock.Unlock()
+
+	return sess.worker
+}
+
+// extranonceHex returns the hex encoded extranonce of the session.
+func (sess *stratumSession) extranonceHex() string {
+	return fmt.Sprintf("%0*x", 2*stratumExtranonceBytes, sess.extranonce)
+}
+
+// sendTarget notifies the worker of its current share difficulty.
+func (sess *stratumSession) sendTarget() error {
+	sess.lock.Lock()
+	protocol, difficulty := sess.protocol, sess.difficulty
+	sess.lock.Unlock()
+
+	if protocol == stratumProtocolV2 {
+		return sess.sendSet(sess.server.currentJob(), true)
+	}
+	return sess.notify("mining.set_difficulty", []float64{float64(difficulty) / stratumBaseDifficulty})
+}
+
+// sendSet sends an EthereumStratum/2.0.0 mining.set message, announcing the
+// epoch of the given job if it changed, and the share target if requested.
+func (sess *stratumSession) sendSet(job *stratumJob, withTarget bool) error {
+	sess.lock.Lock()
+	params := make(map[string]string)
+	if job != nil && (sess.epoch == nil || *sess.epoch != job.epoch) {
+		epoch := job.epoch
+		sess.epoch = &epoch
+		params["epoch"] = fmt.Sprintf("%x", epoch)
+		params["algo"] = "ethashb3"
+	}
+	if withTarget {
+		target := new(big.Int).Div(two256, new(big.Int).SetUint64(sess.difficulty))
+		params["target"] = hex.EncodeToString(common.BigToHash(target).Bytes())
+		params["extranonce"] = sess.extranonceHex()
+	}
+	sess.lock.Unlock()
+
+	if len(params) == 0 {
+		return nil
+	}
+	return sess.notify("mining.set", params)
+}
+
+// sendJob notifies an authorized worker of a new job.
+func (sess *stratumSession) sendJob(job *stratumJob, clean bool) error {
+	sess.lock.Lock()
+	protocol, authorized := sess.protocol, sess.worker != ""
+	idle := sess.server.config.ShareTime > 0 && time.Since(sess.lastRetarget) > stratumRetargetShares*sess.server.config.ShareTime
+	sess.lock.Unlock()
+
+	if !authorized {
+		return nil
+	}
+	// Lower the share difficulty of workers which haven't found enough
+	// shares within the expected time.
+	if idle {
+		sess.retarget()
+	}
+	if protocol == stratumProtocolV2 {
+		if err := sess.sendSet(job, false); err != nil {
+			return err
+		}
+		return sess.notify("mining.notify", []interface{}{
+			job.id,
+			fmt.Sprintf("%x", job.number),
+			hex.EncodeToString(job.sealhash.Bytes()),
+			clean,
+		})
+	}
+	return sess.notify("mining.notify", []interface{}{
+		job.id,
+		hex.EncodeToString(job.seedhash.Bytes()),
+		hex.EncodeToString(job.sealhash.Bytes()),
+		clean,
+	})
+}
+
+// notify sends a server initiated message to the worker.
+func (sess *stratumSession) notify(method string, params interface{}) error {
+	return sess.write(&stratumNotification{Method: method, Params: params})
+}
+
+// reply sends the response of a request to the worker.
+func (sess *stratumSession) reply(req *stratumRequest, result interface{}, err error) error {
+	res := &stratumResponse{ID: req.ID, Result: result}
+	if len(res.ID) == 0 {
+		res.ID = json.RawMessage("null")
+	}
+	if err != nil {
+		serr, ok := err.(*stratumError)
+		if !ok {
+			serr = errStratumUnknown
+		}
+		sess.lock.Lock()
+		protocol := sess.protocol
+		sess.lock.Unlock()
+
+		res.Result = false
+		if protocol == stratumProtocolV2 {
+			res.Error = map[string]interface{}{"code": serr.code, "message": serr.message}
+		} else {
+			res.Error = []interface{}{serr.code, serr.message, nil}
+		}
+	}
+	return sess.write(res)
+}
+
+// write encodes a single line-delimited JSON message to the connection.
+func (sess *stratumSession) write(msg interface{}) error {
+	blob, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	sess.writeLock.Lock()
+	defer sess.writeLock.Unlock()
+
+	sess.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+	_, err = sess.conn.Write(append(blob, '\n'))
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethashb3
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Tests that the variable share difficulty converges towards the configured
+// share time, within the per-retarget and absolute bounds.
+func TestStratumRetarget(t *testing.T) {
+	config := &StratumConfig{
+		MinDifficulty: 1000,
+		MaxDifficulty: 100000,
+		ShareTime:     10 * time.Second,
+	}
+	tests := []struct {
+		difficulty uint64
+		shares     int
+		elapsed    time.Duration
+		want       uint64
+	}{
+		{10000, 8, 80 * time.Second, 10000},  // on target
+		{10000, 8, 160 * time.Second, 5000},  // too slow
+		{10000, 8, 40 * time.Second, 20000},  // too fast
+		{10000, 8, 10 * time.Second, 20000},  // way too fast, bounded to 2x
+		{10000, 0, 600 * time.Second, 5000},  // idle, bounded to 0.5x
+		{1500, 0, 600 * time.Second, 1000},   // idle, bounded to minimum
+		{80000, 8, 10 * time.Second, 100000}, // bounded to maximum
+	}
+	for i, tt := range tests {
+		if have := stratumRetarget(config, tt.difficulty, tt.shares, tt.elapsed); have != tt.want {
+			t.Errorf("test %d: difficulty mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that submitted nonces are combined with the session's extranonce.
+func TestStratumNonce(t *testing.T) {
+	sess := &stratumSession{extranonce: 0xabcd}
+
+	tests := []struct {
+		input string
+		want  uint64
+		fail  bool
+	}{
+		{input: "0123456789ab", want: 0xabcd0123456789ab},
+		{input: "0x0123456789ab", want: 0xabcd0123456789ab},
+		{input: "abcd0123456789ab", want: 0xabcd0123456789ab},
+		{input: "ABCD0123456789AB", want: 0xabcd0123456789ab},
+		{input: "00000123456789ab", fail: true}, // foreign extranonce
+		{input: "0123456789", fail: true},       // too short
+		{input: "0123456789zz", fail: true},     // not hex
+	}
+	for i, tt := range tests {
+		have, err := sess.parseNonce(tt.input)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected error for %q", i, tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		} else if have != tt.want {
+			t.Errorf("test %d: nonce mismatch: have %#x, want %#x", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that the tracked workers are bounded, and that idle ones expire.
+func TestStratumWorkers(t *testing.T) {
+	s := &stratumServer{workers: make(map[string]*StratumWorker)}
+	for i := 0; i < stratumMaxWorkers; i++ {
+		if err := s.authorize(fmt.Sprintf("worker%d", i)); err != nil {
+			t.Fatalf("worker %d: authorization failed: %v", i, err)
+		}
+	}
+	if err := s.authorize("extra"); err != errStratumWorkersFull {
+		t.Fatalf("error mismatch: have %v, want %v", err, errStratumWorkersFull)
+	}
+	// Known workers may open more sessions.
+	if err := s.authorize("worker0"); err != nil {
+		t.Fatalf("known worker rejected: %v", err)
+	}
+	// A disconnected worker expires once idle, freeing its slot.
+	idle := s.workers["worker1"]
+	idle.Sessions = 0
+	idle.lastSeen = time.Now().Add(-stratumWorkerExpiry - time.Second)
+	if err := s.authorize("extra"); err != nil {
+		t.Fatalf("authorization failed after expiry: %v", err)
+	}
+	if _, ok := s.workers["worker1"]; ok {
+		t.Error("idle worker not expired")
+	}
+	// Shares of unknown workers only count towards the totals.
+	s.account("unknown", 1, false, nil)
+	if _, ok := s.workers["unknown"]; ok || s.accepted != 1 {
+		t.Errorf("unknown worker tracked: accepted %d", s.accepted)
+	}
+}
+
+// Tests that the shares of a worker are trimmed to the hashrate window when
+// accounting new ones.
+func TestStratumShareWindow(t *testing.T) {
+	s := &stratumServer{workers: make(map[string]*StratumWorker)}
+	if err := s.authorize("worker"); err != nil {
+		t.Fatal(err)
+	}
+	worker := s.workers["worker"]
+	old := time.Now().Add(-stratumHashrateWindow - time.Second)
+	for i := 0; i < 100; i++ {
+		worker.shares = append(worker.shares, stratumShare{time: old, difficulty: 1})
+	}
+	s.account("worker", 1, false, nil)
+	if len(worker.shares) != 1 {
+		t.Errorf("share count mismatch: have %d, want 1", len(worker.shares))
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethashb3
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	workerHashrateWindow = 10 * time.Minute // Window over which the reported hashrates are averaged
+	workerExpiry         = 24 * time.Hour   // Time after which a silent worker is forgotten
+	maxWorkers           = 1024             // Maximum number of hashrate IDs tracked individually
+)
+
+var (
+	remoteAcceptedMeter = metrics.NewRegisteredMeter("ethashb3/remote/accepted", nil)
+	remoteStaleMeter    = metrics.NewRegisteredMeter("ethashb3/remote/stale", nil)
+	remoteInvalidMeter  = metrics.NewRegisteredMeter("ethashb3/remote/invalid", nil)
+)
+
+// submitOutcome is the classification of a work submission of a remote miner.
+type submitOutcome int
+
+const (
+	submitAccepted submitOutcome = iota // Valid solution, handed to the miner
+	submitStale                         // Solution for unknown, outdated or unwanted work
+	submitInvalid                       // Solution failing the proof-of-work verification
+)
+
+// WorkerStats is the submission accounting of a single remote miner, identified
+// either by the hashrate ID it reports with, or by the coinbase of the work it
+// submits solutions for.
+type WorkerStats struct {
+	ID       *common.Hash    `json:"id,omitempty"`
+	Coinbase *common.Address `json:"coinbase,omitempty"`
+	Accepted uint64          `json:"accepted"`
+	Stale    uint64          `json:"stale"`
+	Invalid  uint64          `json:"invalid"`
+	Hashrate uint64          `json:"hashrate"` // Reported hashrate, averaged over the last 10 minutes
+	LastSeen time.Time       `json:"lastSeen"`
+}
+
+// WorkerReport is the submission accounting of all remote miners known to the
+// remote sealer. The hashrate IDs without an accepted solution yet, or beyond
+// the number of tracked ones, are also aggregated into Others.
+type WorkerReport struct {
+	Workers   []*WorkerStats `json:"workers"`
+	Coinbases []*WorkerStats `json:"coinbases"`
+	Others    *WorkerStats   `json:"others"`
+}
+
+// rateSample is a single hashrate report of a remote miner.
+type rateSample struct {
+	time time.Time
+	rate uint64
+}
+
+// workerEntry is the tracked state of a single hashrate ID or coinbase.
+type workerEntry struct {
+	stats    WorkerStats
+	samples  []rateSample    // Hashrate reports within the averaging window (IDs only)
+	coinbase *common.Address // Coinbase of the last submission (IDs only)
+
+	accepted metrics.Counter
+	stale    metrics.Counter
+	invalid  metrics.Counter
+	hashrate metrics.Gauge
+	prefix   string // Empty if the entry has no metrics of its own
+}
+
+func newWorkerEntry(prefix string) *workerEntry {
+	w := new(workerEntry)
+	w.register(prefix)
+	return w
+}
+
+// register creates the metrics of the entry.
+func (w *workerEntry) register(prefix string) {
+	w.accepted = metrics.GetOrRegisterCounter(prefix+"/accepted", nil)
+	w.stale = metrics.GetOrRegisterCounter(prefix+"/stale", nil)
+	w.invalid = metrics.GetOrRegisterCounter(prefix+"/invalid", nil)
+	w.hashrate = metrics.GetOrRegisterGauge(prefix+"/hashrate", nil)
+	w.prefix = prefix
+}
+
+// metered reports whether the entry has metrics of its own.
+func (w *workerEntry) metered() bool {
+	return w.prefix != ""
+}
+
+// account counts a single submission outcome, in the metrics too if the entry
+// has any.
+func (w *workerEntry) account(outcome submitOutcome, now time.Time) {
+	switch outcome {
+	case submitAccepted:
+		w.stats.Accepted++
+	case submitStale:
+		w.stats.Stale++
+	case submitInvalid:
+		w.stats.Invalid++
+	}
+	w.stats.LastSeen = now
+
+	if w.metered() {
+		switch outcome {
+		case submitAccepted:
+			w.accepted.Inc(1)
+		case submitStale:
+			w.stale.Inc(1)
+		case submitInvalid:
+			w.invalid.Inc(1)
+		}
+	}
+}
+
+// release unregisters the metrics of a forgotten worker.
+func (w *workerEntry) release() {
+	if !w.metered() {
+		return
+	}
+	for _, name := range []string{"/accepted", "/stale", "/invalid", "/hashrate"} {
+		metrics.Unregister(w.prefix + name)
+	}
+}
+
+// workerTracker maintains the per hashrate ID and per coinbase submission
+// accounting of the remote sealer. It is not thread safe, all accesses must
+// happen on the remote sealer's loop.
+//
+// Hashrate IDs are chosen by the remote miners, so only the ones which submitted
+// an accepted solution get metrics of their own, and at most maxWorkers of them
+// are tracked. The others are accounted in an aggregate entry.
+type workerTracker struct {
+	workers   map[common.Hash]*workerEntry
+	coinbases map[common.Address]*workerEntry
+	others    *workerEntry // Aggregate of the IDs without metrics of their own
+}
+
+func newWorkerTracker() *workerTracker {
+	return &workerTracker{
+		workers:   make(map[common.Hash]*workerEntry),
+		coinbases: make(map[common.Address]*workerEntry),
+		others:    newWorkerEntry("ethashb3/workers/others"),
+	}
+}
+
+// worker retrieves the entry of a hashrate ID, creating it if needed. It returns
+// nil if the ID is unknown and the maximum number of IDs is already tracked.
+func (t *workerTracker) worker(id common.Hash, now time.Time) *workerEntry {
+	w := t.workers[id]
+	if w == nil {
+		if len(t.workers) >= maxWorkers {
+			t.expire(now)
+		}
+		if len(t.workers) >= maxWorkers {
+			return nil
+		}
+		w = new(workerEntry)
+		w.stats.ID = &id
+		t.workers[id] = w
+	}
+	return w
+}
+
+// coinbase retrieves the entry of a coinbase, creating it if needed.
+func (t *workerTracker) coinbase(addr common.Address) *workerEntry {
+	w := t.coinbases[addr]
+	if w == nil {
+		w = newWorkerEntry("ethashb3/coinbases/" + addr.Hex())
+		w.stats.Coinbase = &addr
+		t.coinbases[addr] = w
+	}
+	return w
+}
+
+// submitted accounts a work submission. The id is nil if the remote miner did
+// not identify itself, the coinbase is nil if the submitted work is unknown.
+func (t *workerTracker) submitted(id *common.Hash, coinbase *common.Address, outcome submitOutcome) {
+	switch outcome {
+	case submitAccepted:
+		remoteAcceptedMeter.Mark(1)
+	case submitStale:
+		remoteStaleMeter.Mark(1)
+	case submitInvalid:
+		remoteInvalidMeter.Mark(1)
+	}
+	now := time.Now()
+	if id != nil {
+		w := t.worker(*id, now)
+		if w != nil {
+			if outcome == submitAccepted && !w.metered() {
+				w.register("ethashb3/workers/" + id.Hex())
+			}
+			w.account(outcome, now)
+			if coinbase != nil {
+				w.coinbase = coinbase
+			}
+		}
+		if w == nil || !w.metered() {
+			t.others.account(outcome, now)
+		}
+	}
+	if coinbase != nil {
+		t.coinbase(*coinbase).account(outcome, now)
+	}
+}
+
+// reported records a hashrate report of a remote miner. The reports of IDs beyond
+// the tracked ones are dropped.
+func (t *workerTracker) reported(id common.Hash, rate uint64) {
+	now := time.Now()
+	w := t.worker(id, now)
+	if w == nil {
+		return
+	}
+	w.samples = append(w.samples, rateSample{time: now, rate: rate})
+	w.stats.LastSeen = now
+}
+
+// expire forgets the workers and coinbases which were silent for too long.
+func (t *workerTracker) expire(now time.Time) {
+	for id, w := range t.workers {
+		if now.Sub(w.stats.LastSeen) > workerExpiry {
+			w.release()
+			delete(t.workers, id)
+		}
+	}
+	for addr, w := range t.coinbases {
+		if now.Sub(w.stats.LastSeen) > workerExpiry {
+			w.release()
+			delete(t.coinbases, addr)
+		}
+	}
+}
+
+// refresh drops the hashrate reports which fell out of the averaging window,
+// forgets the workers which were silent for too long and updates the rolling
+// hashrates. The hashrate of a coinbase is the sum of the hashrates of the IDs
+// which last submitted work for it.
+func (t *workerTracker) refresh() {
+	now := time.Now()
+	t.expire(now)
+
+	rates := make(map[common.Address]uint64)
+	var others uint64
+	for _, w := range t.workers {
+		var i int
+		for i < len(w.samples) && now.Sub(w.samples[i].time) > workerHashrateWindow {
+			i++
+		}
+		w.samples = w.samples[i:]
+
+		w.stats.Hashrate = 0
+		if len(w.samples) > 0 {
+			var total uint64
+			for _, sample := range w.samples {
+				total += sample.rate
+			}
+			w.stats.Hashrate = total / uint64(len(w.samples))
+		}
+		if w.metered() {
+			w.hashrate.Update(int64(w.stats.Hashrate))
+		} else {
+			others += w.stats.Hashrate
+		}
+		if w.coinbase != nil {
+			rates[*w.coinbase] += w.stats.Hashrate
+		}
+	}
+	for addr, w := range t.coinbases {
+		w.stats.Hashrate = rates[addr]
+		w.hashrate.Update(int64(w.stats.Hashrate))
+	}
+	t.others.stats.Hashrate = others
+	t.others.hashrate.Update(int64(others))
+}
+
+// report assembles the accounting of all known workers.
+func (t *workerTracker) report() *WorkerReport {
+	t.refresh()
+
+	others := t.others.stats
+	report := &WorkerReport{
+		Workers:   make([]*WorkerStats, 0, len(t.workers)),
+		Coinbases: make([]*WorkerStats, 0, len(t.coinbases)),
+		Others:    &others,
+	}
+	for _, w := range t.workers {
+		stats := w.stats
+		report.Workers = append(report.Workers, &stats)
+	}
+	for _, w := range t.coinbases {
+		stats := w.stats
+		report.Coinbases = append(report.Coinbases, &stats)
+	}
+	sort.Slice(report.Workers, func(i, j int) bool {
+		return bytes.Compare(report.Workers[i].ID[:], report.Workers[j].ID[:]) < 0
+	})
+	sort.Slice(report.Coinbases, func(i, j int) bool {
+		return bytes.Compare(report.Coinbases[i].Coinbase[:], report.Coinbases[j].Coinbase[:]) < 0
+	})
+	return report
+}
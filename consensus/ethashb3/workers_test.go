@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethashb3
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Tests that submissions and hashrate reports are accounted both per hashrate
+// ID and per coinbase.
+func TestWorkerTracker(t *testing.T) {
+	var (
+		tracker = newWorkerTracker()
+		rig1    = common.HexToHash("0x01")
+		rig2    = common.HexToHash("0x02")
+		pool    = common.HexToAddress("0xaa")
+	)
+	tracker.reported(rig1, 100)
+	tracker.reported(rig1, 300)
+	tracker.reported(rig2, 50)
+
+	tracker.submitted(&rig1, &pool, submitAccepted)
+	tracker.submitted(&rig1, &pool, submitStale)
+	tracker.submitted(&rig2, &pool, submitInvalid)
+	tracker.submitted(&rig2, nil, submitStale) // unknown work
+	tracker.submitted(nil, &pool, submitAccepted)
+
+	report := tracker.report()
+	if len(report.Workers) != 2 || len(report.Coinbases) != 1 {
+		t.Fatalf("worker count mismatch: have %d/%d, want 2/1", len(report.Workers), len(report.Coinbases))
+	}
+	check := func(name string, stats *WorkerStats, accepted, stale, invalid, hashrate uint64) {
+		t.Helper()
+		if stats.Accepted != accepted || stats.Stale != stale || stats.Invalid != invalid {
+			t.Errorf("%s: submissions mismatch: have %d/%d/%d, want %d/%d/%d", name,
+				stats.Accepted, stats.Stale, stats.Invalid, accepted, stale, invalid)
+		}
+		if stats.Hashrate != hashrate {
+			t.Errorf("%s: hashrate mismatch: have %d, want %d", name, stats.Hashrate, hashrate)
+		}
+		if stats.LastSeen.IsZero() {
+			t.Errorf("%s: last seen not set", name)
+		}
+	}
+	check("rig1", report.Workers[0], 1, 1, 0, 200)
+	check("rig2", report.Workers[1], 0, 1, 1, 50)
+	check("pool", report.Coinbases[0], 2, 1, 1, 250)
+	check("others", report.Others, 0, 1, 1, 50) // rig2 has no accepted solution
+
+	// Only the IDs with an accepted solution have metrics of their own.
+	if metrics.DefaultRegistry.Get("ethashb3/workers/"+rig1.Hex()+"/accepted") == nil {
+		t.Error("missing metrics of rig1")
+	}
+	if metrics.DefaultRegistry.Get("ethashb3/workers/"+rig2.Hex()+"/accepted") != nil {
+		t.Error("unexpected metrics of rig2")
+	}
+
+	// Silent workers should be forgotten after a while.
+	tracker.workers[rig1].stats.LastSeen = time.Now().Add(-2 * workerExpiry)
+	if report = tracker.report(); len(report.Workers) != 1 || *report.Workers[0].ID != rig2 {
+		t.Errorf("expired worker not dropped: %v", report.Workers)
+	}
+	if metrics.DefaultRegistry.Get("ethashb3/workers/"+rig1.Hex()+"/accepted") != nil {
+		t.Error("metrics of expired worker not unregistered")
+	}
+}
+
+// Tests that the number of tracked hashrate IDs is bounded, the submissions of
+// the others being aggregated.
+func TestWorkerTrackerLimit(t *testing.T) {
+	tracker := newWorkerTracker()
+	for i := 0; i < maxWorkers+10; i++ {
+		tracker.reported(common.BigToHash(big.NewInt(int64(i))), 100)
+	}
+	if len(tracker.workers) != maxWorkers {
+		t.Fatalf("tracked worker count mismatch: have %d, want %d", len(tracker.workers), maxWorkers)
+	}
+	extra := common.BigToHash(big.NewInt(maxWorkers))
+	tracker.submitted(&extra, nil, submitAccepted)
+	if _, ok := tracker.workers[extra]; ok {
+		t.Error("worker beyond the limit tracked")
+	}
+	if tracker.others.stats.Accepted != 1 {
+		t.Errorf("aggregate accepted count mismatch: have %d, want 1", tracker.others.stats.Accepted)
+	}
+	// Expired workers free their slots.
+	tracker.workers[common.Hash{}].stats.LastSeen = time.Now().Add(-2 * workerExpiry)
+	tracker.reported(extra, 100)
+	if _, ok := tracker.workers[extra]; !ok {
+		t.Error("worker not tracked after expiry")
+	}
+}
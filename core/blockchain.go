@@ -145,6 +145,7 @@ type CacheConfig struct {
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 	StateHistory        uint64        // Number of blocks from head whose state histories are reserved.
 	StateScheme         string        // Scheme used to store ethereum states and merkle tree nodes on top
+	SupplyIndex         bool          // Whether to index the total issued supply of the canonical blocks
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
@@ -476,6 +477,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *genesis
 		bc.wg.Add(1)
 		go bc.maintainTxIndex()
 	}
+	// Start the issued supply indexer if requested.
+	if bc.cacheConfig.SupplyIndex {
+		bc.wg.Add(1)
+		go bc.maintainSupplyIndex()
+	}
+
 	return bc, nil
 }
 
@@ -1413,6 +1420,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	bc.writeIssuedSupply(blockBatch, block)
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
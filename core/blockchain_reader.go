@@ -276,6 +276,12 @@ func (bc *BlockChain) GetTd(hash common.Hash, number uint64) *big.Int {
 	return bc.hc.GetTd(hash, number)
 }
 
+// GetIssuedSupply retrieves the total supply issued up to and including the
+// block, or nil if it's not indexed (yet).
+func (bc *BlockChain) GetIssuedSupply(hash common.Hash, number uint64) *big.Int {
+	return rawdb.ReadIssuedSupply(bc.db, hash, number)
+}
+
 // HasState checks if state trie is fully present in the database or not.
 func (bc *BlockChain) HasState(hash common.Hash) bool {
 	_, err := bc.stateCache.OpenTrie(hash)
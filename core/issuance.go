@@ -0,0 +1,272 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params/mutations"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+// BlockRewards returns the rewards credited by the consensus engine when
+// finalizing the given block. Only proof-of-work blocks of the ethash engines
+// are rewarded, the result is nil for all others.
+func BlockRewards(config ctypes.ChainConfigurator, header *types.Header, uncles []*types.Header, txs []*types.Transaction) []mutations.Reward {
+	engine := config.GetConsensusEngineType()
+	if !engine.IsEthash() && !engine.IsEthashB3() {
+		return nil
+	}
+	if header.Difficulty == nil || header.Difficulty.Sign() == 0 {
+		return nil // proof-of-stake block
+	}
+	return mutations.RewardPolicyOf(config, header.Number).Rewards(config, header, uncles, txs)
+}
+
+// blockIssuance returns the amount of new coins issued by the given block.
+func blockIssuance(config ctypes.ChainConfigurator, block *types.Block) *big.Int {
+	issuance := new(big.Int)
+	for _, reward := range BlockRewards(config, block.Header(), block.Uncles(), block.Transactions()) {
+		issuance.Add(issuance, reward.Amount)
+	}
+	return issuance
+}
+
+// genesisSupply returns the amount of coins allocated by the genesis state, or
+// zero if the genesis specification was not persisted.
+func genesisSupply(db ethdb.KeyValueReader, hash common.Hash) *big.Int {
+	supply := new(big.Int)
+
+	blob := rawdb.ReadGenesisStateSpec(db, hash)
+	if len(blob) == 0 {
+		log.Warn("Genesis allocation missing, assuming zero supply")
+		return supply
+	}
+	var alloc genesisT.GenesisAlloc
+	if err := alloc.UnmarshalJSON(blob); err != nil {
+		log.Error("Invalid genesis allocation, assuming zero supply", "err", err)
+		return supply
+	}
+	for _, account := range alloc {
+		if account.Balance != nil {
+			supply.Add(supply, account.Balance)
+		}
+	}
+	return supply
+}
+
+// writeIssuedSupply stores the issued supply of a new block, if the index is
+// enabled and the supply of its parent is known. Otherwise the block is left to
+// the supply indexer.
+func (bc *BlockChain) writeIssuedSupply(db ethdb.KeyValueWriter, block *types.Block) {
+	if !bc.cacheConfig.SupplyIndex {
+		return
+	}
+	parent := rawdb.ReadIssuedSupply(bc.db, block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return
+	}
+	supply := new(big.Int).Add(parent, blockIssuance(bc.chainConfig, block))
+	rawdb.WriteIssuedSupply(db, block.Hash(), block.NumberU64(), supply)
+}
+
+// maintainSupplyIndex is responsible for the construction of the issued supply
+// index of the canonical chain. New blocks are indexed as they are written if
+// their parent is, the indexer fills in all blocks missing from the index, eg.
+// the ones synced before the index existed or imported without execution.
+//
+// An indexing run which can't progress, eg. because the blocks are still being
+// synced, is retried after a delay instead of on every new head. The indexer
+// stops for good if the history it needs has been pruned.
+func (bc *BlockChain) maintainSupplyIndex() {
+	defer bc.wg.Done()
+
+	var (
+		done    chan error                     // Non-nil if background indexing routine is active.
+		retry   <-chan time.Time               // Non-nil if waiting to retry an aborted run.
+		pending bool                           // Whether the head moved since the last run started.
+		headCh  = make(chan ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
+	)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	if sub == nil {
+		return
+	}
+	defer sub.Unsubscribe()
+
+	run := func() {
+		pending = false
+		if head := bc.CurrentBlock(); head != nil {
+			done = make(chan error, 1)
+			go func() { done <- bc.indexSupply(head.Number.Uint64()) }()
+		}
+	}
+	run()
+	for {
+		select {
+		case <-headCh:
+			if done == nil && retry == nil {
+				run()
+			} else {
+				pending = true
+			}
+		case err := <-done:
+			done = nil
+			switch {
+			case errors.Is(err, errSupplyHistoryPruned):
+				log.Warn("Chain history pruned, issued supply can't be indexed", "err", err)
+				return
+			case err != nil:
+				log.Info("Issued supply indexing aborted, retrying later", "err", err, "delay", supplyIndexRetryDelay)
+				retry = time.After(supplyIndexRetryDelay)
+			case pending:
+				run()
+			}
+		case <-retry:
+			retry = nil
+			run()
+		case <-bc.quit:
+			if done != nil {
+				log.Info("Waiting background supply indexer to exit")
+				<-done
+			}
+			return
+		}
+	}
+}
+
+const (
+	// supplyIndexRetryDelay is the time to wait before retrying an aborted supply
+	// indexing run.
+	supplyIndexRetryDelay = time.Minute
+)
+
+var (
+	// errSupplyHistoryPruned is returned if the blocks to index the supply of
+	// have been pruned.
+	errSupplyHistoryPruned = errors.New("history pruned")
+
+	// errSupplyIndexInterrupted is returned if the supply indexing was stopped by
+	// the chain shutting down.
+	errSupplyIndexInterrupted = errors.New("interrupted")
+)
+
+// indexSupply indexes the issued supply of the canonical chain up to the given
+// head, resuming from the latest indexed block. The progress is persisted along
+// with each batch of the index, so an interrupted run resumes where it stopped.
+func (bc *BlockChain) indexSupply(head uint64) error {
+	// Find the latest indexed canonical block, rewinding past the blocks reorged
+	// out since the last run.
+	var (
+		number uint64
+		hash   common.Hash
+		supply *big.Int
+	)
+	if progress := rawdb.ReadIssuedSupplyIndexHead(bc.db); progress != nil {
+		number = *progress
+		if number > head {
+			number = head
+		}
+	}
+	for {
+		hash = rawdb.ReadCanonicalHash(bc.db, number)
+		if supply = rawdb.ReadIssuedSupply(bc.db, hash, number); supply != nil {
+			break
+		}
+		if number == 0 {
+			if hash == (common.Hash{}) {
+				return errors.New("missing genesis block")
+			}
+			supply = genesisSupply(bc.db, hash)
+			rawdb.WriteIssuedSupply(bc.db, hash, 0, supply)
+			break
+		}
+		number--
+	}
+	if number >= head {
+		return nil
+	}
+	// The supply accumulates from the genesis, it can't be indexed past a gap
+	if tail, err := bc.db.Tail(); err == nil && number+1 < tail {
+		return fmt.Errorf("%w: first block %d, first available %d", errSupplyHistoryPruned, number+1, tail)
+	}
+	// Accumulate the supply forward from the indexed block
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		from   = number + 1
+		batch  = bc.db.NewBatch()
+	)
+	flush := func() {
+		rawdb.WriteIssuedSupplyIndexHead(batch, number)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed writing supply index", "err", err)
+		}
+		batch.Reset()
+	}
+	if head-number > 1 {
+		log.Info("Indexing issued supply", "from", from, "to", head)
+	}
+	for number < head {
+		parent := hash
+		hash = rawdb.ReadCanonicalHash(bc.db, number+1)
+
+		// Blocks written since the index existed have their supply already
+		if indexed := rawdb.ReadIssuedSupply(bc.db, hash, number+1); indexed != nil {
+			number, supply = number+1, indexed
+			continue
+		}
+		block := rawdb.ReadBlock(bc.db, hash, number+1)
+		if block == nil {
+			flush()
+			return fmt.Errorf("missing block %d", number+1)
+		}
+		if block.ParentHash() != parent {
+			flush()
+			return fmt.Errorf("canonical chain reorged at block %d", number+1)
+		}
+		number++
+		supply = new(big.Int).Add(supply, blockIssuance(bc.chainConfig, block))
+		rawdb.WriteIssuedSupply(batch, hash, number, supply)
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			flush()
+			select {
+			case <-bc.quit:
+				return errSupplyIndexInterrupted
+			default:
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing issued supply", "number", number, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	flush()
+	if number-from > 0 {
+		log.Info("Indexed issued supply", "from", from, "to", number, "supply", supply, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+// Tests that the issued supply is indexed as blocks are imported, and that the
+// supply indexer fills in blocks missing from the index.
+func TestIssuedSupplyIndex(t *testing.T) {
+	var (
+		coinbase = common.HexToAddress("0xc0ffee")
+		gspec    = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: genesisT.GenesisAlloc{
+				common.HexToAddress("0x01"): {Balance: big.NewInt(1000000000000000)},
+				common.HexToAddress("0x02"): {Balance: big.NewInt(2000000000000000)},
+			},
+		}
+		allocated = big.NewInt(3000000000000000)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(coinbase)
+	})
+	db := rawdb.NewMemoryDatabase()
+	config := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	config.SupplyIndex = true
+	chain, err := NewBlockChain(db, config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// The coinbase holds all the coins issued after the genesis block
+	state, _ := chain.State()
+	want := new(big.Int).Add(allocated, state.GetBalance(coinbase))
+
+	check := func() {
+		t.Helper()
+		if supply := chain.GetIssuedSupply(chain.Genesis().Hash(), 0); supply == nil || supply.Cmp(allocated) != 0 {
+			t.Errorf("genesis supply mismatch: have %v, want %v", supply, allocated)
+		}
+		head := chain.CurrentBlock()
+		if supply := chain.GetIssuedSupply(head.Hash(), head.Number.Uint64()); supply == nil || supply.Cmp(want) != 0 {
+			t.Errorf("head supply mismatch: have %v, want %v", supply, want)
+		}
+	}
+	check()
+
+	// Stop the background indexer so it doesn't race with the runs below
+	chain.stopWithoutSaving()
+
+	// Drop part of the index and ensure the indexer restores it, resuming from
+	// its persisted progress
+	for _, block := range blocks[3:] {
+		rawdb.DeleteIssuedSupply(db, block.Hash(), block.NumberU64())
+	}
+	rawdb.WriteIssuedSupplyIndexHead(db, 3)
+	if err := chain.indexSupply(chain.CurrentBlock().Number.Uint64()); err != nil {
+		t.Fatalf("failed to index supply: %v", err)
+	}
+	check()
+	if head := rawdb.ReadIssuedSupplyIndexHead(db); head == nil || *head != 10 {
+		t.Errorf("index head mismatch: have %v, want 10", head)
+	}
+	// A missing block aborts the run, keeping the progress made so far
+	for _, block := range blocks[3:] {
+		rawdb.DeleteIssuedSupply(db, block.Hash(), block.NumberU64())
+	}
+	rawdb.WriteIssuedSupplyIndexHead(db, 3)
+	rawdb.DeleteBody(db, blocks[6].Hash(), blocks[6].NumberU64())
+	if err := chain.indexSupply(chain.CurrentBlock().Number.Uint64()); err == nil {
+		t.Fatal("indexing succeeded despite the missing block")
+	}
+	if head := rawdb.ReadIssuedSupplyIndexHead(db); head == nil || *head != 6 {
+		t.Errorf("index head mismatch: have %v, want 6", head)
+	}
+	if supply := chain.GetIssuedSupply(blocks[5].Hash(), blocks[5].NumberU64()); supply == nil {
+		t.Error("supply before the missing block not indexed")
+	}
+}
+
+// Tests that the issued supply isn't indexed unless enabled.
+func TestIssuedSupplyIndexDisabled(t *testing.T) {
+	gspec := &genesisT.Genesis{Config: params.TestChainConfig}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, nil)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for _, block := range blocks {
+		if supply := chain.GetIssuedSupply(block.Hash(), block.NumberU64()); supply != nil {
+			t.Errorf("block %d: unexpected supply %v", block.NumberU64(), supply)
+		}
+	}
+}
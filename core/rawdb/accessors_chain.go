@@ -278,6 +278,25 @@ func WriteTxIndexTail(db ethdb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadIssuedSupplyIndexHead retrieves the number of the latest canonical block
+// whose issued supply has been indexed.
+func ReadIssuedSupplyIndexHead(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(issuedSupplyIndexHeadKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteIssuedSupplyIndexHead stores the number of the latest canonical block
+// whose issued supply has been indexed into database.
+func WriteIssuedSupplyIndexHead(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(issuedSupplyIndexHeadKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the issued supply index head", "err", err)
+	}
+}
+
 // ReadFastTxLookupLimit retrieves the tx lookup limit used in fast sync.
 func ReadFastTxLookupLimit(db ethdb.KeyValueReader) *uint64 {
 	data, _ := db.Get(fastTxLookupLimitKey)
@@ -574,6 +593,40 @@ func DeleteTd(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadIssuedSupply retrieves the total supply issued up to and including the
+// block, or nil if it's not indexed.
+func ReadIssuedSupply(db ethdb.KeyValueReader, hash common.Hash, number uint64) *big.Int {
+	data, _ := db.Get(issuedSupplyKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	supply := new(big.Int)
+	if err := rlp.DecodeBytes(data, supply); err != nil {
+		log.Error("Invalid block issued supply RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return supply
+}
+
+// WriteIssuedSupply stores the total supply issued up to and including the
+// block into the database.
+func WriteIssuedSupply(db ethdb.KeyValueWriter, hash common.Hash, number uint64, supply *big.Int) {
+	data, err := rlp.EncodeToBytes(supply)
+	if err != nil {
+		log.Crit("Failed to RLP encode block issued supply", "err", err)
+	}
+	if err := db.Put(issuedSupplyKey(number, hash), data); err != nil {
+		log.Crit("Failed to store block issued supply", "err", err)
+	}
+}
+
+// DeleteIssuedSupply removes the issued supply associated with a hash.
+func DeleteIssuedSupply(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(issuedSupplyKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block issued supply", "err", err)
+	}
+}
+
 // HasReceipts verifies the existence of all the transaction receipts belonging
 // to a block.
 func HasReceipts(db ethdb.Reader, hash common.Hash, number uint64) bool {
@@ -835,6 +888,7 @@ func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
+	DeleteIssuedSupply(db, hash, number)
 }
 
 // DeleteBlockWithoutNumber removes all block data associated with a hash, except
@@ -844,6 +898,7 @@ func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number
 	deleteHeaderWithoutNumber(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
+	DeleteIssuedSupply(db, hash, number)
 }
 
 const badBlockToKeep = 10
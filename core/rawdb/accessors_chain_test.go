@@ -281,6 +281,28 @@ func TestTdStorage(t *testing.T) {
 	}
 }
 
+// Tests block issued supply storage and retrieval operations.
+func TestIssuedSupplyStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	hash, supply := common.Hash{0: 0xff}, new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil)
+	if entry := ReadIssuedSupply(db, hash, 314); entry != nil {
+		t.Fatalf("Non existent supply returned: %v", entry)
+	}
+	// Write and verify the supply in the database
+	WriteIssuedSupply(db, hash, 314, supply)
+	if entry := ReadIssuedSupply(db, hash, 314); entry == nil {
+		t.Fatalf("Stored supply not found")
+	} else if entry.Cmp(supply) != 0 {
+		t.Fatalf("Retrieved supply mismatch: have %v, want %v", entry, supply)
+	}
+	// Delete the supply and verify the execution
+	DeleteIssuedSupply(db, hash, 314)
+	if entry := ReadIssuedSupply(db, hash, 314); entry != nil {
+		t.Fatalf("Deleted supply returned: %v", entry)
+	}
+}
+
 // Tests that canonical numbers can be mapped to hashes and retrieved.
 func TestCanonicalMappingStorage(t *testing.T) {
 	db := NewMemoryDatabase()
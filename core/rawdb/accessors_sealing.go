@@ -0,0 +1,61 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadSealingStats retrieves the sealing statistics of the miner for the given
+// epoch.
+func ReadSealingStats(db ethdb.KeyValueReader, epoch uint64) []byte {
+	data, _ := db.Get(sealingStatsKey(epoch))
+	return data
+}
+
+// WriteSealingStats stores the sealing statistics of the miner for the given
+// epoch.
+func WriteSealingStats(db ethdb.KeyValueWriter, epoch uint64, stats []byte) {
+	if err := db.Put(sealingStatsKey(epoch), stats); err != nil {
+		log.Crit("Failed to store sealing statistics", "err", err)
+	}
+}
+
+// ReadPayoutState retrieves the owed payouts of the income split of the given
+// etherbase.
+func ReadPayoutState(db ethdb.KeyValueReader, etherbase common.Address) []byte {
+	data, _ := db.Get(payoutStateKey(etherbase))
+	return data
+}
+
+// WritePayoutState stores the owed payouts of the income split of the given
+// etherbase.
+func WritePayoutState(db ethdb.KeyValueWriter, etherbase common.Address, state []byte) {
+	if err := db.Put(payoutStateKey(etherbase), state); err != nil {
+		log.Crit("Failed to store payout state", "err", err)
+	}
+}
+
+// DeletePayoutState removes the owed payouts of the income split of the given
+// etherbase.
+func DeletePayoutState(db ethdb.KeyValueWriter, etherbase common.Address) {
+	if err := db.Delete(payoutStateKey(etherbase)); err != nil {
+		log.Crit("Failed to delete payout state", "err", err)
+	}
+}
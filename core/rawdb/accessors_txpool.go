@@ -0,0 +1,84 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadTxPoolEntries retrieves all transactions persisted by the transaction pool.
+func ReadTxPoolEntries(db ethdb.Iteratee) [][]byte {
+	it := db.NewIterator(txPoolEntryPrefix, nil)
+	defer it.Release()
+
+	var entries [][]byte
+	for it.Next() {
+		if len(it.Key()) != len(txPoolEntryPrefix)+common.HashLength {
+			continue
+		}
+		entries = append(entries, common.CopyBytes(it.Value()))
+	}
+	return entries
+}
+
+// WriteTxPoolEntry stores a transaction persisted by the transaction pool.
+func WriteTxPoolEntry(db ethdb.KeyValueWriter, hash common.Hash, entry []byte) {
+	if err := db.Put(txPoolEntryKey(hash), entry); err != nil {
+		log.Crit("Failed to store transaction pool entry", "err", err)
+	}
+}
+
+// DeleteTxPoolEntry removes a transaction persisted by the transaction pool.
+func DeleteTxPoolEntry(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(txPoolEntryKey(hash)); err != nil {
+		log.Crit("Failed to delete transaction pool entry", "err", err)
+	}
+}
+
+// ReadTxPoolHistory retrieves all records of transactions removed from the
+// transaction pool, ordered by removal time.
+func ReadTxPoolHistory(db ethdb.Iteratee) [][]byte {
+	it := db.NewIterator(txPoolHistoryPrefix, nil)
+	defer it.Release()
+
+	var entries [][]byte
+	for it.Next() {
+		if len(it.Key()) != len(txPoolHistoryPrefix)+8+common.HashLength {
+			continue
+		}
+		entries = append(entries, common.CopyBytes(it.Value()))
+	}
+	return entries
+}
+
+// WriteTxPoolHistory stores the record of a transaction removed from the
+// transaction pool at the given unix time.
+func WriteTxPoolHistory(db ethdb.KeyValueWriter, removed uint64, hash common.Hash, entry []byte) {
+	if err := db.Put(txPoolHistoryKey(removed, hash), entry); err != nil {
+		log.Crit("Failed to store transaction pool history", "err", err)
+	}
+}
+
+// DeleteTxPoolHistory removes the record of a transaction removed from the
+// transaction pool at the given unix time.
+func DeleteTxPoolHistory(db ethdb.KeyValueWriter, removed uint64, hash common.Hash) {
+	if err := db.Delete(txPoolHistoryKey(removed, hash)); err != nil {
+		log.Crit("Failed to delete transaction pool history", "err", err)
+	}
+}
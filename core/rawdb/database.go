@@ -479,6 +479,11 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		bloomBits       stat
 		beaconHeaders   stat
 		cliqueSnaps     stat
+		issuedSupplies  stat
+		txPoolEntries   stat
+		txPoolHistory   stat
+		sealingStats    stat
+		payoutStates    stat
 
 		// Les statistic
 		chtTrieNodes   stat
@@ -541,6 +546,16 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			beaconHeaders.Add(size)
 		case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
 			cliqueSnaps.Add(size)
+		case bytes.HasPrefix(key, issuedSupplyPrefix) && len(key) == (len(issuedSupplyPrefix)+8+common.HashLength):
+			issuedSupplies.Add(size)
+		case bytes.HasPrefix(key, txPoolEntryPrefix) && len(key) == (len(txPoolEntryPrefix)+common.HashLength):
+			txPoolEntries.Add(size)
+		case bytes.HasPrefix(key, txPoolHistoryPrefix) && len(key) == (len(txPoolHistoryPrefix)+8+common.HashLength):
+			txPoolHistory.Add(size)
+		case bytes.HasPrefix(key, sealingStatsPrefix) && len(key) == (len(sealingStatsPrefix)+8):
+			sealingStats.Add(size)
+		case bytes.HasPrefix(key, payoutStatePrefix) && len(key) == (len(payoutStatePrefix)+common.AddressLength):
+			payoutStates.Add(size)
 		case bytes.HasPrefix(key, ChtTablePrefix) ||
 			bytes.HasPrefix(key, ChtIndexTablePrefix) ||
 			bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
@@ -554,7 +569,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			for _, meta := range [][]byte{
 				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, headFinalizedBlockKey,
 				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
-				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
+				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey, issuedSupplyIndexHeadKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
 				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
 			} {
@@ -594,6 +609,11 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Beacon sync headers", beaconHeaders.Size(), beaconHeaders.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
+		{"Key-Value store", "Issued supply index", issuedSupplies.Size(), issuedSupplies.Count()},
+		{"Key-Value store", "Transaction pool", txPoolEntries.Size(), txPoolEntries.Count()},
+		{"Key-Value store", "Transaction pool history", txPoolHistory.Size(), txPoolHistory.Count()},
+		{"Key-Value store", "Sealing statistics", sealingStats.Size(), sealingStats.Count()},
+		{"Key-Value store", "Payout split debts", payoutStates.Size(), payoutStates.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
 		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
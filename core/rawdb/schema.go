@@ -79,6 +79,9 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// issuedSupplyIndexHeadKey tracks the latest canonical block whose issued supply has been indexed.
+	issuedSupplyIndexHeadKey = []byte("IssuedSupplyIndexHead")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
@@ -132,6 +135,14 @@ var (
 
 	CliqueSnapshotPrefix = []byte("clique-")
 
+	issuedSupplyPrefix = []byte("issued-supply-") // issuedSupplyPrefix + num (uint64 big endian) + hash -> cumulative issued supply
+
+	txPoolEntryPrefix   = []byte("txpool-tx-")      // txPoolEntryPrefix + hash -> persisted pool transaction
+	txPoolHistoryPrefix = []byte("txpool-history-") // txPoolHistoryPrefix + removal time (uint64 big endian) + hash -> pool removal record
+
+	sealingStatsPrefix = []byte("sealing-stats-") // sealingStatsPrefix + epoch (uint64 big endian) -> sealing statistics
+	payoutStatePrefix  = []byte("payout-state-")  // payoutStatePrefix + etherbase -> owed payouts of the etherbase income split
+
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 )
@@ -186,6 +197,31 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// issuedSupplyKey = issuedSupplyPrefix + num (uint64 big endian) + hash
+func issuedSupplyKey(number uint64, hash common.Hash) []byte {
+	return append(append(issuedSupplyPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// txPoolEntryKey = txPoolEntryPrefix + hash
+func txPoolEntryKey(hash common.Hash) []byte {
+	return append(append([]byte{}, txPoolEntryPrefix...), hash.Bytes()...)
+}
+
+// txPoolHistoryKey = txPoolHistoryPrefix + removal time (uint64 big endian) + hash
+func txPoolHistoryKey(removed uint64, hash common.Hash) []byte {
+	return append(append(append([]byte{}, txPoolHistoryPrefix...), encodeBlockNumber(removed)...), hash.Bytes()...)
+}
+
+// sealingStatsKey = sealingStatsPrefix + epoch (uint64 big endian)
+func sealingStatsKey(epoch uint64) []byte {
+	return append(append([]byte{}, sealingStatsPrefix...), encodeBlockNumber(epoch)...)
+}
+
+// payoutStateKey = payoutStatePrefix + etherbase
+func payoutStateKey(etherbase common.Address) []byte {
+	return append(append([]byte{}, payoutStatePrefix...), etherbase.Bytes()...)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -0,0 +1,78 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DropReason is the reason a transaction was removed from the pool, or moved
+// within it.
+type DropReason string
+
+const (
+	DropIncluded    DropReason = "included"    // Included in a block of the canonical chain
+	DropStale       DropReason = "stale"       // Nonce already used by another transaction
+	DropReplaced    DropReason = "replaced"    // Replaced by a transaction with the same nonce paying more
+	DropUnderpriced DropReason = "underpriced" // Evicted by better paying transactions from a full pool
+	DropUnpayable   DropReason = "unpayable"   // Sender can't afford it anymore, or over the block gas limit
+	DropOverflow    DropReason = "overflow"    // Evicted to keep the sender or the pool within the slot limits
+	DropLifetime    DropReason = "lifetime"    // Queued for longer than the pool lifetime
+	DropTipTooLow   DropReason = "tiptoolow"   // Below a raised minimum gas tip
+	DropInvalid     DropReason = "invalid"     // Rejected when restored after a restart
+	DropNonceGap    DropReason = "noncegap"    // Demoted after a lower nonce transaction of the sender was dropped
+)
+
+// HistoryEntry records the removal of a transaction from the pool.
+type HistoryEntry struct {
+	Hash       common.Hash
+	From       common.Address
+	Nonce      uint64
+	Local      bool
+	Added      uint64      // Unix time the transaction was first seen
+	Removed    uint64      // Unix time the transaction was removed from the pool
+	Reason     DropReason  // Why the transaction was removed
+	ReplacedBy common.Hash // Replacement transaction, if replaced
+	Error      string      // Validation error, if invalid
+}
+
+// HistoryReader is implemented by subpools recording the transactions they drop.
+type HistoryReader interface {
+	// History returns the most recent removals, newest first, optionally filtered
+	// by transaction hash or sender. Non-positive limits return all entries.
+	History(hash *common.Hash, from *common.Address, limit int) []*HistoryEntry
+}
+
+// History returns the most recent transaction removals of all subpools keeping
+// a history, newest first, optionally filtered by transaction hash or sender.
+func (p *TxPool) History(hash *common.Hash, from *common.Address, limit int) []*HistoryEntry {
+	var entries []*HistoryEntry
+	for _, subpool := range p.subpools {
+		if reader, ok := subpool.(HistoryReader); ok {
+			entries = append(entries, reader.History(hash, from, limit)...)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Removed > entries[j].Removed
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
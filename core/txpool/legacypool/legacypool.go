@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -51,17 +52,30 @@ const (
 	// more expensive to propagate; larger transactions also take more resources
 	// to validate whether they fit into the pool or not.
 	txMaxSize = 4 * txSlotSize // 128KB
+
+	// maxIncludedDepth is the maximum number of blocks searched to tell whether
+	// the transactions removed by a pool reset were included.
+	maxIncludedDepth = 64
 )
 
 var (
 	// ErrTxPoolOverflow is returned if the transaction pool is full and can't accept
 	// another remote transaction.
 	ErrTxPoolOverflow = errors.New("txpool is full")
+
+	// ErrOriginRateLimited is returned if transactions are received from an origin
+	// faster than its admission rate.
+	ErrOriginRateLimited = errors.New("origin rate limited")
+
+	// ErrOriginOverflow is returned if an origin already has the maximum number
+	// of transactions pooled.
+	ErrOriginOverflow = errors.New("origin slots exceeded")
 )
 
 var (
 	evictionInterval    = time.Minute     // Time interval to check for evictable transactions
 	statsReportInterval = 8 * time.Second // Time interval to report transaction pool stats
+	storeFlushInterval  = 5 * time.Second // Time interval to persist the batched transaction store changes
 )
 
 var (
@@ -85,6 +99,11 @@ var (
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
 
+	// Metrics for the per-origin admission quotas
+	originRateLimitMeter = metrics.NewRegisteredMeter("txpool/origin/ratelimit", nil) // Rejected due to the origin's admission rate
+	originOverflowMeter  = metrics.NewRegisteredMeter("txpool/origin/overflow", nil)  // Rejected due to the origin's slots
+	originGauge          = metrics.NewRegisteredGauge("txpool/origins", nil)
+
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
 	throttleTxMeter = metrics.NewRegisteredMeter("txpool/throttle", nil)
@@ -134,6 +153,17 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	Persist         bool                // Whether to persist all transactions into the database to survive node restarts
+	PersistLimit    uint64              // Maximum number of persisted transactions restored at startup
+	PersistLifetime time.Duration       // Maximum age of restored remote transactions and of the drop history
+	HistoryLimit    uint64              // Maximum number of dropped transactions remembered
+	Database        ethdb.KeyValueStore `toml:"-"` // Database to persist the transactions into
+
+	OriginRate      float64  // Transactions per second admitted from a single remote origin (0 = unlimited)
+	OriginBurst     uint64   // Maximum burst of transactions admitted from a single remote origin
+	OriginSlots     uint64   // Maximum number of transactions pooled from a single remote origin (0 = unlimited)
+	OriginWhitelist []string // Origins (RPC client IPs or peer IDs) exempt from the admission quotas
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -150,6 +180,12 @@ var DefaultConfig = Config{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	PersistLimit:    4096 + 1024 + 1024, // pending and queued capacity
+	PersistLifetime: 3 * time.Hour,
+	HistoryLimit:    8192,
+
+	OriginBurst: 64,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -188,6 +224,26 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
 	}
+	if conf.PersistLimit < 1 {
+		log.Warn("Sanitizing invalid txpool persist limit", "provided", conf.PersistLimit, "updated", DefaultConfig.PersistLimit)
+		conf.PersistLimit = DefaultConfig.PersistLimit
+	}
+	if conf.PersistLifetime < 1 {
+		log.Warn("Sanitizing invalid txpool persist lifetime", "provided", conf.PersistLifetime, "updated", DefaultConfig.PersistLifetime)
+		conf.PersistLifetime = DefaultConfig.PersistLifetime
+	}
+	if conf.HistoryLimit < 1 {
+		log.Warn("Sanitizing invalid txpool history limit", "provided", conf.HistoryLimit, "updated", DefaultConfig.HistoryLimit)
+		conf.HistoryLimit = DefaultConfig.HistoryLimit
+	}
+	if conf.OriginRate < 0 {
+		log.Warn("Sanitizing invalid txpool origin rate", "provided", conf.OriginRate, "updated", 0)
+		conf.OriginRate = 0
+	}
+	if conf.OriginRate > 0 && conf.OriginBurst < 1 {
+		log.Warn("Sanitizing invalid txpool origin burst", "provided", conf.OriginBurst, "updated", DefaultConfig.OriginBurst)
+		conf.OriginBurst = DefaultConfig.OriginBurst
+	}
 	return conf
 }
 
@@ -204,6 +260,7 @@ type LegacyPool struct {
 	chain       BlockChain
 	gasTip      atomic.Pointer[big.Int]
 	txFeed      event.Feed
+	eventFeed   event.Feed // Feed of the transaction lifecycle events
 	signer      types.Signer
 	mu          sync.RWMutex
 
@@ -213,6 +270,8 @@ type LegacyPool struct {
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *journal    // Journal of local transaction to back up to disk
+	store   *txStore    // Database store of all transactions and the drop history
+	quota   *quotaSet   // Admission quotas of the remote transaction origins
 
 	reserve txpool.AddressReserver       // Address reserver to ensure exclusivity across subpools
 	pending map[common.Address]*list     // All currently processable transactions
@@ -230,6 +289,10 @@ type LegacyPool struct {
 	initDoneCh      chan struct{}  // is closed once the pool is initialized (for tests)
 
 	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
+
+	events      []*txpool.LifecycleEvent // Lifecycle events pending until the next flush
+	resetHeads  *txpoolResetRequest      // Heads of the reset in progress, to tell included transactions apart
+	includedTxs map[common.Hash]struct{} // Transactions included by the reset in progress, lazily collected
 }
 
 type txpoolResetRequest struct {
@@ -269,6 +332,13 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 	}
+	// The drop history is always kept, persisted only if enabled
+	var db ethdb.KeyValueStore
+	if config.Persist {
+		db = config.Database
+	}
+	pool.store = newTxStore(&config, db)
+	pool.quota = newQuotaSet(&config)
 	return pool
 }
 
@@ -322,6 +392,11 @@ func (pool *LegacyPool) Init(gasTip *big.Int, head *types.Header, reserve txpool
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If transaction persistence is enabled, restore the pool from the database
+	locals, remotes := pool.store.load(pool.signer)
+	pool.restore(locals, true)
+	pool.restore(remotes, false)
+
 	pool.wg.Add(1)
 	go pool.loop()
 	return nil
@@ -340,10 +415,12 @@ func (pool *LegacyPool) loop() {
 		report  = time.NewTicker(statsReportInterval)
 		evict   = time.NewTicker(evictionInterval)
 		journal = time.NewTicker(pool.config.Rejournal)
+		persist = time.NewTicker(storeFlushInterval)
 	)
 	defer report.Stop()
 	defer evict.Stop()
 	defer journal.Stop()
+	defer persist.Stop()
 
 	// Notify tests that the init phase is done
 	close(pool.initDoneCh)
@@ -377,13 +454,19 @@ func (pool *LegacyPool) loop() {
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
+						pool.dropTx(tx, txpool.DropLifetime, nil)
 						pool.removeTx(tx.Hash(), true, true)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			pool.store.prune()
+			pool.quota.prune(pool.all.Get)
+			events := pool.flush()
 			pool.mu.Unlock()
 
+			pool.postEvents(events)
+
 		// Handle local transaction journal rotation
 		case <-journal.C:
 			if pool.journal != nil {
@@ -393,6 +476,10 @@ func (pool *LegacyPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+
+		// Handle transaction store persistence
+		case <-persist.C:
+			pool.store.flush()
 		}
 	}
 }
@@ -406,6 +493,8 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	pool.store.flush()
+
 	log.Info("Transaction pool stopped")
 	return nil
 }
@@ -431,7 +520,12 @@ func (pool *LegacyPool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs
 // new transaction, and drops all transactions below this threshold.
 func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 	pool.mu.Lock()
-	defer pool.mu.Unlock()
+	defer func() {
+		events := pool.flush()
+		pool.mu.Unlock()
+
+		pool.postEvents(events)
+	}()
 
 	old := pool.gasTip.Load()
 	pool.gasTip.Store(new(big.Int).Set(tip))
@@ -441,6 +535,7 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 		// pool.priced is sorted by GasFeeCap, so we have to iterate through pool.all instead
 		drop := pool.all.RemotesBelowTip(tip)
 		for _, tx := range drop {
+			pool.dropTx(tx, txpool.DropTipTooLow, nil)
 			pool.removeTx(tx.Hash(), false, true)
 		}
 		pool.priced.Removed(len(drop))
@@ -748,6 +843,8 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 
+			pool.dropTx(tx, txpool.DropUnderpriced, nil)
+
 			sender, _ := types.Sender(pool.signer, tx)
 			dropped := pool.removeTx(tx.Hash(), false, sender != from) // Don't unreserve the sender of the tx being added if last from the acc
 
@@ -765,6 +862,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		}
 		// New transaction is better, replace old one
 		if old != nil {
+			pool.dropTx(old, txpool.DropReplaced, tx)
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
@@ -773,6 +871,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		pool.priced.Put(tx, isLocal)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
+		pool.lifecycleEvent(tx, from, txpool.StageAdded, "", common.Hash{})
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// Successful promotion, bump the heartbeat
@@ -794,6 +893,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		localGauge.Inc(1)
 	}
 	pool.journalTx(from, tx)
+	pool.lifecycleEvent(tx, from, txpool.StageAdded, "", common.Hash{})
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replaced, nil
@@ -840,6 +940,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 	}
 	// Discard any previous transaction and mark this
 	if old != nil {
+		pool.dropTx(old, txpool.DropReplaced, tx)
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
@@ -863,9 +964,11 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 	return old != nil, nil
 }
 
-// journalTx adds the specified transaction to the local disk journal if it is
-// deemed to have been sent from a local account.
+// journalTx adds the specified transaction to the transaction store, and to the
+// local disk journal if it is deemed to have been sent from a local account.
 func (pool *LegacyPool) journalTx(from common.Address, tx *types.Transaction) {
+	pool.store.put(tx, pool.locals.contains(from))
+
 	// Only journal if it's enabled and the transaction is local
 	if pool.journal == nil || !pool.locals.contains(from) {
 		return
@@ -889,6 +992,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	inserted, old := list.Add(tx, pool.config.PriceBump)
 	if !inserted {
 		// An older transaction was better, discard this
+		pool.dropTx(tx, txpool.DropReplaced, list.txs.Get(tx.Nonce()))
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
@@ -896,6 +1000,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	}
 	// Otherwise discard any previous transaction and mark this
 	if old != nil {
+		pool.dropTx(old, txpool.DropReplaced, tx)
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
@@ -905,6 +1010,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	}
 	// Set the potentially new pending nonce and notify any subsystems of the new tx
 	pool.pendingNonces.set(addr, tx.Nonce()+1)
+	pool.lifecycleEvent(tx, addr, txpool.StagePromoted, "", common.Hash{})
 
 	// Successful promotion, bump the heartbeat
 	pool.beats[addr] = time.Now()
@@ -959,6 +1065,13 @@ func (pool *LegacyPool) addRemoteSync(tx *types.Transaction) error {
 // If sync is set, the method will block until all internal maintenance related
 // to the add is finished. Only use this during tests for determinism!
 func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error {
+	return pool.AddWithOrigin(txs, local, sync, "")
+}
+
+// AddWithOrigin implements txpool.OriginAdder, enqueueing a batch of transactions
+// like Add, but accounting the remote ones to their origin and rejecting those
+// over its admission quotas.
+func (pool *LegacyPool) AddWithOrigin(txs []*types.Transaction, local, sync bool, origin string) []error {
 	// Filter out known ones without obtaining the pool lock or recovering signatures
 	var (
 		errs = make([]error, len(txs))
@@ -988,9 +1101,12 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 
 	// Process all the new transaction and merge any errors into the original slice
 	pool.mu.Lock()
-	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
+	newErrs, dirtyAddrs := pool.addTxsLocked(news, local, origin)
+	events := pool.flush()
 	pool.mu.Unlock()
 
+	pool.postEvents(events)
+
 	var nilSlot = 0
 	for _, err := range newErrs {
 		for errs[nilSlot] != nil {
@@ -1007,14 +1123,27 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 	return errs
 }
 
-// addTxsLocked attempts to queue a batch of transactions if they are valid.
+// addTxsLocked attempts to queue a batch of transactions if they are valid and
+// within the admission quotas of their origin.
 // The transaction pool lock must be held.
-func (pool *LegacyPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *accountSet) {
+func (pool *LegacyPool) addTxsLocked(txs []*types.Transaction, local bool, origin string) ([]error, *accountSet) {
 	dirty := newAccountSet(pool.signer)
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		limited := !pool.quota.exempt(origin, from)
+		if limited {
+			if err := pool.quota.admit(origin, pool.all.Get); err != nil {
+				log.Trace("Discarding transaction over origin quota", "hash", tx.Hash(), "origin", origin, "err", err)
+				errs[i] = err
+				continue
+			}
+		}
 		replaced, err := pool.add(tx, local)
 		errs[i] = err
+		if err == nil && limited {
+			pool.quota.track(origin, tx.Hash())
+		}
 		if err == nil && !replaced {
 			dirty.addTx(tx)
 		}
@@ -1113,6 +1242,7 @@ func (pool *LegacyPool) removeTx(hash common.Hash, outofbound bool, unreserve bo
 			for _, tx := range invalids {
 				// Internal shuffle shouldn't touch the lookup set.
 				pool.enqueueTx(tx.Hash(), tx, false, false)
+				pool.lifecycleEvent(tx, addr, txpool.StageDemoted, txpool.DropNonceGap, common.Hash{})
 			}
 			// Update the account nonce if needed
 			pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1135,6 +1265,144 @@ func (pool *LegacyPool) removeTx(hash common.Hash, outofbound bool, unreserve bo
 	return 0
 }
 
+// dropTx records the removal of a transaction from the pool, along with the
+// transaction replacing it, if any.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) dropTx(tx *types.Transaction, reason txpool.DropReason, replacement *types.Transaction) {
+	var replacedBy common.Hash
+	if replacement != nil {
+		replacedBy = replacement.Hash()
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated during insertion
+	pool.store.remove(tx, from, pool.locals.contains(from), reason, replacedBy, nil)
+	pool.lifecycleEvent(tx, from, txpool.LifecycleStageFor(reason), reason, replacedBy)
+}
+
+// lifecycleEvent queues a lifecycle event of a transaction, to be posted once
+// the pool operation completes.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) lifecycleEvent(tx *types.Transaction, from common.Address, stage txpool.LifecycleStage, reason txpool.DropReason, replacedBy common.Hash) {
+	pool.events = append(pool.events, &txpool.LifecycleEvent{
+		Hash:       tx.Hash(),
+		From:       from,
+		Nonce:      tx.Nonce(),
+		Stage:      stage,
+		Reason:     reason,
+		ReplacedBy: replacedBy,
+		Time:       uint64(time.Now().Unix()),
+	})
+	metrics.GetOrRegisterMeter("txpool/lifecycle/"+string(stage), nil).Mark(1)
+	if reason != "" {
+		metrics.GetOrRegisterMeter("txpool/lifecycle/"+string(stage)+"/"+string(reason), nil).Mark(1)
+	}
+}
+
+// staleReason returns whether a transaction with an already used nonce was
+// included by the reset in progress, or was simply made stale by another one.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) staleReason(hash common.Hash) txpool.DropReason {
+	heads := pool.resetHeads
+	if heads == nil || heads.newHead == nil {
+		return txpool.DropStale
+	}
+	// Collect the transactions of the new blocks on first use
+	if pool.includedTxs == nil {
+		pool.includedTxs = make(map[common.Hash]struct{})
+
+		var oldHash common.Hash
+		if heads.oldHead != nil {
+			oldHash = heads.oldHead.Hash()
+		}
+		hash, number := heads.newHead.Hash(), heads.newHead.Number.Uint64()
+		for i := 0; i < maxIncludedDepth && hash != oldHash; i++ {
+			block := pool.chain.GetBlock(hash, number)
+			if block == nil {
+				break
+			}
+			for _, tx := range block.Transactions() {
+				pool.includedTxs[tx.Hash()] = struct{}{}
+			}
+			if number == 0 {
+				break
+			}
+			hash, number = block.ParentHash(), number-1
+		}
+	}
+	if _, ok := pool.includedTxs[hash]; ok {
+		return txpool.DropIncluded
+	}
+	return txpool.DropStale
+}
+
+// flush returns the lifecycle events queued since the last flush, to be posted
+// once the pool lock is released. The transaction store changes are persisted
+// separately, after reorgs and periodically, without the pool lock held.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) flush() []*txpool.LifecycleEvent {
+	events := pool.events
+	pool.events = nil
+	return events
+}
+
+// postEvents posts the lifecycle events to the subscribers.
+func (pool *LegacyPool) postEvents(events []*txpool.LifecycleEvent) {
+	if len(events) > 0 {
+		pool.eventFeed.Send(events)
+	}
+}
+
+// QuotaStats implements txpool.QuotaReporter, returning the status of the origin
+// admission quotas.
+func (pool *LegacyPool) QuotaStats() txpool.QuotaStats {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.quota.stats()
+}
+
+// SubscribeLifecycle implements txpool.LifecycleSubscriber, subscribing to the
+// lifecycle events of the pooled transactions.
+func (pool *LegacyPool) SubscribeLifecycle(ch chan<- []*txpool.LifecycleEvent) event.Subscription {
+	return pool.eventFeed.Subscribe(ch)
+}
+
+// restore re-adds the transactions persisted by a previous run to the pool,
+// recording the ones no longer valid.
+func (pool *LegacyPool) restore(txs []*types.Transaction, local bool) {
+	if len(txs) == 0 {
+		return
+	}
+	errs := pool.Add(txs, local, true)
+
+	pool.mu.Lock()
+	var invalids int
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, txpool.ErrAlreadyKnown) {
+			from, _ := types.Sender(pool.signer, txs[i])
+			pool.store.remove(txs[i], from, local, txpool.DropInvalid, common.Hash{}, err)
+			pool.lifecycleEvent(txs[i], from, txpool.StageDropped, txpool.DropInvalid, common.Hash{})
+			invalids++
+		}
+	}
+	events := pool.flush()
+	pool.mu.Unlock()
+
+	pool.postEvents(events)
+	if invalids > 0 {
+		log.Info("Dropped invalid persisted transactions", "local", local, "count", invalids)
+	}
+}
+
+// History implements txpool.HistoryReader, returning the most recent transactions
+// dropped from the pool, newest first.
+func (pool *LegacyPool) History(hash *common.Hash, from *common.Address, limit int) []*txpool.HistoryEntry {
+	return pool.store.query(hash, from, limit)
+}
+
 // requestReset requests a pool reset to the new head block.
 // The returned channel is closed when the reset has occurred.
 func (pool *LegacyPool) requestReset(oldHead *types.Header, newHead *types.Header) chan struct{} {
@@ -1253,6 +1521,9 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 	}
 	pool.mu.Lock()
 	if reset != nil {
+		// Track the reset heads to tell included transactions apart from the stale ones
+		pool.resetHeads = reset
+
 		// Reset from the old head to the new, rescheduling any reorged transactions
 		pool.reset(reset.oldHead, reset.newHead)
 
@@ -1300,8 +1571,13 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset change counter
+	pool.resetHeads, pool.includedTxs = nil, nil
+	lifecycle := pool.flush()
 	pool.mu.Unlock()
 
+	pool.postEvents(lifecycle)
+	pool.store.flush()
+
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
 		addr, _ := types.Sender(pool.signer, tx)
@@ -1415,7 +1691,7 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	core.SenderCacher.Recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, "")
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1436,6 +1712,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		forwards := list.Forward(pool.currentState.GetNonce(addr))
 		for _, tx := range forwards {
 			hash := tx.Hash()
+			pool.dropTx(tx, pool.staleReason(hash), nil)
 			pool.all.Remove(hash)
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
@@ -1443,6 +1720,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), gasLimit)
 		for _, tx := range drops {
 			hash := tx.Hash()
+			pool.dropTx(tx, txpool.DropUnpayable, nil)
 			pool.all.Remove(hash)
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
@@ -1465,6 +1743,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			caps = list.Cap(int(pool.config.AccountQueue))
 			for _, tx := range caps {
 				hash := tx.Hash()
+				pool.dropTx(tx, txpool.DropOverflow, nil)
 				pool.all.Remove(hash)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
@@ -1530,6 +1809,7 @@ func (pool *LegacyPool) truncatePending() {
 					for _, tx := range caps {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
+						pool.dropTx(tx, txpool.DropOverflow, nil)
 						pool.all.Remove(hash)
 
 						// Update the account nonce to the dropped transaction
@@ -1557,6 +1837,7 @@ func (pool *LegacyPool) truncatePending() {
 				for _, tx := range caps {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
+					pool.dropTx(tx, txpool.DropOverflow, nil)
 					pool.all.Remove(hash)
 
 					// Update the account nonce to the dropped transaction
@@ -1604,6 +1885,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
+				pool.dropTx(tx, txpool.DropOverflow, nil)
 				pool.removeTx(tx.Hash(), true, true)
 			}
 			drop -= size
@@ -1613,6 +1895,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Otherwise drop only last few transactions
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+			pool.dropTx(txs[i], txpool.DropOverflow, nil)
 			pool.removeTx(txs[i].Hash(), true, true)
 			drop--
 			queuedRateLimitMeter.Mark(1)
@@ -1637,6 +1920,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		olds := list.Forward(nonce)
 		for _, tx := range olds {
 			hash := tx.Hash()
+			pool.dropTx(tx, pool.staleReason(hash), nil)
 			pool.all.Remove(hash)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
@@ -1645,6 +1929,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
+			pool.dropTx(tx, txpool.DropUnpayable, nil)
 			pool.all.Remove(hash)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
@@ -1655,6 +1940,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 
 			// Internal shuffle shouldn't touch the lookup set.
 			pool.enqueueTx(hash, tx, false, false)
+			pool.lifecycleEvent(tx, addr, txpool.StageDemoted, txpool.DropNonceGap, common.Hash{})
 		}
 		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
 		if pool.locals.contains(addr) {
@@ -1669,6 +1955,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 
 				// Internal shuffle shouldn't touch the lookup set.
 				pool.enqueueTx(hash, tx, false, false)
+				pool.lifecycleEvent(tx, addr, txpool.StageDemoted, txpool.DropNonceGap, common.Hash{})
 			}
 			pendingGauge.Dec(int64(len(gapped)))
 		}
@@ -2418,6 +2418,229 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that local and remote transactions persisted into the database survive
+// a restart, that the ones invalidated meanwhile are dropped and that the drops
+// are recorded in the history along with their reason.
+func TestPersistence(t *testing.T) {
+	t.Parallel()
+
+	// Create the original pool to persist the transactions from
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.Persist = true
+	config.Database = rawdb.NewMemoryDatabase()
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Add a local and a few remote transactions, replacing one of the remotes
+	var (
+		localTx    = pricedTransaction(0, 100000, big.NewInt(1), local)
+		staleTx    = pricedTransaction(0, 100000, big.NewInt(1), remote)
+		replacedTx = pricedTransaction(1, 100000, big.NewInt(1), remote)
+		replacer   = pricedTransaction(1, 100000, big.NewInt(2), remote)
+	)
+	if err := pool.addLocal(localTx); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	for i, tx := range []*types.Transaction{staleTx, replacedTx, replacer} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	// The batched store changes are persisted once the reorg completes
+	if entries := rawdb.ReadTxPoolEntries(config.Database); len(entries) != 3 {
+		t.Fatalf("persisted transactions mismatched: have %d, want %d", len(entries), 3)
+	}
+	// Terminate the pool, use up the stale nonce and ensure the rest is restored
+	pool.Close()
+	statedb.SetNonce(crypto.PubkeyToAddress(remote.PublicKey), 1)
+	blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	pool = New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool size mismatch: have %d/%d, want %d/%d", pending, queued, 2, 0)
+	}
+	if !pool.Has(localTx.Hash()) || !pool.Has(replacer.Hash()) {
+		t.Fatalf("persisted transactions not restored")
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Ensure both drops were recorded, the first one even across the restart
+	hash := replacedTx.Hash()
+	if entries := pool.History(&hash, nil, 0); len(entries) != 1 {
+		t.Fatalf("replaced transaction history mismatch: have %d entries, want %d", len(entries), 1)
+	} else if entries[0].Reason != txpool.DropReplaced || entries[0].ReplacedBy != replacer.Hash() {
+		t.Fatalf("replaced transaction history mismatch: have %s by %x, want %s by %x", entries[0].Reason, entries[0].ReplacedBy, txpool.DropReplaced, replacer.Hash())
+	}
+	hash = staleTx.Hash()
+	if entries := pool.History(&hash, nil, 0); len(entries) != 1 {
+		t.Fatalf("stale transaction history mismatch: have %d entries, want %d", len(entries), 1)
+	} else if entries[0].Reason != txpool.DropInvalid || entries[0].Error == "" {
+		t.Fatalf("stale transaction history mismatch: have %s (%q), want %s", entries[0].Reason, entries[0].Error, txpool.DropInvalid)
+	}
+	from := crypto.PubkeyToAddress(remote.PublicKey)
+	if entries := pool.History(nil, &from, 0); len(entries) != 2 {
+		t.Fatalf("sender history mismatch: have %d entries, want %d", len(entries), 2)
+	}
+	if entries := pool.History(nil, nil, 1); len(entries) != 1 || entries[0].Hash != staleTx.Hash() {
+		t.Fatalf("limited history mismatch: have %v", entries)
+	}
+}
+
+// Tests that the lifecycle events of the transactions entering, moving within and
+// leaving the pool are posted along with their reasons.
+func TestLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	events := make(chan []*txpool.LifecycleEvent, 16)
+	sub := pool.SubscribeLifecycle(events)
+	defer sub.Unsubscribe()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	var (
+		gapped   = pricedTransaction(1, 100000, big.NewInt(1), key)
+		first    = pricedTransaction(0, 100000, big.NewInt(1), key)
+		replacer = pricedTransaction(0, 100000, big.NewInt(2), key)
+	)
+	for i, tx := range []*types.Transaction{gapped, first, replacer} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	// Drain the events and ensure they arrived in order
+	type stage struct {
+		hash   common.Hash
+		stage  txpool.LifecycleStage
+		reason txpool.DropReason
+	}
+	want := []stage{
+		{gapped.Hash(), txpool.StageAdded, ""},
+		{first.Hash(), txpool.StageAdded, ""},
+		{first.Hash(), txpool.StagePromoted, ""},
+		{gapped.Hash(), txpool.StagePromoted, ""},
+		{first.Hash(), txpool.StageReplaced, txpool.DropReplaced},
+		{replacer.Hash(), txpool.StageAdded, ""},
+	}
+	var have []stage
+	for len(have) < len(want) {
+		select {
+		case batch := <-events:
+			for _, ev := range batch {
+				if ev.From != from {
+					t.Errorf("event sender mismatch: have %x, want %x", ev.From, from)
+				}
+				if ev.Stage == txpool.StageReplaced && ev.ReplacedBy != replacer.Hash() {
+					t.Errorf("replacement mismatch: have %x, want %x", ev.ReplacedBy, replacer.Hash())
+				}
+				have = append(have, stage{ev.Hash, ev.Stage, ev.Reason})
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("lifecycle events missing: have %d, want %d", len(have), len(want))
+		}
+	}
+	if len(have) != len(want) {
+		t.Fatalf("event count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("event %d mismatch: have %v, want %v", i, have[i], want[i])
+		}
+	}
+}
+
+// Tests that the transactions of remote origins are admitted within the rate and
+// slot quotas of their origin, while exempt origins and senders are unlimited.
+func TestOriginQuotas(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	local, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.OriginRate = 0.001 // Practically no refill during the test
+	config.OriginBurst = 2
+	config.OriginSlots = 2
+	config.OriginWhitelist = []string{"10.0.0.1"}
+	config.Locals = []common.Address{crypto.PubkeyToAddress(local.PublicKey)}
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	// Create a batch of fresh accounts, one per transaction
+	fresh := func() *types.Transaction {
+		key, _ := crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+		return pricedTransaction(0, 100000, big.NewInt(1), key)
+	}
+	add := func(origin string, tx *types.Transaction) error {
+		return pool.AddWithOrigin([]*types.Transaction{tx}, false, true, origin)[0]
+	}
+	// Fill the slots of an origin and ensure it overflows
+	first := fresh()
+	if err := add("192.0.2.1", first); err != nil {
+		t.Fatalf("failed to add first transaction: %v", err)
+	}
+	if err := add("192.0.2.1", fresh()); err != nil {
+		t.Fatalf("failed to add second transaction: %v", err)
+	}
+	if err := add("192.0.2.1", fresh()); !errors.Is(err, ErrOriginOverflow) {
+		t.Fatalf("slot overflow mismatch: have %v, want %v", err, ErrOriginOverflow)
+	}
+	// Free a slot and ensure the origin's rate limit kicks in instead
+	pool.mu.Lock()
+	pool.removeTx(first.Hash(), true, true)
+	pool.mu.Unlock()
+
+	if err := add("192.0.2.1", fresh()); !errors.Is(err, ErrOriginRateLimited) {
+		t.Fatalf("rate limit mismatch: have %v, want %v", err, ErrOriginRateLimited)
+	}
+	// Ensure other origins have their own quotas and exempt ones have none
+	if err := add("192.0.2.2", fresh()); err != nil {
+		t.Fatalf("failed to add transaction from another origin: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := add("10.0.0.1", fresh()); err != nil {
+			t.Fatalf("failed to add transaction %d from whitelisted origin: %v", i, err)
+		}
+		if err := add("", fresh()); err != nil {
+			t.Fatalf("failed to add transaction %d without origin: %v", i, err)
+		}
+	}
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	for i := 0; i < 4; i++ {
+		if err := add("192.0.2.1", pricedTransaction(uint64(i), 100000, big.NewInt(1), local)); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", i, err)
+		}
+	}
+	if stats := pool.QuotaStats(); stats.Origins != 2 || stats.RateLimited != 1 || stats.Overflowed != 1 {
+		t.Fatalf("quota stats mismatch: have %+v", stats)
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
@@ -0,0 +1,155 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/time/rate"
+)
+
+// quotaSet enforces the admission quotas of the remote transaction origins,
+// the RPC client addresses or IDs of the peers the transactions were received
+// from. Each origin is limited by a token bucket refilling at the admission rate
+// and by the number of transactions it may have pooled at once.
+//
+// The quotas are modified with the pool lock held.
+type quotaSet struct {
+	rate  rate.Limit // Transactions per second admitted from an origin, 0 if unlimited
+	burst int        // Maximum burst of transactions admitted from an origin
+	slots int        // Maximum number of pooled transactions of an origin, 0 if unlimited
+
+	whitelist map[string]struct{}         // Origins exempt from the quotas
+	locals    map[common.Address]struct{} // Accounts exempt from the quotas
+	origins   map[string]*originState     // Quota state of the active origins
+
+	rateLimited uint64 // Transactions rejected for exceeding their origin's rate
+	overflowed  uint64 // Transactions rejected for exceeding their origin's slots
+}
+
+// originState is the quota state of a single origin.
+type originState struct {
+	limiter *rate.Limiter            // Admission rate limiter, nil if unlimited
+	txs     map[common.Hash]struct{} // Transactions admitted, possibly no longer pooled
+}
+
+// newQuotaSet creates the origin admission quotas of the given configuration.
+func newQuotaSet(config *Config) *quotaSet {
+	quota := &quotaSet{
+		rate:      rate.Limit(config.OriginRate),
+		burst:     int(config.OriginBurst),
+		slots:     int(config.OriginSlots),
+		whitelist: make(map[string]struct{}),
+		locals:    make(map[common.Address]struct{}),
+		origins:   make(map[string]*originState),
+	}
+	for _, origin := range config.OriginWhitelist {
+		quota.whitelist[origin] = struct{}{}
+	}
+	for _, addr := range config.Locals {
+		quota.locals[addr] = struct{}{}
+	}
+	return quota
+}
+
+// exempt returns whether a transaction from the given origin and sender is not
+// subject to the quotas. Transactions without an origin (e.g. submitted through
+// IPC or restored from disk), from whitelisted origins or from the configured
+// local accounts are exempt.
+func (q *quotaSet) exempt(origin string, from common.Address) bool {
+	if q.rate == 0 && q.slots == 0 {
+		return true
+	}
+	if origin == "" {
+		return true
+	}
+	if _, ok := q.whitelist[origin]; ok {
+		return true
+	}
+	_, ok := q.locals[from]
+	return ok
+}
+
+// admit checks whether a transaction may be admitted from the given origin,
+// consuming a token of its admission rate. The get callback retrieves a pooled
+// transaction, to tell which transactions of the origin are still pooled.
+func (q *quotaSet) admit(origin string, get func(common.Hash) *types.Transaction) error {
+	state := q.origins[origin]
+	if state == nil {
+		state = &originState{txs: make(map[common.Hash]struct{})}
+		if q.rate > 0 {
+			state.limiter = rate.NewLimiter(q.rate, q.burst)
+		}
+		q.origins[origin] = state
+		originGauge.Update(int64(len(q.origins)))
+	}
+	if q.slots > 0 && len(state.txs) >= q.slots {
+		// The origin seems full, forget its transactions no longer pooled
+		for hash := range state.txs {
+			if get(hash) == nil {
+				delete(state.txs, hash)
+			}
+		}
+		if len(state.txs) >= q.slots {
+			q.overflowed++
+			originOverflowMeter.Mark(1)
+			return ErrOriginOverflow
+		}
+	}
+	if state.limiter != nil && !state.limiter.Allow() {
+		q.rateLimited++
+		originRateLimitMeter.Mark(1)
+		return ErrOriginRateLimited
+	}
+	return nil
+}
+
+// track records a transaction admitted into the pool from the given origin.
+func (q *quotaSet) track(origin string, hash common.Hash) {
+	if state := q.origins[origin]; state != nil {
+		state.txs[hash] = struct{}{}
+	}
+}
+
+// prune forgets the transactions no longer pooled, and the origins without
+// pooled transactions whose admission rate fully recovered.
+func (q *quotaSet) prune(get func(common.Hash) *types.Transaction) {
+	now := time.Now()
+	for origin, state := range q.origins {
+		for hash := range state.txs {
+			if get(hash) == nil {
+				delete(state.txs, hash)
+			}
+		}
+		if len(state.txs) == 0 && (state.limiter == nil || state.limiter.TokensAt(now) >= float64(q.burst)) {
+			delete(q.origins, origin)
+		}
+	}
+	originGauge.Update(int64(len(q.origins)))
+}
+
+// stats returns the status of the origin admission quotas.
+func (q *quotaSet) stats() txpool.QuotaStats {
+	return txpool.QuotaStats{
+		Origins:     len(q.origins),
+		RateLimited: q.rateLimited,
+		Overflowed:  q.overflowed,
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// storedTx is the database representation of a persisted pool transaction.
+type storedTx struct {
+	Tx    []byte // Binary encoding of the transaction
+	Local bool   // Whether the transaction was local
+	Time  uint64 // Unix time the transaction was first seen
+}
+
+// txStore persists all transactions of the pool into the database, local and
+// remote ones, along with the history of the transactions removed from the pool.
+// This allows restoring the pool after a restart. Without a database only the
+// history is kept, in memory.
+//
+// The store is modified with the pool lock held, writes are batched until flush
+// is called periodically or after a pool reorg, without the pool lock held.
+type txStore struct {
+	db        ethdb.KeyValueStore // Database to persist into, nil if persistence is disabled
+	batch     ethdb.Batch         // Writes pending until the next flush
+	batchLock sync.Mutex          // Protects the pending batch, swapped out on flush
+	writeLock sync.Mutex          // Serializes the database writes of the flushed batches
+
+	limit        int           // Maximum number of transactions to restore
+	lifetime     time.Duration // Maximum age of restored transactions and history records
+	historyLimit int           // Maximum number of history records
+
+	lock    sync.RWMutex                         // Protects the history, which is read without the pool lock
+	history []*txpool.HistoryEntry               // Removal records, oldest first
+	index   map[common.Hash]*txpool.HistoryEntry // Most recent removal record of each transaction
+}
+
+// newTxStore creates a transaction store, persisting into the given database
+// if it's non-nil.
+func newTxStore(config *Config, db ethdb.KeyValueStore) *txStore {
+	store := &txStore{
+		db:           db,
+		limit:        int(config.PersistLimit),
+		lifetime:     config.PersistLifetime,
+		historyLimit: int(config.HistoryLimit),
+		index:        make(map[common.Hash]*txpool.HistoryEntry),
+	}
+	if db != nil {
+		store.batch = db.NewBatch()
+	}
+	return store
+}
+
+// load reads the persisted history and transactions from the database, returning
+// the transactions to restore into the pool. Transactions over the age or count
+// limits are dropped.
+func (s *txStore) load(signer types.Signer) (locals, remotes []*types.Transaction) {
+	if s.db == nil {
+		return nil, nil
+	}
+	for _, blob := range rawdb.ReadTxPoolHistory(s.db) {
+		entry := new(txpool.HistoryEntry)
+		if err := rlp.DecodeBytes(blob, entry); err != nil {
+			log.Warn("Failed to decode transaction pool history", "err", err)
+			continue
+		}
+		s.history = append(s.history, entry)
+		s.index[entry.Hash] = entry
+	}
+	type restored struct {
+		tx    *types.Transaction
+		local bool
+		time  uint64
+	}
+	var txs []restored
+	for _, blob := range rawdb.ReadTxPoolEntries(s.db) {
+		var stored storedTx
+		if err := rlp.DecodeBytes(blob, &stored); err != nil {
+			log.Warn("Failed to decode persisted transaction", "err", err)
+			continue
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(stored.Tx); err != nil {
+			log.Warn("Failed to decode persisted transaction", "err", err)
+			continue
+		}
+		tx.SetTime(time.Unix(int64(stored.Time), 0)) // Keep the age across restarts
+		txs = append(txs, restored{tx: tx, local: stored.Local, time: stored.Time})
+	}
+	// Restore local transactions first, then the most recent remote ones
+	sort.SliceStable(txs, func(i, j int) bool {
+		if txs[i].local != txs[j].local {
+			return txs[i].local
+		}
+		return txs[i].time > txs[j].time
+	})
+	var (
+		cutoff  = uint64(time.Now().Add(-s.lifetime).Unix())
+		dropped int
+	)
+	for i, stored := range txs {
+		var reason txpool.DropReason
+		switch {
+		case !stored.local && stored.time < cutoff:
+			reason = txpool.DropLifetime
+		case i >= s.limit:
+			reason = txpool.DropOverflow
+		}
+		if reason != "" {
+			from, _ := types.Sender(signer, stored.tx)
+			s.remove(stored.tx, from, stored.local, reason, common.Hash{}, nil)
+			dropped++
+			continue
+		}
+		if stored.local {
+			locals = append(locals, stored.tx)
+		} else {
+			remotes = append(remotes, stored.tx)
+		}
+	}
+	s.prune()
+	s.flush()
+
+	log.Info("Loaded persisted transactions", "locals", len(locals), "remotes", len(remotes), "dropped", dropped, "history", len(s.history))
+	return locals, remotes
+}
+
+// put persists a transaction added to the pool.
+func (s *txStore) put(tx *types.Transaction, local bool) {
+	if s.db == nil {
+		return
+	}
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		log.Warn("Failed to encode transaction", "hash", tx.Hash(), "err", err)
+		return
+	}
+	entry, err := rlp.EncodeToBytes(&storedTx{Tx: blob, Local: local, Time: uint64(tx.Time().Unix())})
+	if err != nil {
+		log.Warn("Failed to encode transaction", "hash", tx.Hash(), "err", err)
+		return
+	}
+	s.batchLock.Lock()
+	rawdb.WriteTxPoolEntry(s.batch, tx.Hash(), entry)
+	s.batchLock.Unlock()
+}
+
+// remove deletes a transaction removed from the pool, recording the reason.
+func (s *txStore) remove(tx *types.Transaction, from common.Address, local bool, reason txpool.DropReason, replacement common.Hash, err error) {
+	entry := &txpool.HistoryEntry{
+		Hash:       tx.Hash(),
+		From:       from,
+		Nonce:      tx.Nonce(),
+		Local:      local,
+		Added:      uint64(tx.Time().Unix()),
+		Removed:    uint64(time.Now().Unix()),
+		Reason:     reason,
+		ReplacedBy: replacement,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	s.lock.Lock()
+	s.history = append(s.history, entry)
+	s.index[entry.Hash] = entry
+	for len(s.history) > s.historyLimit {
+		s.forget()
+	}
+	s.lock.Unlock()
+
+	if s.db == nil {
+		return
+	}
+	blob, rerr := rlp.EncodeToBytes(entry)
+
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+
+	rawdb.DeleteTxPoolEntry(s.batch, entry.Hash)
+	if rerr != nil {
+		log.Warn("Failed to encode transaction pool history", "hash", entry.Hash, "err", rerr)
+		return
+	}
+	rawdb.WriteTxPoolHistory(s.batch, entry.Removed, entry.Hash, blob)
+}
+
+// forget drops the oldest history record.
+//
+// Note, this method assumes the history lock is held!
+func (s *txStore) forget() {
+	entry := s.history[0]
+	s.history[0] = nil
+	s.history = s.history[1:]
+
+	if s.index[entry.Hash] == entry {
+		delete(s.index, entry.Hash)
+	}
+	if s.db != nil {
+		s.batchLock.Lock()
+		rawdb.DeleteTxPoolHistory(s.batch, entry.Removed, entry.Hash)
+		s.batchLock.Unlock()
+	}
+}
+
+// prune drops the history records older than the lifetime.
+func (s *txStore) prune() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cutoff := uint64(time.Now().Add(-s.lifetime).Unix())
+	for len(s.history) > 0 && (len(s.history) > s.historyLimit || s.history[0].Removed < cutoff) {
+		s.forget()
+	}
+}
+
+// flush writes all pending changes into the database. The pending batch is
+// swapped out first, so the pool may keep modifying the store during the write.
+func (s *txStore) flush() {
+	if s.db == nil {
+		return
+	}
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	s.batchLock.Lock()
+	batch := s.batch
+	if batch.ValueSize() == 0 {
+		s.batchLock.Unlock()
+		return
+	}
+	s.batch = s.db.NewBatch()
+	s.batchLock.Unlock()
+
+	if err := batch.Write(); err != nil {
+		log.Warn("Failed to persist transaction pool", "err", err)
+	}
+}
+
+// query returns the most recent history records, newest first, optionally
+// filtered by transaction hash or sender.
+func (s *txStore) query(hash *common.Hash, from *common.Address, limit int) []*txpool.HistoryEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var entries []*txpool.HistoryEntry
+	if hash != nil {
+		if entry := s.index[*hash]; entry != nil && (from == nil || entry.From == *from) {
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+	for i := len(s.history) - 1; i >= 0 && (limit <= 0 || len(entries) < limit); i-- {
+		if from == nil || s.history[i].From == *from {
+			entries = append(entries, s.history[i])
+		}
+	}
+	return entries
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// LifecycleStage is a step in the life of a pooled transaction.
+type LifecycleStage string
+
+const (
+	StageAdded    LifecycleStage = "added"    // Accepted into the pool
+	StagePromoted LifecycleStage = "promoted" // Moved from the queue into the pending set
+	StageDemoted  LifecycleStage = "demoted"  // Moved from the pending set back into the queue
+	StageReplaced LifecycleStage = "replaced" // Removed in favour of a transaction with the same nonce
+	StageDropped  LifecycleStage = "dropped"  // Removed without being included
+	StageIncluded LifecycleStage = "included" // Removed after being included in a block
+)
+
+// LifecycleEvent is posted when a transaction enters, moves within or leaves
+// the pool.
+type LifecycleEvent struct {
+	Hash       common.Hash
+	From       common.Address
+	Nonce      uint64
+	Stage      LifecycleStage
+	Reason     DropReason  // Why the transaction was moved or removed, empty if added or promoted
+	ReplacedBy common.Hash // Replacement transaction, if replaced
+	Time       uint64      // Unix time of the event
+}
+
+// LifecycleStageFor returns the lifecycle stage of a transaction removed from
+// the pool for the given reason.
+func LifecycleStageFor(reason DropReason) LifecycleStage {
+	switch reason {
+	case DropReplaced:
+		return StageReplaced
+	case DropIncluded:
+		return StageIncluded
+	default:
+		return StageDropped
+	}
+}
+
+// LifecycleSubscriber is implemented by subpools posting lifecycle events.
+type LifecycleSubscriber interface {
+	// SubscribeLifecycle subscribes to the lifecycle events of the transactions
+	// of the subpool, posted in batches.
+	SubscribeLifecycle(ch chan<- []*LifecycleEvent) event.Subscription
+}
+
+// SubscribeLifecycle subscribes to the lifecycle events of the transactions of
+// all subpools posting them.
+func (p *TxPool) SubscribeLifecycle(ch chan<- []*LifecycleEvent) event.Subscription {
+	var subs []event.Subscription
+	for _, subpool := range p.subpools {
+		if subscriber, ok := subpool.(LifecycleSubscriber); ok {
+			subs = append(subs, subscriber.SubscribeLifecycle(ch))
+		}
+	}
+	return p.subs.Track(event.JoinSubscriptions(subs...))
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// OriginAdder is implemented by subpools enforcing admission quotas per origin,
+// the RPC client address or the ID of the peer a transaction was received from.
+type OriginAdder interface {
+	// AddWithOrigin enqueues a batch of transactions into the subpool, accounting
+	// them to the given origin. An empty origin is exempt from the quotas.
+	AddWithOrigin(txs []*types.Transaction, local bool, sync bool, origin string) []error
+}
+
+// QuotaStats is the status of the per-origin admission quotas of a subpool.
+type QuotaStats struct {
+	Origins     int    // Number of origins with pooled transactions or a draining rate limit
+	RateLimited uint64 // Transactions rejected for exceeding the admission rate of their origin
+	Overflowed  uint64 // Transactions rejected for exceeding the slots of their origin
+}
+
+// QuotaReporter is implemented by subpools enforcing admission quotas per origin.
+type QuotaReporter interface {
+	// QuotaStats returns the status of the per-origin admission quotas.
+	QuotaStats() QuotaStats
+}
+
+// QuotaStats returns the status of the per-origin admission quotas, summed over
+// all subpools enforcing them.
+func (p *TxPool) QuotaStats() QuotaStats {
+	var stats QuotaStats
+	for _, subpool := range p.subpools {
+		if reporter, ok := subpool.(QuotaReporter); ok {
+			sub := reporter.QuotaStats()
+
+			stats.Origins += sub.Origins
+			stats.RateLimited += sub.RateLimited
+			stats.Overflowed += sub.Overflowed
+		}
+	}
+	return stats
+}
@@ -264,6 +264,14 @@ func (p *TxPool) Get(hash common.Hash) *types.Transaction {
 // to the large transaction churn, add may postpone fully integrating the tx
 // to a later point to batch multiple ones together.
 func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	return p.AddWithOrigin(txs, local, sync, "")
+}
+
+// AddWithOrigin enqueues a batch of transactions into the pool if they are valid,
+// accounting them to their origin, the RPC client address or the ID of the peer
+// they were received from. Subpools enforcing per-origin admission quotas reject
+// the transactions over the quotas of the origin.
+func (p *TxPool) AddWithOrigin(txs []*types.Transaction, local bool, sync bool, origin string) []error {
 	// Split the input transactions between the subpools. It shouldn't really
 	// happen that we receive merged batches, but better graceful than strange
 	// errors.
@@ -290,7 +298,11 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	// back the errors into the original sort order.
 	errsets := make([][]error, len(p.subpools))
 	for i := 0; i < len(p.subpools); i++ {
-		errsets[i] = p.subpools[i].Add(txsets[i], local, sync)
+		if adder, ok := p.subpools[i].(OriginAdder); ok {
+			errsets[i] = adder.AddWithOrigin(txsets[i], local, sync, origin)
+		} else {
+			errsets[i] = p.subpools[i].Add(txsets[i], local, sync)
+		}
 	}
 	errs := make([]error, len(txs))
 	for i, split := range splits {
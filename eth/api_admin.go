@@ -24,8 +24,11 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -166,3 +169,64 @@ func (api *AdminAPI) MaxPeers(n int) (bool, error) {
 	}
 	return true, nil
 }
+
+// SetBootnodes replaces the bootstrap nodes of the discovery protocols and
+// re-seeds the node tables from them. All URLs must be valid, complete nodes.
+func (api *AdminAPI) SetBootnodes(urls []string) (bool, error) {
+	nodes, err := p2p.ParseBootnodes(urls)
+	if err != nil {
+		return false, err
+	}
+	if err := api.eth.p2pServer.SetBootnodes(nodes); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// BootnodeStatus is the reachability and fork compatibility of a bootnode.
+type BootnodeStatus struct {
+	Enode      string          `json:"enode"`
+	ID         string          `json:"id"`
+	Alive      bool            `json:"alive"`
+	RTT        string          `json:"rtt,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	ENR        string          `json:"enr,omitempty"`        // Current record, if it could be retrieved
+	ForkHash   *hexutil.Bytes  `json:"forkHash,omitempty"`   // Fork ID advertised in the record
+	ForkNext   *hexutil.Uint64 `json:"forkNext,omitempty"`   // Fork ID advertised in the record
+	Compatible *bool           `json:"compatible,omitempty"` // Whether the fork ID passes the local fork filter
+}
+
+// BootnodeStatus pings all bootstrap nodes and reports whether they are alive
+// and on a fork compatible with the local chain.
+func (api *AdminAPI) BootnodeStatus() ([]*BootnodeStatus, error) {
+	checks, err := api.eth.p2pServer.CheckBootnodes()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*BootnodeStatus, 0, len(checks))
+	for _, check := range checks {
+		status := &BootnodeStatus{
+			Enode: check.Node.URLv4(),
+			ID:    check.Node.ID().String(),
+			Alive: check.Err == nil,
+		}
+		if check.Err != nil {
+			status.Error = check.Err.Error()
+		} else {
+			status.RTT = check.RTT.String()
+		}
+		if check.Record != nil {
+			status.ENR = check.Record.String()
+			if id, ok := eth.NodeForkID(check.Record); ok {
+				var (
+					hash       = hexutil.Bytes(id.Hash[:])
+					next       = hexutil.Uint64(id.Next)
+					compatible = api.eth.handler.forkFilter(id) == nil
+				)
+				status.ForkHash, status.ForkNext, status.Compatible = &hash, &next, &compatible
+			}
+		}
+		results = append(results, status)
+	}
+	return results, nil
+}
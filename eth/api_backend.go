@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"net"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -263,6 +264,10 @@ func (b *EthAPIBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.
 	return b.eth.miner.SubscribePendingLogs(ch)
 }
 
+func (b *EthAPIBackend) SubscribeTxLifecycleEvent(ch chan<- []*txpool.LifecycleEvent) event.Subscription {
+	return b.eth.txPool.SubscribeLifecycle(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainEvent(ch)
 }
@@ -280,7 +285,21 @@ func (b *EthAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscri
 }
 
 func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
-	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
+	return b.eth.txPool.AddWithOrigin([]*types.Transaction{signedTx}, true, false, rpcOrigin(ctx))[0]
+}
+
+// rpcOrigin returns the IP address of the RPC client making the request, to be
+// accounted with the transaction pool admission quotas. In-process and IPC
+// clients have no origin.
+func rpcOrigin(ctx context.Context) string {
+	info := rpc.PeerInfoFromContext(ctx)
+	if info.Transport == "ipc" || info.RemoteAddr == "" {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(info.RemoteAddr); err == nil {
+		return host
+	}
+	return info.RemoteAddr
 }
 
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
@@ -321,6 +340,14 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transac
 	return b.eth.txPool.ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) TxPoolHistory(hash *common.Hash, from *common.Address, limit int) []*txpool.HistoryEntry {
+	return b.eth.txPool.History(hash, from, limit)
+}
+
+func (b *EthAPIBackend) TxPoolQuotaStats() txpool.QuotaStats {
+	return b.eth.txPool.QuotaStats()
+}
+
 func (b *EthAPIBackend) TxPool() *txpool.TxPool {
 	return b.eth.txPool
 }
@@ -341,6 +368,10 @@ func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount uint64, lastB
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
 
+func (b *EthAPIBackend) SuggestGasPriceLevels(ctx context.Context) (*gasprice.PriceLevels, error) {
+	return b.gpo.SuggestPriceLevels(ctx)
+}
+
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
@@ -0,0 +1,231 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BundleAPI provides an API to submit and simulate bundles of transactions,
+// which are mined atomically at the top of a block.
+type BundleAPI struct {
+	eth *Ethereum
+}
+
+// NewBundleAPI creates a new instance of BundleAPI.
+func NewBundleAPI(eth *Ethereum) *BundleAPI {
+	return &BundleAPI{eth: eth}
+}
+
+// SendBundleArgs are the arguments of eth_sendBundle.
+type SendBundleArgs struct {
+	Txs         []hexutil.Bytes `json:"txs"`         // Signed transactions, in inclusion order
+	BlockNumber hexutil.Uint64  `json:"blockNumber"` // Block to include the bundle in, the next one if zero
+}
+
+// SendBundleResult is the result of eth_sendBundle.
+type SendBundleResult struct {
+	BundleHash common.Hash `json:"bundleHash"`
+}
+
+// SendBundle submits a bundle for inclusion into the block it targets, which
+// must be one of the next few blocks. The bundle is simulated against the
+// pending state first and rejected if any of its transactions is invalid.
+func (api *BundleAPI) SendBundle(ctx context.Context, args SendBundleArgs) (*SendBundleResult, error) {
+	txs, err := decodeBundleTxs(args.Txs)
+	if err != nil {
+		return nil, err
+	}
+	head := api.eth.blockchain.CurrentBlock().Number.Uint64()
+	bundle := &miner.Bundle{Txs: txs, BlockNumber: uint64(args.BlockNumber)}
+	if bundle.BlockNumber == 0 {
+		bundle.BlockNumber = head + 1
+	}
+	if bundle.BlockNumber <= head {
+		return nil, fmt.Errorf("bundle targets block %d, already past head %d", bundle.BlockNumber, head)
+	}
+	block, statedb := api.eth.miner.Pending()
+	if block == nil || statedb == nil {
+		return nil, errors.New("pending state not available")
+	}
+	ctx, cancel := api.evmContext(ctx)
+	defer cancel()
+
+	header := block.Header()
+	if _, err := miner.SimulateBundle(ctx, api.eth.blockchain.Config(), api.eth.blockchain, header, statedb, txs, *api.eth.blockchain.GetVMConfig(), api.eth.APIBackend.RPCGasCap()); err != nil {
+		return nil, err
+	}
+	if err := api.eth.miner.AddBundle(bundle); err != nil {
+		return nil, err
+	}
+	return &SendBundleResult{BundleHash: bundle.Hash()}, nil
+}
+
+// CallBundleArgs are the arguments of eth_callBundle.
+type CallBundleArgs struct {
+	Txs              []hexutil.Bytes        `json:"txs"`              // Signed transactions, in inclusion order
+	BlockNumber      hexutil.Uint64         `json:"blockNumber"`      // Number of the simulated block, the one after the state block if zero
+	StateBlockNumber *rpc.BlockNumberOrHash `json:"stateBlockNumber"` // Block whose state to execute on, pending if omitted
+	Timestamp        *hexutil.Uint64        `json:"timestamp"`        // Timestamp of the simulated block
+	Coinbase         *common.Address        `json:"coinbase"`         // Coinbase of the simulated block, the etherbase if omitted
+}
+
+// CallBundleTxResult is the outcome of a single bundle transaction.
+type CallBundleTxResult struct {
+	TxHash            common.Hash     `json:"txHash"`
+	From              common.Address  `json:"fromAddress"`
+	To                *common.Address `json:"toAddress"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	GasPrice          *hexutil.Big    `json:"gasPrice"`
+	GasFees           *hexutil.Big    `json:"gasFees"`
+	CoinbaseDiff      *hexutil.Big    `json:"coinbaseDiff"`
+	EthSentToCoinbase *hexutil.Big    `json:"ethSentToCoinbase"`
+	Error             string          `json:"error,omitempty"`  // Error the execution failed with
+	Revert            hexutil.Bytes   `json:"revert,omitempty"` // Revert data of the execution
+}
+
+// CallBundleResult is the result of eth_callBundle.
+type CallBundleResult struct {
+	BundleHash        common.Hash           `json:"bundleHash"`
+	Results           []*CallBundleTxResult `json:"results"`
+	TotalGasUsed      hexutil.Uint64        `json:"totalGasUsed"`
+	BundleGasPrice    *hexutil.Big          `json:"bundleGasPrice"` // Coinbase payment per gas
+	GasFees           *hexutil.Big          `json:"gasFees"`
+	CoinbaseDiff      *hexutil.Big          `json:"coinbaseDiff"`
+	EthSentToCoinbase *hexutil.Big          `json:"ethSentToCoinbase"`
+	StateBlockNumber  hexutil.Uint64        `json:"stateBlockNumber"`
+}
+
+// CallBundle executes a bundle on top of the given state without submitting it,
+// returning the outcome of each transaction and the payment to the coinbase. As
+// eth_call, the execution is subject to the RPC gas cap and EVM timeout.
+func (api *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (*CallBundleResult, error) {
+	txs, err := decodeBundleTxs(args.Txs)
+	if err != nil {
+		return nil, err
+	}
+	stateBlock := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	if args.StateBlockNumber != nil {
+		stateBlock = *args.StateBlockNumber
+	}
+	statedb, parent, err := api.eth.APIBackend.StateAndHeaderByNumberOrHash(ctx, stateBlock)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	// Assemble the header of the simulated block on top of the state block
+	var (
+		config = api.eth.blockchain.Config()
+		header = &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			GasLimit:   parent.GasLimit,
+			Difficulty: parent.Difficulty,
+			Time:       parent.Time + 1,
+		}
+	)
+	if args.BlockNumber != 0 {
+		header.Number = new(big.Int).SetUint64(uint64(args.BlockNumber))
+	}
+	if args.Timestamp != nil {
+		header.Time = uint64(*args.Timestamp)
+	} else if now := uint64(time.Now().Unix()); now > header.Time {
+		header.Time = now
+	}
+	if args.Coinbase != nil {
+		header.Coinbase = *args.Coinbase
+	} else if etherbase, err := api.eth.Etherbase(); err == nil {
+		header.Coinbase = etherbase
+	}
+	if config.IsEnabled(config.GetEIP1559Transition, header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	ctx, cancel := api.evmContext(ctx)
+	defer cancel()
+
+	result, err := miner.SimulateBundle(ctx, config, api.eth.blockchain, header, statedb, txs, *api.eth.blockchain.GetVMConfig(), api.eth.APIBackend.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	res := &CallBundleResult{
+		BundleHash:        (&miner.Bundle{Txs: txs}).Hash(),
+		Results:           make([]*CallBundleTxResult, 0, len(result.Txs)),
+		TotalGasUsed:      hexutil.Uint64(result.GasUsed),
+		BundleGasPrice:    (*hexutil.Big)(result.GasPrice()),
+		GasFees:           (*hexutil.Big)(result.GasFees),
+		CoinbaseDiff:      (*hexutil.Big)(result.CoinbaseDiff),
+		EthSentToCoinbase: (*hexutil.Big)(new(big.Int).Sub(result.CoinbaseDiff, result.GasFees)),
+		StateBlockNumber:  hexutil.Uint64(parent.Number.Uint64()),
+	}
+	for _, tx := range result.Txs {
+		txRes := &CallBundleTxResult{
+			TxHash:            tx.Hash,
+			From:              tx.From,
+			To:                tx.To,
+			GasUsed:           hexutil.Uint64(tx.GasUsed),
+			GasPrice:          (*hexutil.Big)(tx.GasPrice),
+			GasFees:           (*hexutil.Big)(tx.GasFees),
+			CoinbaseDiff:      (*hexutil.Big)(tx.CoinbaseDiff),
+			EthSentToCoinbase: (*hexutil.Big)(new(big.Int).Sub(tx.CoinbaseDiff, tx.GasFees)),
+		}
+		if tx.Err != nil {
+			txRes.Error = tx.Err.Error()
+			if reason, err := abi.UnpackRevert(tx.Revert); err == nil {
+				txRes.Error += ": " + reason
+			}
+			txRes.Revert = tx.Revert
+		}
+		res.Results = append(res.Results, txRes)
+	}
+	return res, nil
+}
+
+// evmContext returns the context of a bundle execution, cancelled after the RPC
+// EVM timeout if one is configured.
+func (api *BundleAPI) evmContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := api.eth.APIBackend.RPCEVMTimeout(); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// decodeBundleTxs decodes the signed transactions of a bundle.
+func decodeBundleTxs(raw []hexutil.Bytes) (types.Transactions, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("bundle missing txs")
+	}
+	txs := make(types.Transactions, len(raw))
+	for i, input := range raw {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil {
+			return nil, fmt.Errorf("tx %d: %v", i, err)
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
@@ -17,12 +17,17 @@
 package eth
 
 import (
+	"fmt"
 	"math/big"
 	"runtime"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
 )
 
 // MinerAPI provides an API to control the miner.
@@ -87,3 +92,197 @@ func (api *MinerAPI) SetEtherbase(etherbase common.Address) bool {
 func (api *MinerAPI) SetRecommitInterval(interval int) {
 	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
 }
+
+// SealPolicyArgs is the policy deciding which proof-of-work sealing work is
+// released. Fields left out of an update keep their value.
+type SealPolicyArgs struct {
+	MinTxs      *math.HexOrDecimal64 `json:"minTxs"`
+	MinFees     *hexutil.Big         `json:"minFees"`
+	FillTimeout *math.HexOrDecimal64 `json:"fillTimeout"` // milliseconds
+	PauseEmpty  *bool                `json:"pauseEmpty"`
+}
+
+// SetSealPolicy updates the policy deciding which proof-of-work sealing work
+// is released: the minimum number of transactions or fees before non-empty
+// work replaces the empty block being mined, the maximum time in milliseconds
+// spent filling transactions, and whether to pause mining empty blocks while
+// the pool has pending transactions.
+func (api *MinerAPI) SetSealPolicy(args SealPolicyArgs) (bool, error) {
+	policy := api.e.Miner().SealPolicy()
+	if args.MinTxs != nil {
+		policy.MinTxs = uint64(*args.MinTxs)
+	}
+	if args.MinFees != nil {
+		policy.MinFees = (*big.Int)(args.MinFees)
+	}
+	if args.FillTimeout != nil {
+		policy.FillTimeout = time.Duration(*args.FillTimeout) * time.Millisecond
+	}
+	if args.PauseEmpty != nil {
+		policy.PauseEmpty = *args.PauseEmpty
+	}
+	if err := api.e.Miner().SetSealPolicy(policy); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetSealPolicy returns the policy deciding which proof-of-work sealing work is
+// released.
+func (api *MinerAPI) GetSealPolicy() *SealPolicyArgs {
+	var (
+		policy      = api.e.Miner().SealPolicy()
+		minTxs      = math.HexOrDecimal64(policy.MinTxs)
+		fillTimeout = math.HexOrDecimal64(policy.FillTimeout / time.Millisecond)
+		minFees     = new(big.Int)
+	)
+	if policy.MinFees != nil {
+		minFees.Set(policy.MinFees)
+	}
+	return &SealPolicyArgs{
+		MinTxs:      &minTxs,
+		MinFees:     (*hexutil.Big)(minFees),
+		FillTimeout: &fillTimeout,
+		PauseEmpty:  &policy.PauseEmpty,
+	}
+}
+
+// maxSealingStatsEpochs is the maximum number of epochs of sealing statistics
+// returned at once.
+const maxSealingStatsEpochs = 128
+
+// SealingStats are the statistics of the chain and of the blocks sealed locally
+// during an epoch.
+type SealingStats struct {
+	Epoch      hexutil.Uint64 `json:"epoch"`
+	FirstBlock hexutil.Uint64 `json:"firstBlock"`
+	LastBlock  hexutil.Uint64 `json:"lastBlock"`
+
+	Blocks     hexutil.Uint64 `json:"blocks"`
+	Uncles     hexutil.Uint64 `json:"uncles"`
+	SideBlocks hexutil.Uint64 `json:"sideBlocks"`
+	UncleRate  float64        `json:"uncleRate"`
+	OrphanRate float64        `json:"orphanRate"`
+
+	Mined           hexutil.Uint64 `json:"mined"`
+	Canonical       hexutil.Uint64 `json:"canonical"`
+	Included        hexutil.Uint64 `json:"includedAsUncle"`
+	Lost            hexutil.Uint64 `json:"lost"`
+	Reorged         hexutil.Uint64 `json:"lostToReorg"`
+	SealedUncleRate float64        `json:"sealedUncleRate"`
+	SealedLostRate  float64        `json:"sealedLostRate"`
+
+	ReorgDepths map[string]hexutil.Uint64 `json:"reorgDepths"`
+}
+
+// GetSealingStats returns the uncle, orphan and reorg statistics of the chain
+// and of the blocks sealed locally, by epoch. It covers the given number of
+// epochs up to the current one, or only the current one if omitted.
+func (api *MinerAPI) GetSealingStats(epochs *hexutil.Uint64) ([]*SealingStats, error) {
+	count := uint64(1)
+	if epochs != nil {
+		count = uint64(*epochs)
+	}
+	if count > maxSealingStatsEpochs {
+		return nil, fmt.Errorf("too many epochs requested: %d, max %d", count, maxSealingStatsEpochs)
+	}
+	var result []*SealingStats
+	for _, stats := range api.e.Miner().SealingStats(count) {
+		first, last := miner.SealingEpochRange(stats.Epoch)
+		res := &SealingStats{
+			Epoch:           hexutil.Uint64(stats.Epoch),
+			FirstBlock:      hexutil.Uint64(first),
+			LastBlock:       hexutil.Uint64(last),
+			Blocks:          hexutil.Uint64(stats.Blocks),
+			Uncles:          hexutil.Uint64(stats.Uncles),
+			SideBlocks:      hexutil.Uint64(stats.Sides),
+			UncleRate:       stats.UncleRate(),
+			OrphanRate:      stats.OrphanRate(),
+			Mined:           hexutil.Uint64(stats.Mined),
+			Canonical:       hexutil.Uint64(stats.Canonical),
+			Included:        hexutil.Uint64(stats.Included),
+			Lost:            hexutil.Uint64(stats.Lost),
+			Reorged:         hexutil.Uint64(stats.Reorged),
+			SealedUncleRate: stats.SealedUncleRate(),
+			SealedLostRate:  stats.SealedLostRate(),
+			ReorgDepths:     make(map[string]hexutil.Uint64),
+		}
+		for i, count := range stats.Reorgs {
+			if count == 0 {
+				continue
+			}
+			depth := fmt.Sprint(i + 1)
+			if i == len(stats.Reorgs)-1 {
+				depth += "+"
+			}
+			res.ReorgDepths[depth] = hexutil.Uint64(count)
+		}
+		result = append(result, res)
+	}
+	return result, nil
+}
+
+// PayoutShareArgs is a recipient of a weighted share of the etherbase income.
+type PayoutShareArgs struct {
+	Address common.Address      `json:"address"`
+	Weight  math.HexOrDecimal64 `json:"weight"`
+}
+
+// PayoutSplit is the configured payout split and the amounts owed.
+type PayoutSplit struct {
+	Etherbase common.Address                  `json:"etherbase"`
+	Shares    []PayoutShareArgs               `json:"shares"`
+	MinPayout *hexutil.Big                    `json:"minPayout"`
+	Owed      map[common.Address]*hexutil.Big `json:"owed"`
+}
+
+// SetPayoutSplit splits the income of the etherbase among the given weighted
+// recipients. The miner pays the recipients their share with transfers from
+// the etherbase, which must be an unlocked account of the node. Amounts below
+// minPayout accumulate until they reach it. An empty share list disables the
+// split. The owed amounts are persisted per etherbase, a split set again after
+// a restart or an etherbase change resumes paying them.
+func (api *MinerAPI) SetPayoutSplit(shares []PayoutShareArgs, minPayout *hexutil.Big) (bool, error) {
+	var signFn miner.PayoutSignerFn
+	if len(shares) > 0 {
+		etherbase, err := api.e.Etherbase()
+		if err != nil {
+			return false, err
+		}
+		account := accounts.Account{Address: etherbase}
+		wallet, err := api.e.AccountManager().Find(account)
+		if wallet == nil || err != nil {
+			return false, fmt.Errorf("etherbase account unavailable locally: %v", err)
+		}
+		signFn = func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+			return wallet.SignTx(account, tx, chainID)
+		}
+	}
+	split := make([]miner.PayoutShare, len(shares))
+	for i, share := range shares {
+		split[i] = miner.PayoutShare{Address: share.Address, Weight: uint64(share.Weight)}
+	}
+	if err := api.e.Miner().SetPayoutSplit(split, (*big.Int)(minPayout), signFn); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetPayoutSplit returns the configured payout split and the amounts owed to
+// the recipients.
+func (api *MinerAPI) GetPayoutSplit() *PayoutSplit {
+	status := api.e.Miner().PayoutSplit()
+	split := &PayoutSplit{
+		Etherbase: status.Etherbase,
+		Shares:    make([]PayoutShareArgs, len(status.Shares)),
+		MinPayout: (*hexutil.Big)(status.MinPayout),
+		Owed:      make(map[common.Address]*hexutil.Big, len(status.Owed)),
+	}
+	for i, share := range status.Shares {
+		split.Shares[i] = PayoutShareArgs{Address: share.Address, Weight: math.HexOrDecimal64(share.Weight)}
+	}
+	for addr, amount := range status.Owed {
+		split.Owed[addr] = (*hexutil.Big)(amount)
+	}
+	return split
+}
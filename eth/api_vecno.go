@@ -0,0 +1,122 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/mutations"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// VecnoAPI provides reward and issuance accounting of the chain.
+type VecnoAPI struct {
+	eth *Ethereum
+}
+
+// NewVecnoAPI creates a new instance of VecnoAPI.
+func NewVecnoAPI(eth *Ethereum) *VecnoAPI {
+	return &VecnoAPI{eth: eth}
+}
+
+// RewardCredit is a single balance credit made when finalizing a block.
+type RewardCredit struct {
+	Recipient common.Address       `json:"recipient"`
+	Amount    *hexutil.Big         `json:"amount"`
+	Kind      mutations.RewardKind `json:"kind"`
+}
+
+// BlockRewards is the reward and issuance accounting of a single block.
+type BlockRewards struct {
+	Number      hexutil.Uint64 `json:"number"`
+	Hash        common.Hash    `json:"hash"`
+	BlockReward *hexutil.Big   `json:"blockReward"` // Static block reward of the block's height
+	Rewards     []RewardCredit `json:"rewards"`     // Miner, uncle and treasury credits
+	Fees        *hexutil.Big   `json:"fees"`        // Transaction fees paid by the block's transactions
+	Issuance    *hexutil.Big   `json:"issuance"`    // New coins issued by the block
+	Supply      *hexutil.Big   `json:"supply"`      // Total issued supply, including the genesis allocation; nil if not indexed yet
+}
+
+// GetBlockRewards returns the rewards credited by the given block, the fees
+// included in it and the total supply issued up to and including it.
+func (api *VecnoAPI) GetBlockRewards(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*BlockRewards, error) {
+	var (
+		block    *types.Block
+		receipts types.Receipts
+		err      error
+	)
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		block, receipts = api.eth.APIBackend.PendingBlockAndReceipts()
+	} else {
+		block, err = api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			receipts, err = api.eth.APIBackend.GetReceipts(ctx, block.Hash())
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if block == nil {
+		return nil, nil
+	}
+	var (
+		config  = api.eth.blockchain.Config()
+		rewards = core.BlockRewards(config, block.Header(), block.Uncles(), block.Transactions())
+		result  = &BlockRewards{
+			Number:      hexutil.Uint64(block.NumberU64()),
+			Hash:        block.Hash(),
+			BlockReward: (*hexutil.Big)(ctypes.EthashBlockReward(config, block.Number())),
+			Rewards:     make([]RewardCredit, 0, len(rewards)),
+		}
+		issuance = new(big.Int)
+		fees     = new(big.Int)
+	)
+	for _, reward := range rewards {
+		result.Rewards = append(result.Rewards, RewardCredit{
+			Recipient: reward.Recipient,
+			Amount:    (*hexutil.Big)(reward.Amount),
+			Kind:      reward.Kind,
+		})
+		issuance.Add(issuance, reward.Amount)
+	}
+	for _, receipt := range receipts {
+		if receipt.EffectiveGasPrice != nil {
+			fees.Add(fees, new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed)))
+		}
+	}
+	result.Fees = (*hexutil.Big)(fees)
+	result.Issuance = (*hexutil.Big)(issuance)
+
+	// Blocks not yet written (pending) are accounted on top of their parent
+	supply := api.eth.blockchain.GetIssuedSupply(block.Hash(), block.NumberU64())
+	if supply == nil && block.NumberU64() > 0 {
+		if parent := api.eth.blockchain.GetIssuedSupply(block.ParentHash(), block.NumberU64()-1); parent != nil && api.eth.blockchain.GetHeader(block.Hash(), block.NumberU64()) == nil {
+			supply = new(big.Int).Add(parent, issuance)
+		}
+	}
+	result.Supply = (*hexutil.Big)(supply)
+	return result, nil
+}
@@ -221,6 +221,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
 			StateScheme:         scheme,
+			SupplyIndex:         config.SupplyIndex,
 		}
 	)
 	// Override the chain config with provided settings.
@@ -265,6 +266,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	config.TxPool.Database = chainDb
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
 	eth.txPool, err = txpool.New(new(big.Int).SetUint64(config.TxPool.PriceLimit), eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
@@ -323,6 +325,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.p2pServer, networkID)
 
+	// Cache the results of the historical queries if requested
+	if config.RPCCacheDepth > 0 && config.RPCCacheSize > 0 {
+		cache := ethapi.NewResponseCache(eth.APIBackend, config.RPCCacheDepth, config.RPCCacheSize*1024*1024)
+		stack.SetResponseCache(cache)
+		stack.RegisterLifecycle(cache)
+	}
+
 	// Register the backend on the node
 	stack.RegisterAPIs(eth.APIs())
 	stack.RegisterProtocols(eth.Protocols())
@@ -364,6 +373,9 @@ func (s *Ethereum) APIs() []rpc.API {
 		{
 			Namespace: "eth",
 			Service:   NewEthereumAPI(s),
+		}, {
+			Namespace: "eth",
+			Service:   NewBundleAPI(s),
 		}, {
 			Namespace: "miner",
 			Service:   NewMinerAPI(s),
@@ -376,6 +388,9 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "debug",
 			Service:   NewDebugAPI(s),
+		}, {
+			Namespace: "vecno",
+			Service:   NewVecnoAPI(s),
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
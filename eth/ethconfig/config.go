@@ -86,6 +86,7 @@ var Defaults = Config{
 		DatasetsInMem:    1,
 		DatasetsOnDisk:   2,
 		DatasetsLockMmap: false,
+		Stratum:          ethashb3.DefaultStratumConfig,
 	},
 
 	NetworkId:          0, // enable auto configuration of networkID == chainID
@@ -108,6 +109,7 @@ var Defaults = Config{
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
 	RPCTxFeeCap:        1, // 1 ether
+	RPCCacheSize:       64,
 }
 
 func init() {
@@ -160,6 +162,7 @@ type Config struct {
 	TxLookupLimit      uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	SupplyIndex        bool   `toml:",omitempty"` // Whether to index the total issued supply of the canonical blocks
 
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
@@ -239,6 +242,13 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RPCCacheDepth is the number of confirmations of a block before the results
+	// of the historical queries about it are cached, 0 to disable the cache.
+	RPCCacheDepth uint64
+
+	// RPCCacheSize is the size of the RPC response cache in megabytes.
+	RPCCacheSize int
+
 	// Checkpoint is a hardcoded checkpoint which can be nil.
 	Checkpoint *ctypes.TrustedCheckpoint `toml:",omitempty"`
 
@@ -359,6 +369,7 @@ func CreateConsensusEngineEthashB3(stack *node.Node, ethashb3Config *ethashb3.Co
 			DatasetsOnDisk:   ethashb3Config.DatasetsOnDisk,
 			DatasetsLockMmap: ethashb3Config.DatasetsLockMmap,
 			NotifyFull:       ethashb3Config.NotifyFull,
+			Stratum:          ethashb3Config.Stratum,
 		}, notify, noverify)
 		engine.(*ethashb3.EthashB3).SetThreads(-1) // Disable CPU mining
 	}
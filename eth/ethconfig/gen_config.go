@@ -31,6 +31,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxLookupLimit              uint64                 `toml:",omitempty"`
 		TransactionHistory         uint64                 `toml:",omitempty"`
 		StateHistory               uint64                 `toml:",omitempty"`
+		SupplyIndex                bool                   `toml:",omitempty"`
 		StateScheme                string                 `toml:",omitempty"`
 		RequiredBlocks             map[uint64]common.Hash `toml:"-"`
 		LightServ                  int                    `toml:",omitempty"`
@@ -67,6 +68,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCGasCap                  uint64
 		RPCEVMTimeout              time.Duration
 		RPCTxFeeCap                float64
+		RPCCacheDepth              uint64
+		RPCCacheSize               int
 		Checkpoint                 *ctypes.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle           *ctypes.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideECBP1100           *uint64                        `toml:",omitempty"`
@@ -88,6 +91,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.TransactionHistory = c.TransactionHistory
 	enc.StateHistory = c.StateHistory
+	enc.SupplyIndex = c.SupplyIndex
 	enc.StateScheme = c.StateScheme
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
@@ -124,6 +128,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCCacheDepth = c.RPCCacheDepth
+	enc.RPCCacheSize = c.RPCCacheSize
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
 	enc.OverrideECBP1100 = c.OverrideECBP1100
@@ -149,6 +155,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxLookupLimit              *uint64                `toml:",omitempty"`
 		TransactionHistory         *uint64                `toml:",omitempty"`
 		StateHistory               *uint64                `toml:",omitempty"`
+		SupplyIndex                *bool                  `toml:",omitempty"`
 		StateScheme                *string                `toml:",omitempty"`
 		RequiredBlocks             map[uint64]common.Hash `toml:"-"`
 		LightServ                  *int                   `toml:",omitempty"`
@@ -185,6 +192,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCGasCap                  *uint64
 		RPCEVMTimeout              *time.Duration
 		RPCTxFeeCap                *float64
+		RPCCacheDepth              *uint64
+		RPCCacheSize               *int
 		Checkpoint                 *ctypes.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle           *ctypes.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideECBP1100           *uint64                        `toml:",omitempty"`
@@ -231,6 +240,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.StateHistory != nil {
 		c.StateHistory = *dec.StateHistory
 	}
+	if dec.SupplyIndex != nil {
+		c.SupplyIndex = *dec.SupplyIndex
+	}
 	if dec.StateScheme != nil {
 		c.StateScheme = *dec.StateScheme
 	}
@@ -339,6 +351,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCCacheDepth != nil {
+		c.RPCCacheDepth = *dec.RPCCacheDepth
+	}
+	if dec.RPCCacheSize != nil {
+		c.RPCCacheSize = *dec.RPCCacheSize
+	}
 	if dec.Checkpoint != nil {
 		c.Checkpoint = dec.Checkpoint
 	}
@@ -28,9 +28,11 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/exp/slices"
 )
 
 var (
@@ -188,6 +190,96 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 	return rpcSub, nil
 }
 
+// TxLifecycleCriteria selects the transaction lifecycle events to be notified.
+// Empty criteria match all events.
+type TxLifecycleCriteria struct {
+	Hashes  []common.Hash           `json:"hashes"`
+	From    []common.Address        `json:"from"`
+	Stages  []txpool.LifecycleStage `json:"stages"`
+	Reasons []txpool.DropReason     `json:"reasons"`
+}
+
+// matches returns whether the lifecycle event satisfies the criteria.
+func (crit *TxLifecycleCriteria) matches(ev *txpool.LifecycleEvent) bool {
+	if crit == nil {
+		return true
+	}
+	if len(crit.Hashes) > 0 && !slices.Contains(crit.Hashes, ev.Hash) {
+		return false
+	}
+	if len(crit.From) > 0 && !slices.Contains(crit.From, ev.From) {
+		return false
+	}
+	if len(crit.Stages) > 0 && !slices.Contains(crit.Stages, ev.Stage) {
+		return false
+	}
+	if len(crit.Reasons) > 0 && !slices.Contains(crit.Reasons, ev.Reason) {
+		return false
+	}
+	return true
+}
+
+// RPCTxLifecycleEvent is a transaction lifecycle event, as notified to txLifecycle
+// subscribers.
+type RPCTxLifecycleEvent struct {
+	Hash       common.Hash           `json:"hash"`
+	From       common.Address        `json:"from"`
+	Nonce      hexutil.Uint64        `json:"nonce"`
+	Stage      txpool.LifecycleStage `json:"stage"`
+	Reason     txpool.DropReason     `json:"reason,omitempty"`
+	ReplacedBy *common.Hash          `json:"replacedBy,omitempty"`
+	Time       hexutil.Uint64        `json:"time"`
+}
+
+// TxLifecycle creates a subscription that is triggered each time a transaction
+// is added to, promoted, demoted, replaced or dropped from the transaction pool,
+// or leaves it after being included, along with the reason of the change.
+func (api *FilterAPI) TxLifecycle(ctx context.Context, crit *TxLifecycleCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan []*txpool.LifecycleEvent, 128)
+		lifecycleSub := api.events.SubscribeTxLifecycle(events)
+
+		for {
+			select {
+			case events := <-events:
+				for _, ev := range events {
+					if !crit.matches(ev) {
+						continue
+					}
+					rpcEvent := &RPCTxLifecycleEvent{
+						Hash:   ev.Hash,
+						From:   ev.From,
+						Nonce:  hexutil.Uint64(ev.Nonce),
+						Stage:  ev.Stage,
+						Reason: ev.Reason,
+						Time:   hexutil.Uint64(ev.Time),
+					}
+					if ev.ReplacedBy != (common.Hash{}) {
+						replacedBy := ev.ReplacedBy
+						rpcEvent.ReplacedBy = &replacedBy
+					}
+					notifier.Notify(rpcSub.ID, rpcEvent)
+				}
+			case <-rpcSub.Err():
+				lifecycleSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				lifecycleSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 func (api *FilterAPI) NewBlockFilter() rpc.ID {
@@ -315,30 +407,84 @@ func (api *FilterAPI) NewSideHeads(ctx context.Context) (*rpc.Subscription, erro
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+//
+// If a fromBlock is given, the matching logs of the canonical chain since that
+// block are replayed first. If the cursor of a log received by a previous
+// subscription is given, the subscription resumes right after that log: the
+// logs delivered since are removed again if their blocks were reorged, and the
+// logs which were missed are replayed. Every log is sent with the cursor to
+// resume after it. If the replay fails, the subscription is ended with a last
+// LogsEnd notification holding the error and the cursor to resume from.
+func (api *FilterAPI) Logs(ctx context.Context, crit LogsCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
+	replay, err := newLogsReplay(ctx, api.sys, crit)
+	if err != nil {
+		return nil, err
+	}
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
 	)
 
-	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), matchedLogs)
+	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit.FilterCriteria), matchedLogs)
 	if err != nil {
 		return nil, err
 	}
 
 	go func() {
+		var (
+			cursors  logCursorTracker
+			replayCh <-chan []*types.Log
+			buffered [][]*types.Log // live logs received during the replay
+			pending  int            // number of buffered live logs
+		)
+		notify := func(logs []*types.Log) {
+			for _, log := range logs {
+				notifier.Notify(rpcSub.ID, &cursorLog{log: log, cursor: cursors.next(log)})
+			}
+		}
+		fail := func(err error) {
+			notifier.Notify(rpcSub.ID, &LogsEnd{Error: err.Error(), Cursor: cursors.last()})
+			logsSub.Unsubscribe()
+		}
+		if crit.Cursor != nil {
+			cursors.cursor, cursors.set = *crit.Cursor, true
+		}
+		if replay != nil {
+			replayCtx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			replayCh = replay.run(replayCtx)
+		}
 		for {
 			select {
+			case logs, ok := <-replayCh:
+				if ok {
+					notify(logs)
+					continue
+				}
+				if replay.err != nil {
+					fail(replay.err)
+					return
+				}
+				// Replay done, switch over to the live logs
+				replayCh = nil
+				for _, logs := range buffered {
+					notify(replay.live(logs))
+				}
+				buffered = nil
 			case logs := <-matchedLogs:
-				for _, log := range logs {
-					log := log
-					notifier.Notify(rpcSub.ID, &log)
+				if replayCh != nil {
+					if pending += len(logs); pending > maxReplayBuffered {
+						fail(errReplayOverflow)
+						return
+					}
+					buffered = append(buffered, logs)
+					continue
 				}
+				notify(replay.live(logs))
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				logsSub.Unsubscribe()
 				return
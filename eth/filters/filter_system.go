@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
@@ -72,6 +73,7 @@ type Backend interface {
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeTxLifecycleEvent(ch chan<- []*txpool.LifecycleEvent) event.Subscription
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
@@ -164,6 +166,9 @@ const (
 	BlocksSubscription
 	// SideBlocksSubscription queries blocks that are imported non-canonically
 	SideBlocksSubscription
+	// TxLifecycleSubscription queries for transactions entering, moving within
+	// or leaving the transaction pool
+	TxLifecycleSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -178,6 +183,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// lifecycleChanSize is the size of channel listening to transaction lifecycle events.
+	lifecycleChanSize = 128
 )
 
 type subscription struct {
@@ -188,6 +195,7 @@ type subscription struct {
 	logs      chan []*types.Log
 	txs       chan []*types.Transaction
 	headers   chan *types.Header
+	lifecycle chan []*txpool.LifecycleEvent
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -207,16 +215,18 @@ type EventSystem struct {
 	pendingLogsSub event.Subscription // Subscription for pending log event
 	chainSub       event.Subscription // Subscription for new chain event
 	chainSideSub   event.Subscription // Subscription for new side chain event
+	lifecycleSub   event.Subscription // Subscription for transaction lifecycle event
 
 	// Channels
-	install       chan *subscription         // install filter for event notification
-	uninstall     chan *subscription         // remove filter for event notification
-	txsCh         chan core.NewTxsEvent      // Channel to receive new transactions event
-	logsCh        chan []*types.Log          // Channel to receive new log event
-	pendingLogsCh chan []*types.Log          // Channel to receive new log event
-	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh       chan core.ChainEvent       // Channel to receive new chain event
-	chainSideCh   chan core.ChainSideEvent   // Channel to receive new side chain event
+	install       chan *subscription            // install filter for event notification
+	uninstall     chan *subscription            // remove filter for event notification
+	txsCh         chan core.NewTxsEvent         // Channel to receive new transactions event
+	logsCh        chan []*types.Log             // Channel to receive new log event
+	pendingLogsCh chan []*types.Log             // Channel to receive new log event
+	rmLogsCh      chan core.RemovedLogsEvent    // Channel to receive removed log event
+	chainCh       chan core.ChainEvent          // Channel to receive new chain event
+	chainSideCh   chan core.ChainSideEvent      // Channel to receive new side chain event
+	lifecycleCh   chan []*txpool.LifecycleEvent // Channel to receive transaction lifecycle event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -238,6 +248,7 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
 		chainCh:       make(chan core.ChainEvent, chainEvChanSize),
 		chainSideCh:   make(chan core.ChainSideEvent, chainEvChanSize),
+		lifecycleCh:   make(chan []*txpool.LifecycleEvent, lifecycleChanSize),
 	}
 
 	// Subscribe events
@@ -247,9 +258,10 @@ func NewEventSystem(sys *FilterSystem, lightMode bool) *EventSystem {
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.chainSideSub = m.backend.SubscribeChainSideEvent(m.chainSideCh)
 	m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
+	m.lifecycleSub = m.backend.SubscribeTxLifecycleEvent(m.lifecycleCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.chainSideSub == nil || m.pendingLogsSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.chainSideSub == nil || m.pendingLogsSub == nil || m.lifecycleSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -285,6 +297,7 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.logs:
 			case <-sub.f.txs:
 			case <-sub.f.headers:
+			case <-sub.f.lifecycle:
 			}
 		}
 
@@ -443,6 +456,23 @@ func (es *EventSystem) SubscribePendingTxs(txs chan []*types.Transaction) *Subsc
 	return es.subscribe(sub)
 }
 
+// SubscribeTxLifecycle creates a subscription that writes the lifecycle events of
+// the transactions entering, moving within or leaving the transaction pool.
+func (es *EventSystem) SubscribeTxLifecycle(events chan []*txpool.LifecycleEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       TxLifecycleSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		headers:   make(chan *types.Header),
+		lifecycle: events,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 func (es *EventSystem) handleLogs(filters filterIndex, ev []*types.Log) {
@@ -475,6 +505,12 @@ func (es *EventSystem) handleTxsEvent(filters filterIndex, ev core.NewTxsEvent)
 	}
 }
 
+func (es *EventSystem) handleTxLifecycleEvent(filters filterIndex, ev []*txpool.LifecycleEvent) {
+	for _, f := range filters[TxLifecycleSubscription] {
+		f.lifecycle <- ev
+	}
+}
+
 func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent) {
 	for _, f := range filters[BlocksSubscription] {
 		f.headers <- ev.Block.Header()
@@ -599,6 +635,7 @@ func (es *EventSystem) eventLoop() {
 		es.pendingLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
 		es.chainSideSub.Unsubscribe()
+		es.lifecycleSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -620,6 +657,8 @@ func (es *EventSystem) eventLoop() {
 			es.handleChainEvent(index, ev)
 		case ev := <-es.chainSideCh:
 			es.handleChainSideEvent(index, ev)
+		case ev := <-es.lifecycleCh:
+			es.handleTxLifecycleEvent(index, ev)
 
 		case f := <-es.install:
 			if f.typ == MinedAndPendingLogsSubscription {
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -55,6 +56,7 @@ type testBackend struct {
 	pendingLogsFeed event.Feed
 	chainFeed       event.Feed
 	chainSideFeed   event.Feed
+	lifecycleFeed   event.Feed
 	pendingBlock    *types.Block
 	pendingReceipts types.Receipts
 }
@@ -150,6 +152,10 @@ func (b *testBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Su
 	return b.pendingLogsFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeTxLifecycleEvent(ch chan<- []*txpool.LifecycleEvent) event.Subscription {
+	return b.lifecycleFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.chainFeed.Subscribe(ch)
 }
@@ -311,6 +317,67 @@ func TestSideBlockSubscription(t *testing.T) {
 	<-sub1.Err()
 }
 
+// TestTxLifecycleSubscription tests whether the transaction lifecycle events posted
+// by the pool reach the subscribers, and whether the criteria select among them.
+func TestTxLifecycleSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys, false)
+
+		sender = common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268")
+		events = []*txpool.LifecycleEvent{
+			{Hash: common.Hash{0x01}, From: sender, Nonce: 0, Stage: txpool.StageAdded},
+			{Hash: common.Hash{0x01}, From: sender, Nonce: 0, Stage: txpool.StagePromoted},
+			{Hash: common.Hash{0x02}, From: common.Address{0x01}, Nonce: 0, Stage: txpool.StageDropped, Reason: txpool.DropUnderpriced},
+			{Hash: common.Hash{0x01}, From: sender, Nonce: 0, Stage: txpool.StageReplaced, Reason: txpool.DropReplaced, ReplacedBy: common.Hash{0x03}},
+			{Hash: common.Hash{0x03}, From: sender, Nonce: 0, Stage: txpool.StageIncluded, Reason: txpool.DropIncluded},
+		}
+	)
+	ch := make(chan []*txpool.LifecycleEvent)
+	sub := api.events.SubscribeTxLifecycle(ch)
+
+	time.Sleep(1 * time.Second)
+	go backend.lifecycleFeed.Send(events)
+
+	select {
+	case have := <-ch:
+		if len(have) != len(events) {
+			t.Fatalf("event count mismatch: have %d, want %d", len(have), len(events))
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("lifecycle events not delivered")
+	}
+	sub.Unsubscribe()
+
+	// Ensure the criteria select the requested events only
+	tests := []struct {
+		crit *TxLifecycleCriteria
+		want int
+	}{
+		{nil, 5},
+		{&TxLifecycleCriteria{}, 5},
+		{&TxLifecycleCriteria{Hashes: []common.Hash{{0x01}}}, 3},
+		{&TxLifecycleCriteria{From: []common.Address{sender}}, 4},
+		{&TxLifecycleCriteria{Stages: []txpool.LifecycleStage{txpool.StageDropped, txpool.StageReplaced}}, 2},
+		{&TxLifecycleCriteria{Reasons: []txpool.DropReason{txpool.DropUnderpriced}}, 1},
+		{&TxLifecycleCriteria{From: []common.Address{sender}, Reasons: []txpool.DropReason{txpool.DropUnderpriced}}, 0},
+	}
+	for i, tt := range tests {
+		var have int
+		for _, ev := range events {
+			if tt.crit.matches(ev) {
+				have++
+			}
+		}
+		if have != tt.want {
+			t.Errorf("test %d: matched event count mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
+
 // TestPendingTxFilter tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilter(t *testing.T) {
 	t.Parallel()
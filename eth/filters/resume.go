@@ -0,0 +1,363 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// replayChunkSize is the number of blocks of which the logs are replayed at
+	// once when resuming a logs subscription.
+	replayChunkSize = 4096
+
+	// replayDedupDepth is the number of blocks below the head at which the
+	// replay starts, of which the replayed logs are tracked to drop them from
+	// the live logs. Only blocks imported while the replay starts are both
+	// replayed and delivered live.
+	replayDedupDepth = 4096
+
+	// maxReplayBuffered is the maximum number of live logs buffered while the
+	// replay is running, the subscription fails if exceeded.
+	maxReplayBuffered = 10000
+)
+
+// logCursorLength is the length of an encoded log cursor: the block number,
+// the block hash and the log index.
+const logCursorLength = 8 + common.HashLength + 8
+
+var (
+	errUnknownCursorBlock = errors.New("unknown cursor block")
+	errReplayOverflow     = errors.New("too many new logs while replaying, resume from the last cursor")
+	errCursorAndRange     = errors.New("cannot specify both cursor and fromBlock/blockHash, choose one or the other")
+)
+
+// LogCursor is the position of a logs subscription in the chain. The matching
+// logs of the chain ending with the cursor block were delivered, up to the log
+// index in the cursor block, exclusive.
+type LogCursor struct {
+	Number uint64
+	Hash   common.Hash
+	Index  uint
+}
+
+// MarshalText encodes the cursor as an opaque hex string.
+func (c LogCursor) MarshalText() ([]byte, error) {
+	enc := make([]byte, logCursorLength)
+	binary.BigEndian.PutUint64(enc, c.Number)
+	copy(enc[8:], c.Hash[:])
+	binary.BigEndian.PutUint64(enc[8+common.HashLength:], uint64(c.Index))
+	return hexutil.Bytes(enc).MarshalText()
+}
+
+// UnmarshalText decodes a cursor encoded by MarshalText.
+func (c *LogCursor) UnmarshalText(input []byte) error {
+	var enc hexutil.Bytes
+	if err := enc.UnmarshalText(input); err != nil {
+		return err
+	}
+	if len(enc) != logCursorLength {
+		return fmt.Errorf("invalid log cursor length %d", len(enc))
+	}
+	c.Number = binary.BigEndian.Uint64(enc)
+	c.Hash = common.BytesToHash(enc[8 : 8+common.HashLength])
+	c.Index = uint(binary.BigEndian.Uint64(enc[8+common.HashLength:]))
+	return nil
+}
+
+// LogsCriteria are the criteria of a logs subscription. Besides the filter
+// criteria, it may hold the cursor of the last log received by a previous
+// subscription, to resume after it.
+type LogsCriteria struct {
+	FilterCriteria
+	Cursor *LogCursor
+}
+
+// UnmarshalJSON sets *args fields with given data.
+func (args *LogsCriteria) UnmarshalJSON(data []byte) error {
+	if err := args.FilterCriteria.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	var raw struct {
+		Cursor *LogCursor `json:"cursor"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Cursor != nil && (args.FromBlock != nil || args.BlockHash != nil) {
+		return errCursorAndRange
+	}
+	args.Cursor = raw.Cursor
+	return nil
+}
+
+// cursorLog is a log notified by a logs subscription, along with the cursor to
+// resume the subscription after it.
+type cursorLog struct {
+	log    *types.Log
+	cursor LogCursor
+}
+
+// MarshalJSON encodes the log with an additional cursor field.
+func (l *cursorLog) MarshalJSON() ([]byte, error) {
+	enc, err := json.Marshal(l.log)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := json.Marshal(l.cursor)
+	if err != nil {
+		return nil, err
+	}
+	enc = append(enc[:len(enc)-1], `,"cursor":`...)
+	enc = append(enc, cursor...)
+	return append(enc, '}'), nil
+}
+
+// LogsEnd is the last notification of a logs subscription ended by the node,
+// when it can't deliver the logs in order anymore, e.g. because the replay of
+// the missed logs failed. It carries the error and the cursor of the last log
+// delivered, if any, from which a new subscription resumes. No notification
+// follows it, and the client should unsubscribe.
+type LogsEnd struct {
+	Error  string     `json:"error"`
+	Cursor *LogCursor `json:"cursor,omitempty"`
+}
+
+// logCursorTracker computes the cursors of the logs notified in order. Removed
+// logs move the cursor back to the oldest removed position, until a new log is
+// delivered.
+type logCursorTracker struct {
+	cursor   LogCursor
+	removing bool
+	set      bool // Whether the cursor was set by a log or the resumed cursor
+}
+
+// last returns the cursor of the last delivered log, nil if none was.
+func (t *logCursorTracker) last() *LogCursor {
+	if !t.set {
+		return nil
+	}
+	cursor := t.cursor
+	return &cursor
+}
+
+// next returns the cursor after the given log is delivered.
+func (t *logCursorTracker) next(log *types.Log) LogCursor {
+	t.set = true
+	if !log.Removed {
+		t.cursor = LogCursor{Number: log.BlockNumber, Hash: log.BlockHash, Index: log.Index + 1}
+		t.removing = false
+		return t.cursor
+	}
+	pos := LogCursor{Number: log.BlockNumber, Hash: log.BlockHash, Index: log.Index}
+	if !t.removing || pos.Number < t.cursor.Number || (pos.Number == t.cursor.Number && pos.Index < t.cursor.Index) {
+		t.cursor = pos
+	}
+	t.removing = true
+	return t.cursor
+}
+
+// logsReplay replays the historical logs of a logs subscription, before it
+// switches to the live logs.
+type logsReplay struct {
+	sys    *FilterSystem
+	crit   FilterCriteria
+	cursor *LogCursor
+	from   uint64 // First canonical block replayed if not resuming a cursor
+
+	head     uint64                   // Chain head when the replay started
+	replayed map[common.Hash]struct{} // Recent blocks of which the logs were replayed
+	err      error                    // Error the replay failed with, set once it's done
+}
+
+// newLogsReplay creates the replay of the historical logs of a subscription,
+// or returns nil if the subscription only delivers live logs.
+func newLogsReplay(ctx context.Context, sys *FilterSystem, crit LogsCriteria) (*logsReplay, error) {
+	replay := &logsReplay{
+		sys:      sys,
+		crit:     crit.FilterCriteria,
+		cursor:   crit.Cursor,
+		replayed: make(map[common.Hash]struct{}),
+	}
+	switch {
+	case crit.Cursor != nil:
+		header, err := sys.backend.HeaderByHash(ctx, crit.Cursor.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil || header.Number.Uint64() != crit.Cursor.Number {
+			return nil, errUnknownCursorBlock
+		}
+	case crit.FromBlock != nil && crit.FromBlock.Sign() >= 0:
+		replay.from = crit.FromBlock.Uint64()
+	default:
+		return nil, nil
+	}
+	return replay, nil
+}
+
+// run starts replaying the logs and returns the channel they are delivered
+// on, closed once the replay is done. If the replay failed, the error is set
+// when the channel is closed.
+func (r *logsReplay) run(ctx context.Context) <-chan []*types.Log {
+	ch := make(chan []*types.Log)
+	go func() {
+		defer close(ch)
+
+		send := func(logs []*types.Log) bool {
+			select {
+			case ch <- logs:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if err := r.replay(ctx, send); err != nil && !errors.Is(err, context.Canceled) {
+			r.err = err
+		}
+	}()
+	return ch
+}
+
+// replay delivers the logs of the branch removed since the cursor if it was
+// reorged, and the matching logs of the canonical chain up to the head.
+func (r *logsReplay) replay(ctx context.Context, send func([]*types.Log) bool) error {
+	r.head = r.sys.backend.CurrentHeader().Number.Uint64()
+
+	var (
+		from = r.from
+		skip *LogCursor // Canonical cursor, the logs before it were delivered
+	)
+	if r.cursor != nil {
+		canon, err := r.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(r.cursor.Number))
+		if err != nil {
+			return err
+		}
+		if canon != nil && canon.Hash() == r.cursor.Hash {
+			from, skip = r.cursor.Number, r.cursor
+		} else if from, err = r.rewind(ctx, send); err != nil {
+			return err
+		}
+	}
+	end := r.head
+	if r.crit.ToBlock != nil && r.crit.ToBlock.Sign() >= 0 && r.crit.ToBlock.Uint64() < end {
+		end = r.crit.ToBlock.Uint64()
+	}
+	for begin := from; begin <= end; begin += replayChunkSize {
+		last := begin + replayChunkSize - 1
+		if last > end {
+			last = end
+		}
+		logs, err := r.sys.NewRangeFilter(int64(begin), int64(last), r.crit.Addresses, r.crit.Topics).Logs(ctx)
+		if err != nil {
+			return err
+		}
+		var matched []*types.Log
+		for _, log := range logs {
+			if skip != nil && log.BlockNumber == skip.Number && log.Index < skip.Index {
+				continue
+			}
+			if log.BlockNumber+replayDedupDepth > r.head {
+				r.replayed[log.BlockHash] = struct{}{}
+			}
+			matched = append(matched, log)
+		}
+		if len(matched) > 0 && !send(matched) {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// rewind delivers as removed the logs of the reorged branch the cursor block
+// is on, and returns the first block after the common ancestor of the branch
+// with the canonical chain.
+func (r *logsReplay) rewind(ctx context.Context, send func([]*types.Log) bool) (uint64, error) {
+	var branch []*types.Header
+	header, err := r.sys.backend.HeaderByHash(ctx, r.cursor.Hash)
+	for ; header != nil && err == nil; header, err = r.sys.backend.HeaderByHash(ctx, header.ParentHash) {
+		canon, err := r.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Uint64()))
+		if err != nil {
+			return 0, err
+		}
+		if canon != nil && canon.Hash() == header.Hash() {
+			break
+		}
+		branch = append(branch, header)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, errUnknownCursorBlock
+	}
+	for i := len(branch) - 1; i >= 0; i-- {
+		hash := branch[i].Hash()
+		logs, err := r.sys.NewBlockFilter(hash, r.crit.Addresses, r.crit.Topics).Logs(ctx)
+		if err != nil {
+			return 0, err
+		}
+		var removed []*types.Log
+		for _, log := range logs {
+			if hash == r.cursor.Hash && log.Index >= r.cursor.Index {
+				continue
+			}
+			cpy := *log
+			cpy.Removed = true
+			removed = append(removed, &cpy)
+		}
+		if len(removed) > 0 && !send(removed) {
+			return 0, ctx.Err()
+		}
+	}
+	return header.Number.Uint64() + 1, nil
+}
+
+// live filters out the live logs which were already replayed. It must only be
+// called once the replay is done.
+func (r *logsReplay) live(logs []*types.Log) []*types.Log {
+	if r == nil || r.replayed == nil {
+		return logs
+	}
+	var (
+		result []*types.Log
+		newer  bool
+	)
+	for _, log := range logs {
+		if _, ok := r.replayed[log.BlockHash]; ok && !log.Removed {
+			continue
+		}
+		if log.BlockNumber > r.head {
+			newer = true
+		}
+		result = append(result, log)
+	}
+	// Live logs caught up with the replay, no more duplicates to filter
+	if newer {
+		r.replayed = nil
+	}
+	return result
+}
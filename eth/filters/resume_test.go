@@ -0,0 +1,206 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func TestUnmarshalLogsCriteria(t *testing.T) {
+	t.Parallel()
+
+	cursor := LogCursor{Number: 0x10, Hash: common.HexToHash("0x1234"), Index: 3}
+	enc, err := json.Marshal(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var crit LogsCriteria
+	if err := json.Unmarshal([]byte(fmt.Sprintf(`{"address":"0x0000000000000000000000000000000000000001","cursor":%s}`, enc)), &crit); err != nil {
+		t.Fatal(err)
+	}
+	if crit.Cursor == nil || *crit.Cursor != cursor {
+		t.Fatalf("cursor mismatch: have %v, want %v", crit.Cursor, cursor)
+	}
+	if len(crit.Addresses) != 1 {
+		t.Fatalf("expected 1 address, got %d", len(crit.Addresses))
+	}
+	if err := json.Unmarshal([]byte(fmt.Sprintf(`{"fromBlock":"0x1","cursor":%s}`, enc)), &crit); err != errCursorAndRange {
+		t.Fatalf("expected %v, got %v", errCursorAndRange, err)
+	}
+	if err := json.Unmarshal([]byte(`{"cursor":"0x1234"}`), &crit); err == nil {
+		t.Fatal("expected error for short cursor")
+	}
+}
+
+// Tests that a resumed logs subscription replays the logs after its cursor,
+// and delivers as removed the logs of a cursor branch which was reorged.
+func TestLogsReplay(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{})
+		addr   = common.BytesToAddress([]byte("jeff"))
+		engine = ethash.NewFaker()
+		gspec  = &genesisT.Genesis{
+			BaseFee: big.NewInt(vars.InitialBaseFee),
+			Config:  params.TestChainConfig,
+		}
+		ctx = context.Background()
+	)
+	addLog := func(i int, gen *core.BlockGen) {
+		gen.AddUncheckedReceipt(makeReceipt(addr))
+		gen.AddUncheckedTx(types.NewTransaction(999, common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	}
+	genDb, chain, receipts := core.GenerateChainWithGenesis(gspec, engine, 4, addLog)
+	fork, forkReceipts := core.GenerateChain(gspec.Config, chain[1], engine, genDb, 3, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{0x1})
+		addLog(i, gen)
+	})
+	core.MustCommitGenesis(db, trie.NewDatabase(db, trie.HashDefaults), gspec)
+
+	write := func(blocks []*types.Block, receipts []types.Receipts) {
+		for i, block := range blocks {
+			rawdb.WriteBlock(db, block)
+			rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+			rawdb.WriteHeadBlockHash(db, block.Hash())
+			rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		}
+	}
+	replay := func(crit LogsCriteria) (*logsReplay, []*types.Log) {
+		t.Helper()
+		r, err := newLogsReplay(ctx, sys, crit)
+		if err != nil {
+			t.Fatalf("failed to create replay: %v", err)
+		}
+		var logs []*types.Log
+		if err := r.replay(ctx, func(l []*types.Log) bool { logs = append(logs, l...); return true }); err != nil {
+			t.Fatalf("failed to replay logs: %v", err)
+		}
+		return r, logs
+	}
+	check := func(logs []*types.Log, want []*types.Block, removed int) {
+		t.Helper()
+		if len(logs) != len(want) {
+			t.Fatalf("log count mismatch: have %d, want %d", len(logs), len(want))
+		}
+		for i, log := range logs {
+			if log.BlockHash != want[i].Hash() || log.Removed != (i < removed) {
+				t.Errorf("log %d mismatch: have block %d (removed %v), want block %d", i, log.BlockNumber, log.Removed, want[i].NumberU64())
+			}
+		}
+	}
+	write(chain, receipts)
+
+	// Replay the logs from a block number, and compute the cursor of the third
+	crit := LogsCriteria{FilterCriteria: FilterCriteria{FromBlock: big.NewInt(1), Addresses: []common.Address{addr}}}
+	_, logs := replay(crit)
+	check(logs, chain, 0)
+
+	var tracker logCursorTracker
+	for _, log := range logs[:3] {
+		tracker.next(log)
+	}
+	cursor := tracker.cursor
+	if cursor.Hash != chain[2].Hash() || cursor.Index != 1 {
+		t.Fatalf("cursor mismatch: have %v", cursor)
+	}
+	// Reorg the cursor block away, its log is removed before the new branch
+	write(fork, forkReceipts)
+
+	crit = LogsCriteria{FilterCriteria: FilterCriteria{Addresses: []common.Address{addr}}, Cursor: &cursor}
+	_, logs = replay(crit)
+	check(logs, []*types.Block{chain[2], fork[0], fork[1], fork[2]}, 1)
+
+	if tracker.next(logs[0]); tracker.cursor.Hash != chain[2].Hash() || tracker.cursor.Index != 0 {
+		t.Fatalf("cursor after removal mismatch: have %v", tracker.cursor)
+	}
+	// Resume from a canonical cursor, the live logs already replayed are dropped
+	cursor = LogCursor{Number: fork[1].NumberU64(), Hash: fork[1].Hash(), Index: 0}
+	crit.Cursor = &cursor
+	r, logs := replay(crit)
+	check(logs, fork[1:], 0)
+
+	if live := r.live(logs[1:]); len(live) != 0 {
+		t.Fatalf("expected replayed logs to be dropped, got %d", len(live))
+	}
+	newer := &types.Log{Address: addr, BlockNumber: fork[2].NumberU64() + 1}
+	if live := r.live([]*types.Log{newer}); len(live) != 1 {
+		t.Fatalf("expected new log to be delivered, got %d", len(live))
+	}
+	// A cursor on a branch disconnected from the chain fails the replay
+	orphan := &types.Header{ParentHash: common.Hash{0x1}, Number: big.NewInt(3), Difficulty: big.NewInt(1)}
+	rawdb.WriteHeader(db, orphan)
+	cursor = LogCursor{Number: 3, Hash: orphan.Hash()}
+	crit.Cursor = &cursor
+	r, err := newLogsReplay(ctx, sys, crit)
+	if err != nil {
+		t.Fatalf("failed to create replay: %v", err)
+	}
+	for range r.run(ctx) {
+	}
+	if !errors.Is(r.err, errUnknownCursorBlock) {
+		t.Fatalf("replay error mismatch: have %v, want %v", r.err, errUnknownCursorBlock)
+	}
+	// The subscription ends with a last notification of the error and the cursor
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewFilterAPI(sys, false)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	notifications := make(chan json.RawMessage)
+	sub, err := client.EthSubscribe(ctx, notifications, "logs", map[string]interface{}{"address": addr, "cursor": cursor})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	select {
+	case raw := <-notifications:
+		var end LogsEnd
+		if err := json.Unmarshal(raw, &end); err != nil {
+			t.Fatalf("failed to decode notification: %v", err)
+		}
+		if end.Error != errUnknownCursorBlock.Error() || end.Cursor == nil || *end.Cursor != cursor {
+			t.Fatalf("end notification mismatch: have %s", raw)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("subscription not ended within 1s")
+	}
+}
@@ -52,8 +52,9 @@ type blockFees struct {
 	block       *types.Block // only set if reward percentiles are requested
 	receipts    types.Receipts
 	// filled by processBlock
-	results processedFees
-	err     error
+	results  processedFees
+	volatile bool // results depend on the oracle state, not only on the block
+	err      error
 }
 
 type cacheKey struct {
@@ -97,20 +98,41 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 		return
 	}
 
+	// Without a base fee the rewards are full gas prices. Transactions priced
+	// below the ignore threshold (e.g. miner payouts) are skipped, and rows are
+	// never zero, so that the rewards are always usable legacy gas prices.
+	var (
+		legacy  = oracle.legacy(bf.header.Number)
+		sorter  = make([]txGasAndReward, 0, len(bf.block.Transactions()))
+		gasUsed uint64
+	)
+	for i, tx := range bf.block.Transactions() {
+		reward, _ := tx.EffectiveGasTip(bf.block.BaseFee())
+		if legacy && reward.Cmp(oracle.ignorePrice) < 0 {
+			continue
+		}
+		sorter = append(sorter, txGasAndReward{gasUsed: bf.receipts[i].GasUsed, reward: reward})
+		gasUsed += bf.receipts[i].GasUsed
+	}
 	bf.results.reward = make([]*big.Int, len(percentiles))
-	if len(bf.block.Transactions()) == 0 {
-		// return an all zero row if there are no transactions to gather data from
+	if len(sorter) == 0 {
+		// return an all zero row if there are no transactions to gather data
+		// from, or the last suggested gas price for legacy blocks. The latter
+		// changes over time, so the row must not be cached.
+		fill := new(big.Int)
+		if legacy {
+			oracle.cacheLock.RLock()
+			if oracle.lastPrice != nil {
+				fill.Set(oracle.lastPrice)
+				bf.volatile = true
+			}
+			oracle.cacheLock.RUnlock()
+		}
 		for i := range bf.results.reward {
-			bf.results.reward[i] = new(big.Int)
+			bf.results.reward[i] = new(big.Int).Set(fill)
 		}
 		return
 	}
-
-	sorter := make([]txGasAndReward, len(bf.block.Transactions()))
-	for i, tx := range bf.block.Transactions() {
-		reward, _ := tx.EffectiveGasTip(bf.block.BaseFee())
-		sorter[i] = txGasAndReward{gasUsed: bf.receipts[i].GasUsed, reward: reward}
-	}
 	slices.SortStableFunc(sorter, func(a, b txGasAndReward) int {
 		return a.reward.Cmp(b.reward)
 	})
@@ -119,8 +141,8 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 	sumGasUsed := sorter[0].gasUsed
 
 	for i, p := range percentiles {
-		thresholdGasUsed := uint64(float64(bf.block.GasUsed()) * p / 100)
-		for sumGasUsed < thresholdGasUsed && txIndex < len(bf.block.Transactions())-1 {
+		thresholdGasUsed := uint64(float64(gasUsed) * p / 100)
+		for sumGasUsed < thresholdGasUsed && txIndex < len(sorter)-1 {
 			txIndex++
 			sumGasUsed += sorter[txIndex].gasUsed
 		}
@@ -209,6 +231,9 @@ func (oracle *Oracle) resolveBlockRange(ctx context.Context, reqEnd rpc.BlockNum
 //   - baseFee: base fee per gas in the given block
 //   - gasUsedRatio: gasUsed/gasLimit in the given block
 //
+// Note: on chains without EIP-1559, base fees are zero and the rewards are gas
+// prices, so that baseFee + reward is a usable legacy gas price either way.
+//
 // Note: baseFee includes the next block after the newest of the returned range, because this
 // value can be derived from the newest block.
 func (oracle *Oracle) FeeHistory(ctx context.Context, blocks uint64, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
@@ -283,7 +308,7 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks uint64, unresolvedL
 						}
 						if fees.header != nil && fees.err == nil {
 							oracle.processBlock(fees, rewardPercentiles)
-							if fees.err == nil {
+							if fees.err == nil && !fees.volatile {
 								oracle.historyCache.Add(cacheKey, fees.results)
 							}
 						}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/params/vars"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -89,3 +90,34 @@ func TestFeeHistory(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the legacy reward rows of blocks without usable transactions, which
+// are filled with the last suggested price, aren't cached.
+func TestFeeHistoryLegacyFill(t *testing.T) {
+	config := Config{
+		MaxHeaderHistory: 1000,
+		MaxBlockHistory:  1000,
+		IgnorePrice:      big.NewInt(1000 * vars.GWei), // Ignore all transactions
+	}
+	backend := newTestBackend(t, nil, false)
+	defer backend.teardown()
+	oracle := NewOracle(backend, config)
+
+	for _, price := range []int64{7, 9} {
+		oracle.cacheLock.Lock()
+		oracle.lastPrice = big.NewInt(price * vars.GWei)
+		oracle.cacheLock.Unlock()
+
+		_, reward, _, _, err := oracle.FeeHistory(context.Background(), 2, 30, []float64{0, 50})
+		if err != nil {
+			t.Fatalf("failed to retrieve fee history: %v", err)
+		}
+		for i, row := range reward {
+			for j, r := range row {
+				if r.Cmp(big.NewInt(price*vars.GWei)) != 0 {
+					t.Errorf("last price %d: reward %d/%d mismatch: have %v, want %v", price, i, j, r, price*vars.GWei)
+				}
+			}
+		}
+	}
+}
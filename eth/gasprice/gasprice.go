@@ -75,6 +75,9 @@ type Oracle struct {
 	maxHeaderHistory, maxBlockHistory uint64
 
 	historyCache *lru.Cache[cacheKey, processedFees]
+
+	levelsHead common.Hash  // Head block of the cached price levels
+	levels     *PriceLevels // Price levels suggested at levelsHead
 }
 
 // NewOracle returns a new gasprice oracle which can recommend suitable
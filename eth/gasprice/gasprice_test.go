@@ -21,6 +21,7 @@ import (
 	"math"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
@@ -231,3 +232,80 @@ func TestSuggestTipCap(t *testing.T) {
 		}
 	}
 }
+
+// poolBackend is a test backend with pending pool transactions.
+type poolBackend struct {
+	*testBackend
+	pending map[common.Address][]*types.Transaction
+}
+
+func (b *poolBackend) TxPoolContent() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
+	return b.pending, nil
+}
+
+func TestSuggestPriceLevels(t *testing.T) {
+	config := Config{
+		Blocks:     10,
+		Percentile: 60,
+		Default:    big.NewInt(vars.GWei),
+	}
+	check := func(name string, level *PriceLevel, tip *big.Int, blocks uint64) {
+		t.Helper()
+		if level.TipCap.Cmp(tip) != 0 {
+			t.Errorf("%s: tip mismatch, want %d, got %d", name, tip, level.TipCap)
+		}
+		if level.Blocks != blocks {
+			t.Errorf("%s: blocks mismatch, want %d, got %d", name, blocks, level.Blocks)
+		}
+		if level.Wait != time.Duration(blocks)*10*time.Second {
+			t.Errorf("%s: wait mismatch, want %v, got %v", name, time.Duration(blocks)*10*time.Second, level.Wait)
+		}
+	}
+	// The lowest prices of the sampled blocks are: 23G, 24G, ..., 32G
+	backend := newTestBackend(t, nil, false)
+	levels, err := NewOracle(backend, config).SuggestPriceLevels(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to retrieve price levels: %v", err)
+	}
+	if !levels.Legacy || levels.BaseFee != nil {
+		t.Fatalf("Legacy mode mismatch: legacy %v, base fee %v", levels.Legacy, levels.BaseFee)
+	}
+	check("slow", levels.Slow, big.NewInt(25*vars.GWei), 4)
+	check("standard", levels.Standard, big.NewInt(28*vars.GWei), 2)
+	check("fast", levels.Fast, big.NewInt(31*vars.GWei), 2)
+	if levels.Fast.GasPrice.Cmp(levels.Fast.TipCap) != 0 {
+		t.Errorf("Legacy gas price mismatch, want %d, got %d", levels.Fast.TipCap, levels.Fast.GasPrice)
+	}
+
+	// Two blocks worth of pending transactions outbid the fast level and delay the others
+	var (
+		gas     = backend.chain.GetHeaderByNumber(testHead).GasLimit/2 + 1
+		price   = big.NewInt(100 * vars.GWei)
+		pending = make(map[common.Address][]*types.Transaction)
+	)
+	for i := 0; i < 4; i++ {
+		pending[common.Address{byte(i)}] = []*types.Transaction{types.NewTx(&types.LegacyTx{Gas: gas, GasPrice: price})}
+	}
+	levels, err = NewOracle(&poolBackend{backend, pending}, config).SuggestPriceLevels(context.Background())
+	backend.teardown()
+	if err != nil {
+		t.Fatalf("Failed to retrieve price levels: %v", err)
+	}
+	check("slow", levels.Slow, big.NewInt(25*vars.GWei), 4)
+	check("standard", levels.Standard, big.NewInt(28*vars.GWei), 3)
+	check("fast", levels.Fast, new(big.Int).Add(price, common.Big1), 1)
+
+	// Post-London, the levels are tips on top of the next base fee
+	backend = newTestBackend(t, big.NewInt(0), false)
+	levels, err = NewOracle(backend, config).SuggestPriceLevels(context.Background())
+	backend.teardown()
+	if err != nil {
+		t.Fatalf("Failed to retrieve price levels: %v", err)
+	}
+	if levels.Legacy || levels.BaseFee == nil {
+		t.Fatalf("Legacy mode mismatch: legacy %v, base fee %v", levels.Legacy, levels.BaseFee)
+	}
+	if want := new(big.Int).Add(levels.Standard.TipCap, levels.BaseFee); levels.Standard.GasPrice.Cmp(want) != 0 {
+		t.Errorf("Gas price mismatch, want %d, got %d", want, levels.Standard.GasPrice)
+	}
+}
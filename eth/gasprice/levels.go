@@ -0,0 +1,262 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/exp/slices"
+)
+
+// priceLevels are the suggested inclusion speeds: the percentile of the lowest
+// prices included in recent blocks, and the number of blocks of pending pool
+// transactions the price should outbid.
+var priceLevels = []struct {
+	percentile int
+	target     uint64
+}{
+	{30, 10}, // slow
+	{60, 3},  // standard
+	{90, 1},  // fast
+}
+
+// PoolBackend is implemented by oracle backends with access to a transaction
+// pool, allowing the pending transactions to be taken into account.
+type PoolBackend interface {
+	TxPoolContent() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction)
+}
+
+// PriceLevel is a gas price suggestion along with the expected delay until a
+// transaction paying it is included.
+type PriceLevel struct {
+	TipCap   *big.Int      // Priority fee, equal to the gas price in legacy mode
+	GasPrice *big.Int      // Tip on top of the base fee of the next block
+	Blocks   uint64        // Expected number of blocks until inclusion
+	Wait     time.Duration // Expected time until inclusion
+}
+
+// PriceLevels are gas price suggestions for different inclusion speeds.
+type PriceLevels struct {
+	Number    uint64        // Head block the suggestions are based on
+	Legacy    bool          // Whether the next block has no EIP-1559 base fee
+	BaseFee   *big.Int      // Base fee of the next block, nil in legacy mode
+	BlockTime time.Duration // Average time between the sampled blocks
+
+	Slow, Standard, Fast *PriceLevel
+}
+
+// legacy reports whether the fee market of the given block predates EIP-1559,
+// in which case prices are plain gas prices instead of tips.
+func (oracle *Oracle) legacy(number *big.Int) bool {
+	config := oracle.backend.ChainConfig()
+	return !config.IsEnabled(config.GetEIP1559Transition, number)
+}
+
+// SuggestPriceLevels returns slow, standard and fast price suggestions. The
+// prices are percentiles of the lowest prices included in recent blocks, raised
+// to outbid the pending pool transactions which would otherwise be included
+// first. The expected delay of every level accounts both for how often recent
+// blocks included its price and for the backlog of the pending pool.
+func (oracle *Oracle) SuggestPriceLevels(ctx context.Context) (*PriceLevels, error) {
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	headHash := head.Hash()
+
+	oracle.cacheLock.RLock()
+	lastHead, levels := oracle.levelsHead, oracle.levels
+	oracle.cacheLock.RUnlock()
+	if headHash == lastHead && levels != nil {
+		return levels, nil
+	}
+	// The default price is used when recent blocks contain no samples
+	fallback, err := oracle.SuggestTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		next    = new(big.Int).Add(head.Number, common.Big1)
+		baseFee *big.Int
+	)
+	levels = &PriceLevels{
+		Number: head.Number.Uint64(),
+		Legacy: oracle.legacy(next),
+	}
+	if !levels.Legacy {
+		config := oracle.backend.ChainConfig()
+		baseFee = eip1559.CalcBaseFee(config, head)
+		levels.BaseFee = baseFee
+	}
+	mins, oldest, err := oracle.sampleMinPrices(ctx, head.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	if oldest != nil && head.Number.Uint64() > oldest.Number.Uint64() {
+		levels.BlockTime = time.Duration(head.Time-oldest.Time) * time.Second / time.Duration(head.Number.Uint64()-oldest.Number.Uint64())
+	}
+	pending := oracle.pendingTips(baseFee)
+
+	results := make([]*PriceLevel, len(priceLevels))
+	for i, level := range priceLevels {
+		tip := new(big.Int).Set(fallback)
+		if len(mins) > 0 {
+			tip.Set(mins[(len(mins)-1)*level.percentile/100])
+		}
+		if outbid := clearingTip(pending, level.target*head.GasLimit); outbid != nil && outbid.Cmp(tip) > 0 {
+			tip = outbid
+		}
+		if tip.Cmp(oracle.maxPrice) > 0 {
+			tip.Set(oracle.maxPrice)
+		}
+		result := &PriceLevel{
+			TipCap:   tip,
+			GasPrice: new(big.Int).Set(tip),
+			Blocks:   expectedBlocks(mins, pending, tip, head.GasLimit),
+		}
+		if baseFee != nil {
+			result.GasPrice.Add(result.GasPrice, baseFee)
+		}
+		result.Wait = time.Duration(result.Blocks) * levels.BlockTime
+		results[i] = result
+	}
+	levels.Slow, levels.Standard, levels.Fast = results[0], results[1], results[2]
+
+	oracle.cacheLock.Lock()
+	oracle.levelsHead, oracle.levels = headHash, levels
+	oracle.cacheLock.Unlock()
+
+	return levels, nil
+}
+
+// sampleMinPrices retrieves the lowest price paid by a transaction not sent by
+// the miner in each of the recent blocks, skipping empty blocks. The prices are
+// returned in ascending order, along with the header of the oldest sampled
+// block.
+func (oracle *Oracle) sampleMinPrices(ctx context.Context, number uint64) ([]*big.Int, *types.Header, error) {
+	var (
+		exp    int
+		result = make(chan results, oracle.checkBlocks)
+		quit   = make(chan struct{})
+		mins   []*big.Int
+	)
+	defer close(quit)
+
+	for exp < oracle.checkBlocks && number-uint64(exp) > 0 {
+		go oracle.getBlockValues(ctx, number-uint64(exp), 1, oracle.ignorePrice, result, quit)
+		exp++
+	}
+	for i := 0; i < exp; i++ {
+		res := <-result
+		if res.err != nil {
+			return nil, nil, res.err
+		}
+		mins = append(mins, res.values...)
+	}
+	if exp == 0 {
+		return nil, nil, nil
+	}
+	slices.SortFunc(mins, func(a, b *big.Int) int { return a.Cmp(b) })
+
+	oldest, err := oracle.backend.HeaderByNumber(ctx, rpc.BlockNumber(number+1-uint64(exp)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return mins, oldest, nil
+}
+
+// pendingTx is the gas and effective tip of a pending pool transaction.
+type pendingTx struct {
+	gas uint64
+	tip *big.Int
+}
+
+// pendingTips returns the executable transactions of the pool which can pay
+// the given base fee, in descending order of their effective tips.
+func (oracle *Oracle) pendingTips(baseFee *big.Int) []pendingTx {
+	pool, ok := oracle.backend.(PoolBackend)
+	if !ok {
+		return nil
+	}
+	content, _ := pool.TxPoolContent()
+
+	var txs []pendingTx
+	for _, list := range content {
+		for _, tx := range list {
+			tip, err := tx.EffectiveGasTip(baseFee)
+			if err != nil || tip.Cmp(oracle.ignorePrice) < 0 {
+				continue
+			}
+			txs = append(txs, pendingTx{gas: tx.Gas(), tip: tip})
+		}
+	}
+	slices.SortFunc(txs, func(a, b pendingTx) int { return b.tip.Cmp(a.tip) })
+	return txs
+}
+
+// clearingTip returns the tip needed to be included ahead of the pending
+// transactions filling the given amount of gas, or nil if the pool doesn't
+// hold that much.
+func clearingTip(pending []pendingTx, capacity uint64) *big.Int {
+	var gas uint64
+	for _, tx := range pending {
+		if gas += tx.gas; gas >= capacity {
+			return new(big.Int).Add(tx.tip, common.Big1)
+		}
+	}
+	return nil
+}
+
+// expectedBlocks estimates the number of blocks until a transaction paying the
+// given tip is included. It's the larger of the expected wait given the share
+// of recent blocks which included the tip, and the number of blocks needed to
+// clear the pending transactions paying more.
+func expectedBlocks(mins []*big.Int, pending []pendingTx, tip *big.Int, gasLimit uint64) uint64 {
+	blocks := uint64(1)
+	if len(mins) > 0 {
+		var included int
+		for _, min := range mins {
+			if min.Cmp(tip) <= 0 {
+				included++
+			}
+		}
+		if included == 0 {
+			blocks = uint64(len(mins)) + 1
+		} else {
+			blocks = uint64((len(mins) + included - 1) / included)
+		}
+	}
+	var ahead uint64
+	for _, tx := range pending {
+		if tx.tip.Cmp(tip) <= 0 {
+			break
+		}
+		ahead += tx.gas
+	}
+	if gasLimit > 0 {
+		if backlog := ahead/gasLimit + 1; backlog > blocks {
+			blocks = backlog
+		}
+	}
+	return blocks
+}
@@ -73,6 +73,10 @@ type txPool interface {
 	// Add should add the given transactions to the pool.
 	Add(txs []*types.Transaction, local bool, sync bool) []error
 
+	// AddWithOrigin should add the given transactions to the pool, accounting
+	// them to the peer they were received from.
+	AddWithOrigin(txs []*types.Transaction, local bool, sync bool, origin string) []error
+
 	// Pending should return pending transactions.
 	// The slice should be modifiable by the caller.
 	Pending(enforceTips bool) map[common.Address][]*txpool.LazyTransaction
@@ -300,8 +304,8 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return p.RequestTxs(hashes)
 	}
-	addTxs := func(txs []*types.Transaction) []error {
-		return h.txpool.Add(txs, false, false)
+	addTxs := func(peer string, txs []*types.Transaction) []error {
+		return h.txpool.AddWithOrigin(txs, false, false, peer)
 	}
 	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, h.removePeer)
 	h.chainSync = newChainSyncer(h)
@@ -92,6 +92,11 @@ func (p *testTxPool) Add(txs []*types.Transaction, local bool, sync bool) []erro
 	return make([]error, len(txs))
 }
 
+// AddWithOrigin appends a batch of transactions to the pool, ignoring the origin.
+func (p *testTxPool) AddWithOrigin(txs []*types.Transaction, local bool, sync bool, origin string) []error {
+	return p.Add(txs, local, sync)
+}
+
 // Pending returns all the transactions known to the pool
 func (p *testTxPool) Pending(enforceTips bool) map[common.Address][]*txpool.LazyTransaction {
 	p.lock.RLock()
@@ -64,3 +64,13 @@ func currentENREntry(chain *core.BlockChain) *enrEntry {
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis(), head.Number.Uint64(), head.Time),
 	}
 }
+
+// NodeForkID returns the fork identifier advertised in the `eth` entry of the
+// given node record, if any.
+func NodeForkID(n *enode.Node) (forkid.ID, bool) {
+	var entry enrEntry
+	if err := n.Load(&entry); err != nil {
+		return forkid.ID{}, false
+	}
+	return entry.ForkID, true
+}
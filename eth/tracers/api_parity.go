@@ -135,49 +135,27 @@ func decorateNestedTraceResponse(res interface{}, tracer string) interface{} {
 	return out
 }
 
-// traceBlockReward retrieve the block reward for the coinbase address
-func (api *TraceAPI) traceBlockReward(ctx context.Context, block *types.Block, config *TraceConfig) (*ParityTrace, error) {
+// traceBlockRewards retrieves the block rewards of the coinbase, the uncles and
+// any other recipients of the chain's reward policy
+func (api *TraceAPI) traceBlockRewards(ctx context.Context, block *types.Block, config *TraceConfig) ([]*ParityTrace, error) {
 	chainConfig := api.debugAPI.backend.ChainConfig()
-	minerReward, _ := mutations.GetRewards(chainConfig, block.Header(), block.Uncles())
-
-	coinbase := block.Coinbase()
-
-	tr := &ParityTrace{
-		Type: "reward",
-		Action: TraceRewardAction{
-			Value:      (*hexutil.Big)(minerReward),
-			Author:     &coinbase,
-			RewardType: "block",
-		},
-		TraceAddress: []int{},
-		BlockHash:    block.Hash(),
-		BlockNumber:  block.NumberU64(),
-	}
-
-	return tr, nil
-}
-
-// traceBlockUncleRewards retrieve the block rewards for uncle addresses
-func (api *TraceAPI) traceBlockUncleRewards(ctx context.Context, block *types.Block, config *TraceConfig) ([]*ParityTrace, error) {
-	chainConfig := api.debugAPI.backend.ChainConfig()
-	_, uncleRewards := mutations.GetRewards(chainConfig, block.Header(), block.Uncles())
-
-	results := make([]*ParityTrace, len(uncleRewards))
-	for i, uncle := range block.Uncles() {
-		if i < len(uncleRewards) {
-			coinbase := uncle.Coinbase
-
-			results[i] = &ParityTrace{
-				Type: "reward",
-				Action: TraceRewardAction{
-					Value:      (*hexutil.Big)(uncleRewards[i]),
-					Author:     &coinbase,
-					RewardType: "uncle",
-				},
-				TraceAddress: []int{},
-				BlockNumber:  block.NumberU64(),
-				BlockHash:    block.Hash(),
-			}
+	policy := mutations.RewardPolicyOf(chainConfig, block.Number())
+	rewards := policy.Rewards(chainConfig, block.Header(), block.Uncles(), block.Transactions())
+
+	results := make([]*ParityTrace, len(rewards))
+	for i, reward := range rewards {
+		recipient := reward.Recipient
+
+		results[i] = &ParityTrace{
+			Type: "reward",
+			Action: TraceRewardAction{
+				Value:      (*hexutil.Big)(reward.Amount),
+				Author:     &recipient,
+				RewardType: string(reward.Kind),
+			},
+			TraceAddress: []int{},
+			BlockHash:    block.Hash(),
+			BlockNumber:  block.NumberU64(),
 		}
 	}
 
@@ -200,12 +178,7 @@ func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber, config *
 		return nil, err
 	}
 
-	traceReward, err := api.traceBlockReward(ctx, block, config)
-	if err != nil {
-		return nil, err
-	}
-
-	traceUncleRewards, err := api.traceBlockUncleRewards(ctx, block, config)
+	traceRewards, err := api.traceBlockRewards(ctx, block, config)
 	if err != nil {
 		return nil, err
 	}
@@ -227,10 +200,8 @@ func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber, config *
 		}
 	}
 
-	results = append(results, traceReward)
-
-	for _, uncleReward := range traceUncleRewards {
-		results = append(results, uncleReward)
+	for _, reward := range traceRewards {
+		results = append(results, reward)
 	}
 
 	return results, nil
@@ -0,0 +1,569 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethgrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// This file implements the protobuf encoding of the messages of eth.proto.
+
+// message is a protobuf message sent by the service.
+type message interface {
+	marshal(b []byte) []byte
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendUint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendUint(b, num, 1)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBig(b []byte, num protowire.Number, v *big.Int) []byte {
+	if v == nil {
+		return b
+	}
+	return appendBytes(b, num, v.Bytes())
+}
+
+func appendMessage(b []byte, num protowire.Number, m message) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m.marshal(nil))
+}
+
+// decodeFields calls fn with the number, type and value of each field of an
+// encoded message. Varint values are decoded, other values are passed raw.
+func decodeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var (
+			v   uint64
+			raw []byte
+		)
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			raw, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(num, typ, v, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeHash decodes a 32 bytes hash field.
+func decodeHash(raw []byte) (common.Hash, error) {
+	if len(raw) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid hash length %d", len(raw))
+	}
+	return common.BytesToHash(raw), nil
+}
+
+// decodeAddress decodes a 20 bytes address field.
+func decodeAddress(raw []byte) (common.Address, error) {
+	if len(raw) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("invalid address length %d", len(raw))
+	}
+	return common.BytesToAddress(raw), nil
+}
+
+// Block tags of BlockRef.
+const (
+	tagLatest = iota
+	tagPending
+	tagSafe
+	tagFinalized
+	tagEarliest
+)
+
+// blockRef is a BlockRef message, converted to the RPC block reference.
+type blockRef rpc.BlockNumberOrHash
+
+func (r *blockRef) unmarshal(b []byte) error {
+	*r = blockRef(rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) error {
+		switch num {
+		case 1:
+			if v > math.MaxInt64 {
+				return fmt.Errorf("block number %d too high", v)
+			}
+			*r = blockRef(rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(v)))
+		case 2:
+			hash, err := decodeHash(raw)
+			if err != nil {
+				return err
+			}
+			*r = blockRef(rpc.BlockNumberOrHashWithHash(hash, true))
+		case 3:
+			number, ok := map[uint64]rpc.BlockNumber{
+				tagLatest:    rpc.LatestBlockNumber,
+				tagPending:   rpc.PendingBlockNumber,
+				tagSafe:      rpc.SafeBlockNumber,
+				tagFinalized: rpc.FinalizedBlockNumber,
+				tagEarliest:  rpc.EarliestBlockNumber,
+			}[v]
+			if !ok {
+				return fmt.Errorf("unknown block tag %d", v)
+			}
+			*r = blockRef(rpc.BlockNumberOrHashWithNumber(number))
+		}
+		return nil
+	})
+}
+
+// unmarshalBlockRef decodes an embedded BlockRef field.
+func unmarshalBlockRef(raw []byte) (rpc.BlockNumberOrHash, error) {
+	var ref blockRef
+	err := ref.unmarshal(raw)
+	return rpc.BlockNumberOrHash(ref), err
+}
+
+// getBlockRequest is a GetBlockRequest message.
+type getBlockRequest struct {
+	block rpc.BlockNumberOrHash
+	full  bool
+}
+
+func (r *getBlockRequest) unmarshal(b []byte) error {
+	r.block = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		switch num {
+		case 1:
+			r.block, err = unmarshalBlockRef(raw)
+		case 2:
+			r.full = v != 0
+		}
+		return err
+	})
+}
+
+// getReceiptsRequest is a GetReceiptsRequest message.
+type getReceiptsRequest struct {
+	block rpc.BlockNumberOrHash
+}
+
+func (r *getReceiptsRequest) unmarshal(b []byte) error {
+	r.block = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		if num == 1 {
+			r.block, err = unmarshalBlockRef(raw)
+		}
+		return err
+	})
+}
+
+// getTransactionReceiptRequest is a GetTransactionReceiptRequest message.
+type getTransactionReceiptRequest struct {
+	hash common.Hash
+}
+
+func (r *getTransactionReceiptRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		if num == 1 {
+			r.hash, err = decodeHash(raw)
+		}
+		return err
+	})
+}
+
+// logFilter is a LogFilter message.
+type logFilter struct {
+	from, to  rpc.BlockNumberOrHash
+	blockHash *common.Hash
+	addresses []common.Address
+	topics    [][]common.Hash
+}
+
+func (f *logFilter) unmarshal(b []byte) error {
+	f.from = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	f.to = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		switch num {
+		case 1:
+			f.from, err = unmarshalBlockRef(raw)
+		case 2:
+			f.to, err = unmarshalBlockRef(raw)
+		case 3:
+			var hash common.Hash
+			if hash, err = decodeHash(raw); err == nil {
+				f.blockHash = &hash
+			}
+		case 4:
+			var addr common.Address
+			if addr, err = decodeAddress(raw); err == nil {
+				f.addresses = append(f.addresses, addr)
+			}
+		case 5:
+			var set []common.Hash
+			err = decodeFields(raw, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) error {
+				if num != 1 {
+					return nil
+				}
+				hash, err := decodeHash(raw)
+				set = append(set, hash)
+				return err
+			})
+			f.topics = append(f.topics, set)
+		}
+		return err
+	})
+}
+
+// getAccountRequest is a GetAccountRequest message.
+type getAccountRequest struct {
+	address common.Address
+	block   rpc.BlockNumberOrHash
+}
+
+func (r *getAccountRequest) unmarshal(b []byte) error {
+	r.block = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		switch num {
+		case 1:
+			r.address, err = decodeAddress(raw)
+		case 2:
+			r.block, err = unmarshalBlockRef(raw)
+		}
+		return err
+	})
+}
+
+// getStorageRequest is a GetStorageRequest message.
+type getStorageRequest struct {
+	address common.Address
+	keys    []common.Hash
+	block   rpc.BlockNumberOrHash
+}
+
+func (r *getStorageRequest) unmarshal(b []byte) error {
+	r.block = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		switch num {
+		case 1:
+			r.address, err = decodeAddress(raw)
+		case 2:
+			var key common.Hash
+			if key, err = decodeHash(raw); err == nil {
+				r.keys = append(r.keys, key)
+			}
+		case 3:
+			r.block, err = unmarshalBlockRef(raw)
+		}
+		return err
+	})
+}
+
+// emptyRequest is a message without fields, like SubscribeHeadsRequest.
+type emptyRequest struct{}
+
+func (r *emptyRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(protowire.Number, protowire.Type, uint64, []byte) error { return nil })
+}
+
+// traceConfig is a TraceConfig message, converted to the config of the tracers.
+type traceConfig tracers.TraceConfig
+
+func (c *traceConfig) unmarshal(b []byte) error {
+	// The struct logger options are only set if one of them is
+	loggerConfig := func() *logger.Config {
+		if c.Config == nil {
+			c.Config = new(logger.Config)
+		}
+		return c.Config
+	}
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) error {
+		switch num {
+		case 1:
+			tracer := string(raw)
+			c.Tracer = &tracer
+		case 2:
+			c.TracerConfig = json.RawMessage(common.CopyBytes(raw))
+		case 3:
+			timeout := string(raw)
+			c.Timeout = &timeout
+		case 4:
+			reexec := v
+			c.Reexec = &reexec
+		case 5:
+			loggerConfig().EnableMemory = v != 0
+		case 6:
+			loggerConfig().DisableStack = v != 0
+		case 7:
+			loggerConfig().DisableStorage = v != 0
+		case 8:
+			loggerConfig().EnableReturnData = v != 0
+		}
+		return nil
+	})
+}
+
+// unmarshalTraceConfig decodes an embedded TraceConfig field.
+func unmarshalTraceConfig(raw []byte) (*tracers.TraceConfig, error) {
+	var config traceConfig
+	err := config.unmarshal(raw)
+	return (*tracers.TraceConfig)(&config), err
+}
+
+// traceBlockRequest is a TraceBlockRequest message.
+type traceBlockRequest struct {
+	block  rpc.BlockNumberOrHash
+	config *tracers.TraceConfig
+}
+
+func (r *traceBlockRequest) unmarshal(b []byte) error {
+	r.block = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		switch num {
+		case 1:
+			r.block, err = unmarshalBlockRef(raw)
+		case 2:
+			r.config, err = unmarshalTraceConfig(raw)
+		}
+		return err
+	})
+}
+
+// traceTransactionRequest is a TraceTransactionRequest message.
+type traceTransactionRequest struct {
+	hash   common.Hash
+	config *tracers.TraceConfig
+}
+
+func (r *traceTransactionRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		switch num {
+		case 1:
+			r.hash, err = decodeHash(raw)
+		case 2:
+			r.config, err = unmarshalTraceConfig(raw)
+		}
+		return err
+	})
+}
+
+// headerMessage is a Header message.
+type headerMessage struct{ *types.Header }
+
+func (h headerMessage) marshal(b []byte) []byte {
+	b = appendBytes(b, 1, h.Hash().Bytes())
+	b = appendBytes(b, 2, h.ParentHash.Bytes())
+	b = appendUint(b, 3, h.Number.Uint64())
+	b = appendUint(b, 4, h.Time)
+	b = appendBytes(b, 5, h.Coinbase.Bytes())
+	b = appendBytes(b, 6, h.Root.Bytes())
+	b = appendBytes(b, 7, h.TxHash.Bytes())
+	b = appendBytes(b, 8, h.ReceiptHash.Bytes())
+	b = appendBytes(b, 9, h.Bloom.Bytes())
+	b = appendBig(b, 10, h.Difficulty)
+	b = appendUint(b, 11, h.GasLimit)
+	b = appendUint(b, 12, h.GasUsed)
+	b = appendBytes(b, 13, h.Extra)
+	b = appendBytes(b, 14, h.MixDigest.Bytes())
+	b = appendUint(b, 15, h.Nonce.Uint64())
+	b = appendBytes(b, 16, h.UncleHash.Bytes())
+	return appendBig(b, 17, h.BaseFee)
+}
+
+// transactionMessage is a Transaction message.
+type transactionMessage struct {
+	tx    *types.Transaction
+	raw   []byte
+	from  common.Address
+	index uint64
+}
+
+func (t *transactionMessage) marshal(b []byte) []byte {
+	b = appendBytes(b, 1, t.tx.Hash().Bytes())
+	b = appendBytes(b, 2, t.raw)
+	b = appendBytes(b, 3, t.from.Bytes())
+	return appendUint(b, 4, t.index)
+}
+
+// blockMessage is a Block message.
+type blockMessage struct {
+	block *types.Block
+	txs   []*transactionMessage // Set if the full transactions are sent
+}
+
+func (m *blockMessage) marshal(b []byte) []byte {
+	b = appendMessage(b, 1, headerMessage{m.block.Header()})
+	if m.txs != nil {
+		for _, tx := range m.txs {
+			b = appendMessage(b, 2, tx)
+		}
+	} else {
+		for _, tx := range m.block.Transactions() {
+			b = protowire.AppendTag(b, 3, protowire.BytesType)
+			b = protowire.AppendBytes(b, tx.Hash().Bytes())
+		}
+	}
+	for _, uncle := range m.block.Uncles() {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendBytes(b, uncle.Hash().Bytes())
+	}
+	return appendUint(b, 5, m.block.Size())
+}
+
+// logMessage is a Log message.
+type logMessage struct{ *types.Log }
+
+func (l logMessage) marshal(b []byte) []byte {
+	b = appendBytes(b, 1, l.Address.Bytes())
+	for _, topic := range l.Topics {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, topic.Bytes())
+	}
+	b = appendBytes(b, 3, l.Data)
+	b = appendUint(b, 4, l.BlockNumber)
+	b = appendBytes(b, 5, l.BlockHash.Bytes())
+	b = appendBytes(b, 6, l.TxHash.Bytes())
+	b = appendUint(b, 7, uint64(l.TxIndex))
+	b = appendUint(b, 8, uint64(l.Index))
+	return appendBool(b, 9, l.Removed)
+}
+
+// receiptMessage is a Receipt message.
+type receiptMessage struct {
+	receipt *types.Receipt
+	from    common.Address
+	to      *common.Address
+}
+
+func (m *receiptMessage) marshal(b []byte) []byte {
+	r := m.receipt
+	b = appendBytes(b, 1, r.TxHash.Bytes())
+	b = appendUint(b, 2, uint64(r.TransactionIndex))
+	b = appendBytes(b, 3, r.BlockHash.Bytes())
+	if r.BlockNumber != nil {
+		b = appendUint(b, 4, r.BlockNumber.Uint64())
+	}
+	b = appendUint(b, 5, uint64(r.Type))
+	b = appendUint(b, 6, r.Status)
+	b = appendUint(b, 7, r.CumulativeGasUsed)
+	b = appendUint(b, 8, r.GasUsed)
+	b = appendBig(b, 9, r.EffectiveGasPrice)
+	b = appendBytes(b, 10, m.from.Bytes())
+	if m.to != nil {
+		b = appendBytes(b, 11, m.to.Bytes())
+	}
+	if r.ContractAddress != (common.Address{}) {
+		b = appendBytes(b, 12, r.ContractAddress.Bytes())
+	}
+	b = appendBytes(b, 13, r.Bloom.Bytes())
+	for _, log := range r.Logs {
+		b = appendMessage(b, 14, logMessage{log})
+	}
+	return b
+}
+
+// receiptsMessage is a Receipts message.
+type receiptsMessage []*receiptMessage
+
+func (m receiptsMessage) marshal(b []byte) []byte {
+	for _, receipt := range m {
+		b = appendMessage(b, 1, receipt)
+	}
+	return b
+}
+
+// accountMessage is an Account message.
+type accountMessage struct {
+	balance  *big.Int
+	nonce    uint64
+	code     []byte
+	codeHash common.Hash
+}
+
+func (m *accountMessage) marshal(b []byte) []byte {
+	b = appendBig(b, 1, m.balance)
+	b = appendUint(b, 2, m.nonce)
+	b = appendBytes(b, 3, m.code)
+	return appendBytes(b, 4, m.codeHash.Bytes())
+}
+
+// storageValuesMessage is a StorageValues message.
+type storageValuesMessage []common.Hash
+
+func (m storageValuesMessage) marshal(b []byte) []byte {
+	for _, value := range m {
+		// Repeated bytes are sent even if empty to keep the key order
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, value.Bytes())
+	}
+	return b
+}
+
+// traceResultMessage is a TraceResult message.
+type traceResultMessage struct {
+	txHash common.Hash
+	result []byte // JSON output of the tracer
+	err    string
+}
+
+func (m *traceResultMessage) marshal(b []byte) []byte {
+	b = appendBytes(b, 1, m.txHash.Bytes())
+	b = appendBytes(b, 2, m.result)
+	return appendString(b, 3, m.err)
+}
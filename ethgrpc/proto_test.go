@@ -0,0 +1,622 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethgrpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoTokens matches the tokens of the protobuf language, skipping comments.
+var protoTokens = regexp.MustCompile(`//[^\n]*|/\*(?s:.*?)\*/|"[^"]*"|[\w.]+|[^\s\w]`)
+
+// protoScalars are the scalar field types used by the schema.
+var protoScalars = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"bytes":  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+	"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"uint32": descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"uint64": descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+}
+
+// protoParser parses the subset of the protobuf language used by eth.proto into
+// a file descriptor, so the codec can be checked against the schema without
+// protoc.
+type protoParser struct {
+	t      *testing.T
+	tokens []string
+	file   *descriptorpb.FileDescriptorProto
+	enums  map[string]bool // Full names of the enums, to resolve field types
+}
+
+func parseProto(t *testing.T, path string) protoreflect.FileDescriptor {
+	t.Helper()
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &protoParser{
+		t:     t,
+		file:  &descriptorpb.FileDescriptorProto{Name: proto.String(path)},
+		enums: make(map[string]bool),
+	}
+	for _, token := range protoTokens.FindAllString(string(src), -1) {
+		if !strings.HasPrefix(token, "//") && !strings.HasPrefix(token, "/*") {
+			p.tokens = append(p.tokens, token)
+		}
+	}
+	for len(p.tokens) > 0 {
+		switch token := p.next(); token {
+		case "syntax":
+			p.expect("=")
+			p.file.Syntax = proto.String(strings.Trim(p.next(), `"`))
+			p.expect(";")
+		case "package":
+			p.file.Package = proto.String(p.next())
+			p.expect(";")
+		case "option":
+			p.skip()
+		case "service":
+			p.file.Service = append(p.file.Service, p.parseService())
+		case "message":
+			p.file.MessageType = append(p.file.MessageType, p.parseMessage(p.file.GetPackage()))
+		default:
+			t.Fatalf("unexpected token %q", token)
+		}
+	}
+	for _, msg := range p.file.MessageType {
+		p.resolve(p.file.GetPackage(), msg)
+	}
+	file, err := protodesc.NewFile(p.file, new(protoregistry.Files))
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	return file
+}
+
+func (p *protoParser) next() string {
+	if len(p.tokens) == 0 {
+		p.t.Fatal("unexpected end of schema")
+	}
+	token := p.tokens[0]
+	p.tokens = p.tokens[1:]
+	return token
+}
+
+func (p *protoParser) expect(want string) {
+	if token := p.next(); token != want {
+		p.t.Fatalf("unexpected token %q, want %q", token, want)
+	}
+}
+
+// skip skips the tokens up to the end of the statement.
+func (p *protoParser) skip() {
+	for p.next() != ";" {
+	}
+}
+
+func (p *protoParser) parseService() *descriptorpb.ServiceDescriptorProto {
+	service := &descriptorpb.ServiceDescriptorProto{Name: proto.String(p.next())}
+	p.expect("{")
+	for token := p.next(); token != "}"; token = p.next() {
+		if token != "rpc" {
+			p.t.Fatalf("unexpected token %q in service", token)
+		}
+		method := &descriptorpb.MethodDescriptorProto{Name: proto.String(p.next())}
+		p.expect("(")
+		method.InputType = proto.String(p.next())
+		if method.GetInputType() == "stream" {
+			method.ClientStreaming, method.InputType = proto.Bool(true), proto.String(p.next())
+		}
+		p.expect(")")
+		p.expect("returns")
+		p.expect("(")
+		method.OutputType = proto.String(p.next())
+		if method.GetOutputType() == "stream" {
+			method.ServerStreaming, method.OutputType = proto.Bool(true), proto.String(p.next())
+		}
+		p.expect(")")
+		p.expect(";")
+
+		method.InputType = proto.String("." + p.file.GetPackage() + "." + method.GetInputType())
+		method.OutputType = proto.String("." + p.file.GetPackage() + "." + method.GetOutputType())
+		service.Method = append(service.Method, method)
+	}
+	return service
+}
+
+func (p *protoParser) parseMessage(scope string) *descriptorpb.DescriptorProto {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(p.next())}
+	scope += "." + msg.GetName()
+
+	p.expect("{")
+	for token := p.next(); token != "}"; token = p.next() {
+		switch token {
+		case "message":
+			msg.NestedType = append(msg.NestedType, p.parseMessage(scope))
+		case "enum":
+			msg.EnumType = append(msg.EnumType, p.parseEnum(scope))
+		case "oneof":
+			index := int32(len(msg.OneofDecl))
+			msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(p.next())})
+			p.expect("{")
+			for token := p.next(); token != "}"; token = p.next() {
+				field := p.parseField(token)
+				field.OneofIndex = proto.Int32(index)
+				msg.Field = append(msg.Field, field)
+			}
+		case "option", "reserved":
+			p.skip()
+		default:
+			msg.Field = append(msg.Field, p.parseField(token))
+		}
+	}
+	return msg
+}
+
+func (p *protoParser) parseEnum(scope string) *descriptorpb.EnumDescriptorProto {
+	enum := &descriptorpb.EnumDescriptorProto{Name: proto.String(p.next())}
+	p.enums[scope+"."+enum.GetName()] = true
+
+	p.expect("{")
+	for token := p.next(); token != "}"; token = p.next() {
+		p.expect("=")
+		number, err := strconv.ParseInt(p.next(), 10, 32)
+		if err != nil {
+			p.t.Fatalf("invalid enum value %s: %v", token, err)
+		}
+		p.expect(";")
+		enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name:   proto.String(token),
+			Number: proto.Int32(int32(number)),
+		})
+	}
+	return enum
+}
+
+// parseField parses a field starting with the given token. The types which
+// aren't scalars are resolved once the whole schema is parsed.
+func (p *protoParser) parseField(token string) *descriptorpb.FieldDescriptorProto {
+	field := &descriptorpb.FieldDescriptorProto{Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}
+	if token == "repeated" {
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		token = p.next()
+	}
+	if typ, ok := protoScalars[token]; ok {
+		field.Type = typ.Enum()
+	} else {
+		field.TypeName = proto.String(token)
+	}
+	field.Name = proto.String(p.next())
+	p.expect("=")
+	number, err := strconv.ParseInt(p.next(), 10, 32)
+	if err != nil {
+		p.t.Fatalf("invalid number of field %s: %v", field.GetName(), err)
+	}
+	field.Number = proto.Int32(int32(number))
+	p.expect(";")
+	return field
+}
+
+// resolve resolves the message and enum field types of a message, looking them
+// up from the innermost scope outwards.
+func (p *protoParser) resolve(scope string, msg *descriptorpb.DescriptorProto) {
+	scope += "." + msg.GetName()
+	for _, nested := range msg.NestedType {
+		p.resolve(scope, nested)
+	}
+	for _, field := range msg.Field {
+		if field.Type != nil {
+			continue
+		}
+		name := ""
+		for s := scope; name == ""; s = s[:strings.LastIndexByte(s, '.')] {
+			if p.enums[s+"."+field.GetTypeName()] || p.message(s+"."+field.GetTypeName()) {
+				name = s + "." + field.GetTypeName()
+			}
+			if !strings.Contains(s, ".") {
+				break
+			}
+		}
+		if name == "" {
+			p.t.Fatalf("unknown type %s of field %s.%s", field.GetTypeName(), scope, field.GetName())
+		}
+		field.TypeName = proto.String("." + name)
+		if p.enums[name] {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum()
+		} else {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		}
+	}
+}
+
+// message reports whether a message of the given full name is declared.
+func (p *protoParser) message(name string) bool {
+	if !strings.HasPrefix(name, p.file.GetPackage()+".") {
+		return false
+	}
+	msgs := p.file.MessageType
+	for _, part := range strings.Split(strings.TrimPrefix(name, p.file.GetPackage()+"."), ".") {
+		var found *descriptorpb.DescriptorProto
+		for _, msg := range msgs {
+			if msg.GetName() == part {
+				found = msg
+			}
+		}
+		if found == nil {
+			return false
+		}
+		msgs = found.NestedType
+	}
+	return true
+}
+
+// newProtoMessage creates a message of the schema with the given field values.
+// Repeated fields are set from slices of values.
+func newProtoMessage(t *testing.T, file protoreflect.FileDescriptor, name string, fields map[string]interface{}) *dynamicpb.Message {
+	t.Helper()
+
+	desc := file.Messages().ByName(protoreflect.Name(name))
+	if desc == nil {
+		t.Fatalf("unknown message %s", name)
+	}
+	msg := dynamicpb.NewMessage(desc)
+	for name, value := range fields {
+		field := desc.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			t.Fatalf("unknown field %s of %s", name, desc.FullName())
+		}
+		if !field.IsList() {
+			msg.Set(field, protoValue(value))
+			continue
+		}
+		list := msg.Mutable(field).List()
+		for _, elem := range value.([]interface{}) {
+			list.Append(protoValue(elem))
+		}
+	}
+	return msg
+}
+
+func protoValue(value interface{}) protoreflect.Value {
+	if msg, ok := value.(*dynamicpb.Message); ok {
+		return protoreflect.ValueOfMessage(msg)
+	}
+	return protoreflect.ValueOf(value)
+}
+
+// protoFields adds the full names of the fields set in a message and its
+// embedded messages to the set.
+func protoFields(msg protoreflect.Message, set map[protoreflect.FullName]bool) {
+	msg.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		set[field.FullName()] = true
+		switch {
+		case field.IsList() && field.Message() != nil:
+			for i := 0; i < value.List().Len(); i++ {
+				protoFields(value.List().Get(i).Message(), set)
+			}
+		case field.Message() != nil:
+			protoFields(value.Message(), set)
+		}
+		return true
+	})
+}
+
+// protoUnknown returns whether a message or one of its embedded messages holds
+// fields not matching the schema.
+func protoUnknown(msg protoreflect.Message) bool {
+	unknown := len(msg.GetUnknown()) > 0
+	msg.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case field.IsList() && field.Message() != nil:
+			for i := 0; i < value.List().Len(); i++ {
+				unknown = unknown || protoUnknown(value.List().Get(i).Message())
+			}
+		case field.Message() != nil:
+			unknown = unknown || protoUnknown(value.Message())
+		}
+		return !unknown
+	})
+	return unknown
+}
+
+// Tests that the hand-written codec matches eth.proto: the requests encoded by
+// protobuf from the schema decode to the expected values, the responses decode
+// against the schema without unknown fields, and every field of every message
+// of the schema is covered.
+func TestProtoSchema(t *testing.T) {
+	t.Parallel()
+
+	file := parseProto(t, "eth.proto")
+	covered := make(map[protoreflect.FullName]bool)
+
+	// Every method of the service is served
+	methods := file.Services().ByName("Eth").Methods()
+	if methods.Len() != len(rpcMethods) {
+		t.Errorf("method count mismatch: schema %d, served %d", methods.Len(), len(rpcMethods))
+	}
+	for i := 0; i < methods.Len(); i++ {
+		if _, ok := rpcMethods[string(methods.Get(i).Name())]; !ok {
+			t.Errorf("method %s not served", methods.Get(i).Name())
+		}
+	}
+	// Requests
+	var (
+		hash = common.Hash{0x01}
+		addr = common.Address{0x02}
+		key  = common.Hash{0x03}
+		ref  = func(fields map[string]interface{}) *dynamicpb.Message {
+			return newProtoMessage(t, file, "BlockRef", fields)
+		}
+		tracer       = "callTracer"
+		tracerConfig = `{"onlyTopCall":true}`
+		timeout      = "10s"
+		reexec       = uint64(64)
+	)
+	requests := []struct {
+		msg  *dynamicpb.Message
+		have interface{ unmarshal([]byte) error }
+		want interface{}
+	}{
+		{
+			msg:  newProtoMessage(t, file, "GetBlockRequest", map[string]interface{}{"block": ref(map[string]interface{}{"number": uint64(100)}), "full_transactions": true}),
+			have: new(getBlockRequest),
+			want: &getBlockRequest{block: rpc.BlockNumberOrHashWithNumber(100), full: true},
+		},
+		{
+			msg:  newProtoMessage(t, file, "GetReceiptsRequest", map[string]interface{}{"block": ref(map[string]interface{}{"hash": hash.Bytes()})}),
+			have: new(getReceiptsRequest),
+			want: &getReceiptsRequest{block: rpc.BlockNumberOrHashWithHash(hash, true)},
+		},
+		{
+			msg:  newProtoMessage(t, file, "GetTransactionReceiptRequest", map[string]interface{}{"hash": hash.Bytes()}),
+			have: new(getTransactionReceiptRequest),
+			want: &getTransactionReceiptRequest{hash: hash},
+		},
+		{
+			msg: newProtoMessage(t, file, "LogFilter", map[string]interface{}{
+				"from_block": ref(map[string]interface{}{"number": uint64(1)}),
+				"to_block":   ref(map[string]interface{}{"number": uint64(2)}),
+				"block_hash": hash.Bytes(),
+				"addresses":  []interface{}{addr.Bytes()},
+				"topics": []interface{}{
+					newProtoMessage(t, file, "TopicSet", nil),
+					newProtoMessage(t, file, "TopicSet", map[string]interface{}{"hashes": []interface{}{hash.Bytes(), key.Bytes()}}),
+				},
+			}),
+			have: new(logFilter),
+			want: &logFilter{
+				from:      rpc.BlockNumberOrHashWithNumber(1),
+				to:        rpc.BlockNumberOrHashWithNumber(2),
+				blockHash: &hash,
+				addresses: []common.Address{addr},
+				topics:    [][]common.Hash{nil, {hash, key}},
+			},
+		},
+		{
+			msg:  newProtoMessage(t, file, "GetAccountRequest", map[string]interface{}{"address": addr.Bytes(), "block": ref(map[string]interface{}{"number": uint64(4)})}),
+			have: new(getAccountRequest),
+			want: &getAccountRequest{address: addr, block: rpc.BlockNumberOrHashWithNumber(4)},
+		},
+		{
+			msg:  newProtoMessage(t, file, "GetStorageRequest", map[string]interface{}{"address": addr.Bytes(), "keys": []interface{}{key.Bytes(), hash.Bytes()}, "block": ref(map[string]interface{}{"number": uint64(3)})}),
+			have: new(getStorageRequest),
+			want: &getStorageRequest{address: addr, keys: []common.Hash{key, hash}, block: rpc.BlockNumberOrHashWithNumber(3)},
+		},
+		{
+			msg:  newProtoMessage(t, file, "SubscribeHeadsRequest", nil),
+			have: new(emptyRequest),
+			want: new(emptyRequest),
+		},
+		{
+			msg: newProtoMessage(t, file, "TraceBlockRequest", map[string]interface{}{
+				"block": ref(map[string]interface{}{"number": uint64(5)}),
+				"config": newProtoMessage(t, file, "TraceConfig", map[string]interface{}{
+					"tracer":             tracer,
+					"tracer_config":      []byte(tracerConfig),
+					"timeout":            timeout,
+					"reexec":             reexec,
+					"enable_memory":      true,
+					"disable_stack":      true,
+					"disable_storage":    true,
+					"enable_return_data": true,
+				}),
+			}),
+			have: new(traceBlockRequest),
+			want: &traceBlockRequest{
+				block: rpc.BlockNumberOrHashWithNumber(5),
+				config: &tracers.TraceConfig{
+					Config:       &logger.Config{EnableMemory: true, DisableStack: true, DisableStorage: true, EnableReturnData: true},
+					Tracer:       &tracer,
+					Timeout:      &timeout,
+					Reexec:       &reexec,
+					TracerConfig: json.RawMessage(tracerConfig),
+				},
+			},
+		},
+		{
+			msg:  newProtoMessage(t, file, "TraceTransactionRequest", map[string]interface{}{"hash": hash.Bytes(), "config": newProtoMessage(t, file, "TraceConfig", map[string]interface{}{"tracer": tracer})}),
+			have: new(traceTransactionRequest),
+			want: &traceTransactionRequest{hash: hash, config: &tracers.TraceConfig{Tracer: &tracer}},
+		},
+	}
+	// The block tags of the schema map to the RPC ones of the same name
+	tags := map[protoreflect.Name]rpc.BlockNumber{
+		"LATEST":    rpc.LatestBlockNumber,
+		"PENDING":   rpc.PendingBlockNumber,
+		"SAFE":      rpc.SafeBlockNumber,
+		"FINALIZED": rpc.FinalizedBlockNumber,
+		"EARLIEST":  rpc.EarliestBlockNumber,
+	}
+	values := file.Messages().ByName("BlockRef").Enums().ByName("Tag").Values()
+	if values.Len() != len(tags) {
+		t.Errorf("block tag count mismatch: schema %d, want %d", values.Len(), len(tags))
+	}
+	for i := 0; i < values.Len(); i++ {
+		number, ok := tags[values.Get(i).Name()]
+		if !ok {
+			t.Errorf("unknown block tag %s", values.Get(i).Name())
+			continue
+		}
+		requests = append(requests, struct {
+			msg  *dynamicpb.Message
+			have interface{ unmarshal([]byte) error }
+			want interface{}
+		}{
+			msg:  newProtoMessage(t, file, "GetReceiptsRequest", map[string]interface{}{"block": ref(map[string]interface{}{"tag": values.Get(i).Number()})}),
+			have: new(getReceiptsRequest),
+			want: &getReceiptsRequest{block: rpc.BlockNumberOrHashWithNumber(number)},
+		})
+	}
+	for _, tt := range requests {
+		name := tt.msg.Descriptor().Name()
+		b, err := proto.Marshal(tt.msg)
+		if err != nil {
+			t.Fatalf("%s: failed to encode: %v", name, err)
+		}
+		if err := tt.have.unmarshal(b); err != nil {
+			t.Errorf("%s: failed to decode: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.have, tt.want) {
+			t.Errorf("%s: decoded mismatch: have %+v, want %+v", name, tt.have, tt.want)
+		}
+		protoFields(tt.msg, covered)
+	}
+	// Responses
+	var (
+		header = &types.Header{
+			ParentHash:  common.Hash{0x10},
+			UncleHash:   common.Hash{0x11},
+			Coinbase:    common.Address{0x12},
+			Root:        common.Hash{0x13},
+			TxHash:      common.Hash{0x14},
+			ReceiptHash: common.Hash{0x15},
+			Bloom:       types.Bloom{0x16},
+			Difficulty:  big.NewInt(17),
+			Number:      big.NewInt(18),
+			GasLimit:    19,
+			GasUsed:     20,
+			Time:        21,
+			Extra:       []byte{0x22},
+			MixDigest:   common.Hash{0x23},
+			Nonce:       types.EncodeNonce(24),
+			BaseFee:     big.NewInt(25),
+		}
+		tx    = types.NewTx(&types.LegacyTx{Nonce: 1, To: &addr, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1)})
+		block = types.NewBlockWithHeader(header).WithBody([]*types.Transaction{tx}, []*types.Header{header})
+		log   = &types.Log{
+			Address:     addr,
+			Topics:      []common.Hash{hash},
+			Data:        []byte{0x30},
+			BlockNumber: 31,
+			TxHash:      common.Hash{0x32},
+			TxIndex:     33,
+			BlockHash:   common.Hash{0x34},
+			Index:       35,
+			Removed:     true,
+		}
+		receipt = &receiptMessage{
+			receipt: &types.Receipt{
+				Type:              types.DynamicFeeTxType,
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: 40,
+				Bloom:             types.Bloom{0x41},
+				Logs:              []*types.Log{log},
+				TxHash:            common.Hash{0x42},
+				ContractAddress:   common.Address{0x43},
+				GasUsed:           44,
+				EffectiveGasPrice: big.NewInt(45),
+				BlockHash:         common.Hash{0x46},
+				BlockNumber:       big.NewInt(47),
+				TransactionIndex:  48,
+			},
+			from: common.Address{0x49},
+			to:   &addr,
+		}
+	)
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := []struct {
+		name protoreflect.Name
+		msg  message
+	}{
+		{"Header", headerMessage{header}},
+		{"Transaction", &transactionMessage{tx: tx, raw: raw, from: addr, index: 1}},
+		{"Block", &blockMessage{block: block}},
+		{"Block", &blockMessage{block: block, txs: []*transactionMessage{{tx: tx, raw: raw, from: addr, index: 1}}}},
+		{"Log", logMessage{log}},
+		{"Receipt", receipt},
+		{"Receipts", receiptsMessage{receipt}},
+		{"Account", &accountMessage{balance: big.NewInt(50), nonce: 51, code: []byte{0x52}, codeHash: common.Hash{0x53}}},
+		{"StorageValues", storageValuesMessage{key, {}}},
+		{"TraceResult", &traceResultMessage{txHash: hash, result: []byte(`{"gas":"0x5208"}`), err: "execution reverted"}},
+	}
+	for _, tt := range responses {
+		msg := dynamicpb.NewMessage(file.Messages().ByName(tt.name))
+		if err := proto.Unmarshal(tt.msg.marshal(nil), msg); err != nil {
+			t.Errorf("%s: failed to decode: %v", tt.name, err)
+			continue
+		}
+		if protoUnknown(msg) {
+			t.Errorf("%s: fields not matching the schema", tt.name)
+		}
+		protoFields(msg, covered)
+	}
+	// Every field of the schema is covered
+	for i := 0; i < file.Messages().Len(); i++ {
+		desc := file.Messages().Get(i)
+		for _, desc := range append([]protoreflect.MessageDescriptor{desc}, nestedMessages(desc)...) {
+			for j := 0; j < desc.Fields().Len(); j++ {
+				if field := desc.Fields().Get(j); !covered[field.FullName()] {
+					t.Errorf("field %s not covered", field.FullName())
+				}
+			}
+		}
+	}
+}
+
+// nestedMessages returns the messages nested in a message, recursively.
+func nestedMessages(desc protoreflect.MessageDescriptor) []protoreflect.MessageDescriptor {
+	var msgs []protoreflect.MessageDescriptor
+	for i := 0; i < desc.Messages().Len(); i++ {
+		msgs = append(msgs, desc.Messages().Get(i))
+		msgs = append(msgs, nestedMessages(desc.Messages().Get(i))...)
+	}
+	return msgs
+}
@@ -0,0 +1,242 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethgrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// This file implements the gRPC wire protocol over HTTP/2: length-prefixed
+// messages in the request and response bodies, and the call status in the
+// response trailers.
+
+const (
+	// servicePath is the URL path prefix of the methods of the Eth service.
+	servicePath = "/eth.v1.Eth/"
+
+	// maxRequestSize is the maximum size of a request message.
+	maxRequestSize = 4 * 1024 * 1024
+)
+
+// gRPC status codes.
+const (
+	codeOK                = 0
+	codeCanceled          = 1
+	codeInvalidArgument   = 3
+	codeDeadlineExceeded  = 4
+	codeNotFound          = 5
+	codeResourceExhausted = 8
+	codeUnimplemented     = 12
+	codeInternal          = 13
+)
+
+// statusError is an error with a gRPC status code.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string { return e.message }
+
+func invalidArgument(format string, args ...interface{}) error {
+	return &statusError{codeInvalidArgument, fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &statusError{codeNotFound, fmt.Sprintf(format, args...)}
+}
+
+// errorStatus returns the gRPC status code and message of an error.
+func errorStatus(err error) (int, string) {
+	var status *statusError
+	switch {
+	case err == nil:
+		return codeOK, ""
+	case errors.As(err, &status):
+		return status.code, status.message
+	case errors.Is(err, context.DeadlineExceeded):
+		return codeDeadlineExceeded, err.Error()
+	case errors.Is(err, context.Canceled):
+		return codeCanceled, err.Error()
+	}
+	return codeInternal, err.Error()
+}
+
+// sendFunc sends a response message of a call.
+type sendFunc func(message) error
+
+// methodFunc serves a call of a method, decoding the request message and sending
+// the response messages. Unary methods send exactly one response.
+type methodFunc func(ctx context.Context, req []byte, send sendFunc) error
+
+// handler is the http.Handler serving the gRPC calls of the methods.
+type handler struct {
+	methods map[string]methodFunc
+	limiter rpc.CallLimiter   // Optional limits of the calls
+	limits  map[string]string // JSON-RPC methods of which the limits apply to the methods
+}
+
+// ServeHTTP implements http.Handler.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.ProtoMajor != 2 || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+
+	err := h.serve(w, r)
+	code, message := errorStatus(err)
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeGrpcMessage(message))
+	}
+}
+
+// serve reads the request of a call and runs its method.
+func (h *handler) serve(w http.ResponseWriter, r *http.Request) error {
+	name := strings.TrimPrefix(r.URL.Path, servicePath)
+	method, ok := h.methods[name]
+	if !ok || !strings.HasPrefix(r.URL.Path, servicePath) {
+		return &statusError{codeUnimplemented, fmt.Sprintf("unknown method %s", r.URL.Path)}
+	}
+	ctx := r.Context()
+	if h.limiter != nil {
+		release, err := h.limiter.Acquire(ctx, h.limits[name])
+		if err != nil {
+			return &statusError{codeResourceExhausted, err.Error()}
+		}
+		defer release()
+	}
+	if value := r.Header.Get("Grpc-Timeout"); value != "" {
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			return invalidArgument("%v", err)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := readMessage(r.Body)
+	if err != nil {
+		return err
+	}
+	flusher, _ := w.(http.Flusher)
+	return method(ctx, req, func(msg message) error {
+		if err := writeMessage(w, msg); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// readMessage reads a length-prefixed request message.
+func readMessage(r io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, invalidArgument("missing request message: %v", err)
+	}
+	if prefix[0] != 0 {
+		return nil, &statusError{codeUnimplemented, "compressed messages not supported"}
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxRequestSize {
+		return nil, invalidArgument("request message too large: %d bytes", size)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, invalidArgument("truncated request message: %v", err)
+	}
+	return msg, nil
+}
+
+// decodeRequest decodes a request message.
+func decodeRequest(b []byte, req interface{ unmarshal([]byte) error }) error {
+	if err := req.unmarshal(b); err != nil {
+		return invalidArgument("invalid request: %v", err)
+	}
+	return nil
+}
+
+// writeMessage writes a length-prefixed response message.
+func writeMessage(w io.Writer, msg message) error {
+	b := make([]byte, 5, 256)
+	b = msg.marshal(b)
+	binary.BigEndian.PutUint32(b[1:5], uint32(len(b)-5))
+	_, err := w.Write(b)
+	return err
+}
+
+// parseTimeout parses the value of the grpc-timeout header.
+func parseTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, fmt.Errorf("invalid timeout %q", value)
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid timeout unit %q", value)
+	}
+	n, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q", value)
+	}
+	// At most 8 digits, so only hours may overflow
+	if unit == time.Hour && n > uint64(1<<63-1)/uint64(time.Hour) {
+		return 1<<63 - 1, nil
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// encodeGrpcMessage percent-encodes a status message for the grpc-message
+// trailer.
+func encodeGrpcMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
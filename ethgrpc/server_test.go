@@ -0,0 +1,493 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethgrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// call sends a gRPC request to the server and returns the response messages
+// and the status trailers.
+func call(t *testing.T, url, method string, req []byte) ([][]byte, http.Header) {
+	t.Helper()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, network, addr)
+		},
+	}}
+	body := make([]byte, 5, 5+len(req))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(req)))
+	body = append(body, req...)
+
+	httpReq, _ := http.NewRequest(http.MethodPost, url+servicePath+method, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var msgs [][]byte
+	for {
+		var prefix [5]byte
+		if _, err := io.ReadFull(resp.Body, prefix[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read message prefix: %v", err)
+		}
+		msg := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+		if _, err := io.ReadFull(resp.Body, msg); err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, resp.Trailer
+}
+
+// Tests the gRPC framing of the requests and responses, and the call status.
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	h := &handler{methods: map[string]methodFunc{
+		"GetStorage": func(ctx context.Context, b []byte, send sendFunc) error {
+			var req getStorageRequest
+			if err := decodeRequest(b, &req); err != nil {
+				return err
+			}
+			for _, key := range req.keys {
+				if err := send(storageValuesMessage{key}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		"GetBlock": func(ctx context.Context, b []byte, send sendFunc) error {
+			return notFound("block %s not found", "100%")
+		},
+	}}
+	srv := httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+	defer srv.Close()
+
+	// Streamed responses
+	var req []byte
+	req = appendBytes(req, 1, common.Address{1}.Bytes())
+	req = appendBytes(req, 2, common.Hash{1}.Bytes())
+	req = appendBytes(req, 2, common.Hash{2}.Bytes())
+	msgs, trailer := call(t, srv.URL, "GetStorage", req)
+	if status := trailer.Get("Grpc-Status"); status != "0" {
+		t.Fatalf("status mismatch: have %q, want 0", status)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("message count mismatch: have %d, want 2", len(msgs))
+	}
+	for i, msg := range msgs {
+		want := storageValuesMessage{common.Hash{byte(i + 1)}}.marshal(nil)
+		if !bytes.Equal(msg, want) {
+			t.Errorf("message %d mismatch: have %x, want %x", i, msg, want)
+		}
+	}
+	// Errors
+	tests := []struct {
+		method  string
+		req     []byte
+		status  string
+		message string
+	}{
+		{"GetBlock", nil, "5", "block 100%25 not found"},
+		{"GetStorage", []byte{0x0a, 0x01, 0x01}, "3", "invalid request: invalid address length 1"},
+		{"Unknown", nil, "12", "unknown method /eth.v1.Eth/Unknown"},
+	}
+	for _, tt := range tests {
+		msgs, trailer := call(t, srv.URL, tt.method, tt.req)
+		if len(msgs) != 0 {
+			t.Errorf("%s: unexpected messages: %x", tt.method, msgs)
+		}
+		if status := trailer.Get("Grpc-Status"); status != tt.status {
+			t.Errorf("%s: status mismatch: have %q, want %q", tt.method, status, tt.status)
+		}
+		if message := trailer.Get("Grpc-Message"); message != tt.message {
+			t.Errorf("%s: message mismatch: have %q, want %q", tt.method, message, tt.message)
+		}
+	}
+}
+
+// testLimiter is a call limiter rejecting the calls of some JSON-RPC methods.
+type testLimiter struct {
+	reject   map[string]bool
+	acquired []string
+}
+
+func (l *testLimiter) Acquire(ctx context.Context, method string) (func(), error) {
+	if l.reject[method] {
+		return nil, errors.New("rate limit of " + method + " exceeded")
+	}
+	l.acquired = append(l.acquired, method)
+	return func() {}, nil
+}
+
+// Tests that the calls are subject to the limits of their JSON-RPC counterparts.
+func TestHandlerLimits(t *testing.T) {
+	t.Parallel()
+
+	serve := func(ctx context.Context, b []byte, send sendFunc) error {
+		return send(storageValuesMessage{})
+	}
+	limiter := &testLimiter{reject: map[string]bool{"eth_getLogs": true}}
+	h := &handler{
+		methods: map[string]methodFunc{"GetLogs": serve, "GetStorage": serve},
+		limiter: limiter,
+		limits:  rpcMethods,
+	}
+	srv := httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+	defer srv.Close()
+
+	msgs, trailer := call(t, srv.URL, "GetLogs", nil)
+	if len(msgs) != 0 {
+		t.Errorf("unexpected messages of rejected call: %x", msgs)
+	}
+	if status := trailer.Get("Grpc-Status"); status != "8" {
+		t.Errorf("status mismatch: have %q, want 8", status)
+	}
+	if message := trailer.Get("Grpc-Message"); message != "rate limit of eth_getLogs exceeded" {
+		t.Errorf("message mismatch: have %q", message)
+	}
+	if _, trailer := call(t, srv.URL, "GetStorage", nil); trailer.Get("Grpc-Status") != "0" {
+		t.Errorf("status mismatch: have %q, want 0", trailer.Get("Grpc-Status"))
+	}
+	if len(limiter.acquired) != 1 || limiter.acquired[0] != "eth_getStorageAt" {
+		t.Errorf("acquired limits mismatch: have %v", limiter.acquired)
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		timeout time.Duration
+		err     bool
+	}{
+		{value: "100m", timeout: 100 * time.Millisecond},
+		{value: "5S", timeout: 5 * time.Second},
+		{value: "2H", timeout: 2 * time.Hour},
+		{value: "99999999H", timeout: 1<<63 - 1},
+		{value: "10", err: true},
+		{value: "m", err: true},
+		{value: "123456789S", err: true},
+	}
+	for _, tt := range tests {
+		timeout, err := parseTimeout(tt.value)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: error mismatch: have %v, want error %v", tt.value, err, tt.err)
+		}
+		if timeout != tt.timeout {
+			t.Errorf("%q: timeout mismatch: have %v, want %v", tt.value, timeout, tt.timeout)
+		}
+	}
+}
+
+// Tests the decoding of the log filters, including the nested block references
+// and topic sets.
+func TestUnmarshalLogFilter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		from  = protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 100)
+		to    = protowire.AppendVarint(protowire.AppendTag(nil, 3, protowire.VarintType), tagFinalized)
+		set   = appendBytes(appendBytes(nil, 1, common.Hash{1}.Bytes()), 1, common.Hash{2}.Bytes())
+		input []byte
+	)
+	input = appendBytes(input, 1, from)
+	input = appendBytes(input, 2, to)
+	input = appendBytes(input, 4, common.Address{3}.Bytes())
+	input = protowire.AppendTag(input, 5, protowire.BytesType)
+	input = protowire.AppendBytes(input, nil)
+	input = appendBytes(input, 5, set)
+	input = appendUint(input, 99, 1) // Unknown fields are skipped
+
+	var f logFilter
+	if err := f.unmarshal(input); err != nil {
+		t.Fatal(err)
+	}
+	if number, ok := f.from.Number(); !ok || number != 100 {
+		t.Errorf("from block mismatch: have %v", f.from)
+	}
+	if number, ok := f.to.Number(); !ok || number != rpc.FinalizedBlockNumber {
+		t.Errorf("to block mismatch: have %v", f.to)
+	}
+	if f.blockHash != nil {
+		t.Errorf("unexpected block hash %x", *f.blockHash)
+	}
+	if len(f.addresses) != 1 || f.addresses[0] != (common.Address{3}) {
+		t.Errorf("addresses mismatch: have %x", f.addresses)
+	}
+	if len(f.topics) != 2 || len(f.topics[0]) != 0 || len(f.topics[1]) != 2 || f.topics[1][1] != (common.Hash{2}) {
+		t.Errorf("topics mismatch: have %x", f.topics)
+	}
+	// Invalid block references are rejected
+	bad := appendBytes(nil, 1, protowire.AppendVarint(protowire.AppendTag(nil, 3, protowire.VarintType), 42))
+	if err := new(logFilter).unmarshal(bad); err == nil {
+		t.Error("expected error for unknown block tag")
+	}
+}
+
+// testBackend is a backend of a chain, serving the methods used by the tracing.
+type testBackend struct {
+	ethapi.Backend // Other methods aren't used by the tests
+	db             ethdb.Database
+	chain          *core.BlockChain
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number < 0 {
+		return b.chain.CurrentHeader(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number < 0 {
+		number = rpc.BlockNumber(b.chain.CurrentBlock().Number.Int64())
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) BlockByNumberOrHash(ctx context.Context, ref rpc.BlockNumberOrHash) (*types.Block, error) {
+	if hash, ok := ref.Hash(); ok {
+		return b.BlockByHash(ctx, hash)
+	}
+	number, _ := ref.Number()
+	return b.BlockByNumber(ctx, number)
+}
+
+func (b *testBackend) GetTransaction(ctx context.Context, hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	tx, blockHash, number, index := rawdb.ReadTransaction(b.db, hash)
+	return tx, blockHash, number, index, nil
+}
+
+func (b *testBackend) RPCGasCap() uint64                     { return 25000000 }
+func (b *testBackend) ChainConfig() ctypes.ChainConfigurator { return b.chain.Config() }
+func (b *testBackend) Engine() consensus.Engine              { return b.chain.Engine() }
+func (b *testBackend) ChainDb() ethdb.Database               { return b.db }
+
+func (b *testBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, readOnly bool, preferDisk bool) (*state.StateDB, tracers.StateReleaseFunc, error) {
+	statedb, err := b.chain.StateAt(block.Root())
+	return statedb, func() {}, err
+}
+
+func (b *testBackend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (*core.Message, vm.BlockContext, *state.StateDB, tracers.StateReleaseFunc, error) {
+	statedb, release, err := b.StateAtBlock(ctx, b.chain.GetBlockByHash(block.ParentHash()), reexec, nil, true, false)
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, nil, err
+	}
+	signer := types.MakeSigner(b.chain.Config(), block.Number(), block.Time())
+	context := core.NewEVMBlockContext(block.Header(), b.chain, nil)
+	for i, tx := range block.Transactions() {
+		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
+		if i == txIndex {
+			return msg, context, statedb, release, nil
+		}
+		evm := vm.NewEVM(context, core.NewEVMTxContext(msg), statedb, b.chain.Config(), vm.Config{})
+		if _, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
+			return nil, vm.BlockContext{}, nil, nil, err
+		}
+		statedb.Finalise(true)
+	}
+	return nil, vm.BlockContext{}, nil, nil, fmt.Errorf("transaction index %d out of range", txIndex)
+}
+
+// decodeTraceResult decodes a TraceResult message.
+func decodeTraceResult(t *testing.T, b []byte) *traceResultMessage {
+	t.Helper()
+
+	msg := new(traceResultMessage)
+	err := decodeFields(b, func(num protowire.Number, typ protowire.Type, v uint64, raw []byte) (err error) {
+		switch num {
+		case 1:
+			msg.txHash, err = decodeHash(raw)
+		case 2:
+			msg.result = raw
+		case 3:
+			msg.err = string(raw)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to decode trace result: %v", err)
+	}
+	return msg
+}
+
+// Tests that the traces of blocks and transactions are the outputs of the
+// tracers, streamed in the order of the block.
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  genesisT.GenesisAlloc{addr: {Balance: big.NewInt(vars.Ether)}},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		txs    []*types.Transaction
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 1, func(i int, b *core.BlockGen) {
+		// A transfer, then a contract creation reverting with a word
+		transfer := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 0, To: &common.Address{1}, Value: big.NewInt(1), Gas: 21000, GasPrice: b.BaseFee()})
+		revert := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 1, Gas: 100000, GasPrice: b.BaseFee(), Data: common.FromHex("602a60005260206000fd")})
+		b.AddTx(transfer)
+		b.AddTx(revert)
+		txs = append(txs, transfer, revert)
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	backend := &testBackend{db: db, chain: chain}
+	s := &Service{backend: backend, tracer: tracers.NewAPI(backend)}
+	h := &handler{methods: map[string]methodFunc{
+		"TraceBlock":       s.traceBlock,
+		"TraceTransaction": s.traceTransaction,
+	}}
+	srv := httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+	defer srv.Close()
+
+	// The block traces are the struct logs of the transactions
+	msgs, trailer := call(t, srv.URL, "TraceBlock", appendBytes(nil, 1, appendUint(nil, 1, 1)))
+	if status := trailer.Get("Grpc-Status"); status != "0" {
+		t.Fatalf("status mismatch: have %q, want 0: %s", status, trailer.Get("Grpc-Message"))
+	}
+	if len(msgs) != len(txs) {
+		t.Fatalf("trace count mismatch: have %d, want %d", len(msgs), len(txs))
+	}
+	var traces []*traceResultMessage
+	for i, msg := range msgs {
+		trace := decodeTraceResult(t, msg)
+		if trace.txHash != txs[i].Hash() {
+			t.Errorf("trace %d: transaction mismatch: have %x, want %x", i, trace.txHash, txs[i].Hash())
+		}
+		if trace.err != "" {
+			t.Errorf("trace %d: unexpected error %q", i, trace.err)
+		}
+		var result logger.ExecutionResult
+		if err := json.Unmarshal(trace.result, &result); err != nil {
+			t.Fatalf("trace %d: invalid result: %v", i, err)
+		}
+		if failed := i == 1; result.Failed != failed {
+			t.Errorf("trace %d: failure mismatch: have %v, want %v", i, result.Failed, failed)
+		}
+		traces = append(traces, trace)
+	}
+	// The transaction trace matches the one of the block
+	msgs, trailer = call(t, srv.URL, "TraceTransaction", appendBytes(nil, 1, txs[1].Hash().Bytes()))
+	if status := trailer.Get("Grpc-Status"); status != "0" {
+		t.Fatalf("status mismatch: have %q, want 0: %s", status, trailer.Get("Grpc-Message"))
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("trace count mismatch: have %d, want 1", len(msgs))
+	}
+	if trace := decodeTraceResult(t, msgs[0]); !bytes.Equal(trace.result, traces[1].result) {
+		t.Errorf("transaction trace mismatch: have %s, want %s", trace.result, traces[1].result)
+	}
+	// The struct logger options of the request apply
+	config := appendBool(nil, 6, true) // Without the stack
+	msgs, _ = call(t, srv.URL, "TraceTransaction", appendBytes(appendBytes(nil, 1, txs[1].Hash().Bytes()), 2, config))
+	if len(msgs) != 1 {
+		t.Fatalf("trace count mismatch: have %d, want 1", len(msgs))
+	}
+	var result logger.ExecutionResult
+	if err := json.Unmarshal(decodeTraceResult(t, msgs[0]).result, &result); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if len(result.StructLogs) == 0 {
+		t.Fatal("missing struct logs")
+	}
+	for i, log := range result.StructLogs {
+		if log.Stack != nil {
+			t.Fatalf("struct log %d has a stack", i)
+		}
+	}
+	// Unknown blocks and transactions aren't found
+	tests := []struct {
+		method string
+		req    []byte
+	}{
+		{"TraceBlock", appendBytes(nil, 1, appendUint(nil, 1, 2))},
+		{"TraceTransaction", appendBytes(nil, 1, common.Hash{1}.Bytes())},
+	}
+	for _, tt := range tests {
+		msgs, trailer := call(t, srv.URL, tt.method, tt.req)
+		if len(msgs) != 0 {
+			t.Errorf("%s: unexpected messages: %x", tt.method, msgs)
+		}
+		if status := trailer.Get("Grpc-Status"); status != "5" {
+			t.Errorf("%s: status mismatch: have %q, want 5", tt.method, status)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ethgrpc implements a gRPC service of the chain data, as an alternative
+// to JSON-RPC for clients fetching large amounts of blocks, receipts, logs and
+// traces. The service schema is in eth.proto.
+//
+// The messages are encoded by hand with protowire instead of by code generated
+// from eth.proto: the service is served over net/http without depending on the
+// gRPC library and its code generator, and the messages are encoded straight
+// from the core types without being copied into generated structs first.
+// TestProtoSchema checks the codec against eth.proto.
+//
+// The calls are subject to the API keys and rate limits of the node's RPC
+// endpoints, which apply to each method as to its JSON-RPC counterpart.
+package ethgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	// DefaultHost is the default interface the gRPC server listens on.
+	DefaultHost = "localhost"
+
+	// DefaultPort is the default port the gRPC server listens on.
+	DefaultPort = 8549
+
+	// maxTopics is the maximum number of topic positions of a log filter.
+	maxTopics = 4
+
+	// logsChunkSize is the number of blocks of which GetLogs gathers the logs
+	// at once, streaming them chunk by chunk.
+	logsChunkSize = 1024
+
+	// Buffer sizes of the subscription channels.
+	headsChanSize = 16
+	logsChanSize  = 16
+)
+
+// Backend is the backend of the service, serving the chain data as to the eth
+// namespace and the traces as to the debug namespace.
+type Backend interface {
+	ethapi.Backend
+	tracers.Backend
+}
+
+// Service is the gRPC server of the chain data, run as a node lifecycle.
+type Service struct {
+	stack   *node.Node
+	backend ethapi.Backend
+	tracer  *tracers.API
+	filters *filters.FilterSystem
+	events  *filters.EventSystem
+	addr    string
+
+	server *http.Server
+	cancel context.CancelFunc // Cancels the calls in progress on shutdown
+}
+
+// New creates the gRPC service on top of the given backend and registers it on
+// the node, listening on the given host and port once the node starts.
+func New(stack *node.Node, backend Backend, filterSystem *filters.FilterSystem, host string, port int) (*Service, error) {
+	if filterSystem == nil {
+		return nil, errors.New("gRPC service requires a filter system")
+	}
+	s := &Service{
+		stack:   stack,
+		backend: backend,
+		tracer:  tracers.NewAPI(backend),
+		filters: filterSystem,
+		addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+	}
+	stack.RegisterLifecycle(s)
+	return s, nil
+}
+
+// Start implements node.Lifecycle, starting the gRPC server.
+func (s *Service) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.events = filters.NewEventSystem(s.filters, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.server = &http.Server{
+		Handler:     h2c.NewHandler(s.stack.RPCLimitHandler(s.handler()), &http2.Server{}),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go s.server.Serve(listener)
+	log.Info("gRPC endpoint opened", "url", "http://"+listener.Addr().String())
+	return nil
+}
+
+// Stop implements node.Lifecycle, cancelling the calls in progress and closing
+// the gRPC server.
+func (s *Service) Stop() error {
+	s.cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.server.Shutdown(ctx)
+	s.server.Close()
+	log.Info("gRPC endpoint closed", "addr", s.addr)
+	return nil
+}
+
+// rpcMethods are the JSON-RPC counterparts of the gRPC methods, of which the
+// RPC limits apply to the gRPC calls.
+var rpcMethods = map[string]string{
+	"GetBlock":              "eth_getBlockByNumber",
+	"GetReceipts":           "eth_getBlockReceipts",
+	"GetTransactionReceipt": "eth_getTransactionReceipt",
+	"GetLogs":               "eth_getLogs",
+	"GetAccount":            "eth_getBalance",
+	"GetStorage":            "eth_getStorageAt",
+	"SubscribeHeads":        "eth_subscribe",
+	"SubscribeLogs":         "eth_subscribe",
+	"TraceBlock":            "debug_traceBlockByHash",
+	"TraceTransaction":      "debug_traceTransaction",
+}
+
+// handler returns the http.Handler of the gRPC calls.
+func (s *Service) handler() *handler {
+	return &handler{
+		methods: map[string]methodFunc{
+			"GetBlock":              s.getBlock,
+			"GetReceipts":           s.getReceipts,
+			"GetTransactionReceipt": s.getTransactionReceipt,
+			"GetLogs":               s.getLogs,
+			"GetAccount":            s.getAccount,
+			"GetStorage":            s.getStorage,
+			"SubscribeHeads":        s.subscribeHeads,
+			"SubscribeLogs":         s.subscribeLogs,
+			"TraceBlock":            s.traceBlock,
+			"TraceTransaction":      s.traceTransaction,
+		},
+		limiter: s.stack.RPCCallLimiter(),
+		limits:  rpcMethods,
+	}
+}
+
+func (s *Service) getBlock(ctx context.Context, b []byte, send sendFunc) error {
+	var req getBlockRequest
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	block, err := s.backend.BlockByNumberOrHash(ctx, req.block)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return notFound("block not found")
+	}
+	msg := &blockMessage{block: block}
+	if req.full {
+		signer := types.MakeSigner(s.backend.ChainConfig(), block.Number(), block.Time())
+		msg.txs = make([]*transactionMessage, 0, len(block.Transactions()))
+		for i, tx := range block.Transactions() {
+			raw, err := tx.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			from, _ := types.Sender(signer, tx)
+			msg.txs = append(msg.txs, &transactionMessage{tx: tx, raw: raw, from: from, index: uint64(i)})
+		}
+	}
+	return send(msg)
+}
+
+func (s *Service) getReceipts(ctx context.Context, b []byte, send sendFunc) error {
+	var req getReceiptsRequest
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	block, err := s.backend.BlockByNumberOrHash(ctx, req.block)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return notFound("block not found")
+	}
+	receipts, err := s.backend.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return err
+	}
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return notFound("receipts not found")
+	}
+	signer := types.MakeSigner(s.backend.ChainConfig(), block.Number(), block.Time())
+	msg := make(receiptsMessage, len(receipts))
+	for i, receipt := range receipts {
+		msg[i] = newReceiptMessage(receipt, txs[i], signer)
+	}
+	return send(msg)
+}
+
+func (s *Service) getTransactionReceipt(ctx context.Context, b []byte, send sendFunc) error {
+	var req getTransactionReceiptRequest
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	tx, blockHash, _, index, err := s.backend.GetTransaction(ctx, req.hash)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return notFound("transaction not found")
+	}
+	header, err := s.backend.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return err
+	}
+	receipts, err := s.backend.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return err
+	}
+	if header == nil || uint64(len(receipts)) <= index {
+		return notFound("receipt not found")
+	}
+	signer := types.MakeSigner(s.backend.ChainConfig(), header.Number, header.Time)
+	return send(newReceiptMessage(receipts[index], tx, signer))
+}
+
+// newReceiptMessage creates the message of the receipt of a transaction.
+func newReceiptMessage(receipt *types.Receipt, tx *types.Transaction, signer types.Signer) *receiptMessage {
+	from, _ := types.Sender(signer, tx)
+	return &receiptMessage{receipt: receipt, from: from, to: tx.To()}
+}
+
+func (s *Service) getLogs(ctx context.Context, b []byte, send sendFunc) error {
+	var req logFilter
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	if len(req.topics) > maxTopics {
+		return invalidArgument("too many topics: %d", len(req.topics))
+	}
+	if req.blockHash != nil {
+		logs, err := s.filters.NewBlockFilter(*req.blockHash, req.addresses, req.topics).Logs(ctx)
+		if err != nil {
+			return err
+		}
+		return sendLogs(logs, send)
+	}
+	begin, err := s.blockNumber(ctx, req.from)
+	if err != nil {
+		return err
+	}
+	end, err := s.blockNumber(ctx, req.to)
+	if err != nil {
+		return err
+	}
+	if begin > 0 && end > 0 && begin > end {
+		return invalidArgument("invalid block range %d-%d", begin, end)
+	}
+	// Stream the logs chunk by chunk up to the last full chunk of the range,
+	// then the remaining ones up to the requested end, which may be a tag.
+	last := s.lastBlock(ctx, end)
+	for ; begin >= 0 && begin+logsChunkSize-1 < last; begin += logsChunkSize {
+		logs, err := s.filters.NewRangeFilter(begin, begin+logsChunkSize-1, req.addresses, req.topics).Logs(ctx)
+		if err != nil {
+			return err
+		}
+		if err := sendLogs(logs, send); err != nil {
+			return err
+		}
+	}
+	logs, err := s.filters.NewRangeFilter(begin, end, req.addresses, req.topics).Logs(ctx)
+	if err != nil {
+		return err
+	}
+	return sendLogs(logs, send)
+}
+
+// sendLogs sends the messages of logs.
+func sendLogs(logs []*types.Log, send sendFunc) error {
+	for _, log := range logs {
+		if err := send(logMessage{log}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastBlock returns the number of the last block of a log filter range ending
+// at the given block number or tag, or -1 if the tag doesn't resolve.
+func (s *Service) lastBlock(ctx context.Context, end int64) int64 {
+	if end >= 0 {
+		return end
+	}
+	number := rpc.BlockNumber(end)
+	if number == rpc.PendingBlockNumber {
+		number = rpc.LatestBlockNumber
+	}
+	header, _ := s.backend.HeaderByNumber(ctx, number)
+	if header == nil {
+		return -1
+	}
+	return header.Number.Int64()
+}
+
+// blockNumber returns the number of a block reference of a log filter, which
+// may also be one of the tags resolved by the filter.
+func (s *Service) blockNumber(ctx context.Context, ref rpc.BlockNumberOrHash) (int64, error) {
+	if number, ok := ref.Number(); ok {
+		return number.Int64(), nil
+	}
+	hash, _ := ref.Hash()
+	header, err := s.backend.HeaderByHash(ctx, hash)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, notFound("block %x not found", hash)
+	}
+	return header.Number.Int64(), nil
+}
+
+func (s *Service) getAccount(ctx context.Context, b []byte, send sendFunc) error {
+	var req getAccountRequest
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	state, _, err := s.backend.StateAndHeaderByNumberOrHash(ctx, req.block)
+	if state == nil || err != nil {
+		return stateError(err)
+	}
+	msg := &accountMessage{
+		balance: state.GetBalance(req.address),
+		nonce:   state.GetNonce(req.address),
+		code:    state.GetCode(req.address),
+	}
+	if state.Exist(req.address) {
+		msg.codeHash = state.GetCodeHash(req.address)
+	}
+	return send(msg)
+}
+
+func (s *Service) getStorage(ctx context.Context, b []byte, send sendFunc) error {
+	var req getStorageRequest
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	state, _, err := s.backend.StateAndHeaderByNumberOrHash(ctx, req.block)
+	if state == nil || err != nil {
+		return stateError(err)
+	}
+	msg := make(storageValuesMessage, len(req.keys))
+	for i, key := range req.keys {
+		msg[i] = state.GetState(req.address, key)
+	}
+	return send(msg)
+}
+
+// stateError returns the error of a failed state lookup.
+func stateError(err error) error {
+	if err == nil {
+		return notFound("state not found")
+	}
+	return err
+}
+
+func (s *Service) subscribeHeads(ctx context.Context, b []byte, send sendFunc) error {
+	if err := decodeRequest(b, new(emptyRequest)); err != nil {
+		return err
+	}
+	headers := make(chan *types.Header, headsChanSize)
+	sub := s.events.SubscribeNewHeads(headers)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case header := <-headers:
+			if err := send(headerMessage{header}); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) subscribeLogs(ctx context.Context, b []byte, send sendFunc) error {
+	var req logFilter
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	if len(req.topics) > maxTopics {
+		return invalidArgument("too many topics: %d", len(req.topics))
+	}
+	logs := make(chan []*types.Log, logsChanSize)
+	sub, err := s.events.SubscribeLogs(ethereum.FilterQuery{Addresses: req.addresses, Topics: req.topics}, logs)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case batch := <-logs:
+			for _, log := range batch {
+				if err := send(logMessage{log}); err != nil {
+					return err
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) traceBlock(ctx context.Context, b []byte, send sendFunc) error {
+	var req traceBlockRequest
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	block, err := s.backend.BlockByNumberOrHash(ctx, req.block)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return notFound("block not found")
+	}
+	results, err := s.tracer.TraceBlockByHash(ctx, block.Hash(), req.config)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		msg := &traceResultMessage{txHash: result.TxHash, err: result.Error}
+		if result.Result != nil {
+			if msg.result, err = json.Marshal(result.Result); err != nil {
+				return err
+			}
+		}
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) traceTransaction(ctx context.Context, b []byte, send sendFunc) error {
+	var req traceTransactionRequest
+	if err := decodeRequest(b, &req); err != nil {
+		return err
+	}
+	tx, _, _, _, err := s.backend.GetTransaction(ctx, req.hash)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return notFound("transaction not found")
+	}
+	result, err := s.tracer.TraceTransaction(ctx, req.hash, req.config)
+	if err != nil {
+		return err
+	}
+	msg := &traceResultMessage{txHash: req.hash}
+	if msg.result, err = json.Marshal(result); err != nil {
+		return err
+	}
+	return send(msg)
+}
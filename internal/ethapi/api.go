@@ -39,9 +39,11 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -84,6 +86,52 @@ func (s *EthereumAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, e
 	return (*hexutil.Big)(tipcap), err
 }
 
+// gasPriceLevel is a gas price suggestion with its expected inclusion delay.
+type gasPriceLevel struct {
+	GasPrice             *hexutil.Big   `json:"gasPrice"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	Blocks               hexutil.Uint64 `json:"blocks"`        // Expected number of blocks until inclusion
+	ExpectedDelay        hexutil.Uint64 `json:"expectedDelay"` // Expected seconds until inclusion
+}
+
+type gasPriceLevelsResult struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Legacy      bool           `json:"legacy"`
+	BaseFee     *hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	BlockTime   hexutil.Uint64 `json:"blockTime"`
+	Slow        *gasPriceLevel `json:"slow"`
+	Standard    *gasPriceLevel `json:"standard"`
+	Fast        *gasPriceLevel `json:"fast"`
+}
+
+// GasPriceLevels returns slow, standard and fast gas price suggestions based on
+// recent blocks and the pending transactions, along with the expected delay
+// until a transaction paying them is included. On chains without EIP-1559 the
+// suggestions are plain legacy gas prices.
+func (s *EthereumAPI) GasPriceLevels(ctx context.Context) (*gasPriceLevelsResult, error) {
+	levels, err := s.b.SuggestGasPriceLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	marshal := func(level *gasprice.PriceLevel) *gasPriceLevel {
+		return &gasPriceLevel{
+			GasPrice:             (*hexutil.Big)(level.GasPrice),
+			MaxPriorityFeePerGas: (*hexutil.Big)(level.TipCap),
+			Blocks:               hexutil.Uint64(level.Blocks),
+			ExpectedDelay:        hexutil.Uint64(level.Wait / time.Second),
+		}
+	}
+	return &gasPriceLevelsResult{
+		BlockNumber: hexutil.Uint64(levels.Number),
+		Legacy:      levels.Legacy,
+		BaseFee:     (*hexutil.Big)(levels.BaseFee),
+		BlockTime:   hexutil.Uint64(levels.BlockTime / time.Second),
+		Slow:        marshal(levels.Slow),
+		Standard:    marshal(levels.Standard),
+		Fast:        marshal(levels.Fast),
+	}, nil
+}
+
 type feeHistoryResult struct {
 	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
 	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
@@ -213,13 +261,80 @@ func (s *TxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RPCT
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
+// Status returns the number of pending and queued transaction in the pool, along
+// with the status of the per-origin admission quotas.
 func (s *TxPoolAPI) Status() map[string]hexutil.Uint {
 	pending, queue := s.b.Stats()
+	quota := s.b.TxPoolQuotaStats()
 	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(pending),
-		"queued":  hexutil.Uint(queue),
+		"pending":         hexutil.Uint(pending),
+		"queued":          hexutil.Uint(queue),
+		"origins":         hexutil.Uint(quota.Origins),
+		"originLimited":   hexutil.Uint(quota.RateLimited),
+		"originOverflows": hexutil.Uint(quota.Overflowed),
+	}
+}
+
+// HistoryArgs are the filters of a txpool_history query.
+type HistoryArgs struct {
+	Hash  *common.Hash    `json:"hash"`
+	From  *common.Address `json:"from"`
+	Limit *hexutil.Uint   `json:"limit"`
+}
+
+// RPCHistoryEntry is a transaction dropped from the pool, as returned by txpool_history.
+type RPCHistoryEntry struct {
+	Hash       common.Hash       `json:"hash"`
+	From       common.Address    `json:"from"`
+	Nonce      hexutil.Uint64    `json:"nonce"`
+	Local      bool              `json:"local"`
+	Added      hexutil.Uint64    `json:"added"`
+	Removed    hexutil.Uint64    `json:"removed"`
+	Reason     txpool.DropReason `json:"reason"`
+	ReplacedBy *common.Hash      `json:"replacedBy,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// defaultHistoryLimit is the number of entries returned by txpool_history if
+// no limit is requested.
+const defaultHistoryLimit = 100
+
+// History returns the transactions most recently dropped from the pool, newest
+// first, along with the reason they were dropped. The results can be filtered
+// by transaction hash or sender.
+func (s *TxPoolAPI) History(args *HistoryArgs) []*RPCHistoryEntry {
+	var (
+		hash  *common.Hash
+		from  *common.Address
+		limit = defaultHistoryLimit
+	)
+	if args != nil {
+		hash, from = args.Hash, args.From
+		if args.Limit != nil {
+			limit = int(*args.Limit)
+		}
 	}
+	entries := s.b.TxPoolHistory(hash, from, limit)
+
+	result := make([]*RPCHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		rpcEntry := &RPCHistoryEntry{
+			Hash:    entry.Hash,
+			From:    entry.From,
+			Nonce:   hexutil.Uint64(entry.Nonce),
+			Local:   entry.Local,
+			Added:   hexutil.Uint64(entry.Added),
+			Removed: hexutil.Uint64(entry.Removed),
+			Reason:  entry.Reason,
+			Error:   entry.Error,
+		}
+		if entry.ReplacedBy != (common.Hash{}) {
+			replacedBy := entry.ReplacedBy
+			rpcEntry.ReplacedBy = &replacedBy
+		}
+		result = append(result, rpcEntry)
+	}
+	return result
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
@@ -40,9 +40,11 @@ import (
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/blocktest"
@@ -449,6 +451,9 @@ func (b testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 func (b testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
 	return nil, nil, nil, nil, nil
 }
+func (b testBackend) SuggestGasPriceLevels(ctx context.Context) (*gasprice.PriceLevels, error) {
+	panic("implement me")
+}
 func (b testBackend) ChainDb() ethdb.Database           { return b.db }
 func (b testBackend) AccountManager() *accounts.Manager { return nil }
 func (b testBackend) ExtRPCEnabled() bool               { return false }
@@ -580,6 +585,12 @@ func (b testBackend) TxPoolContent() (map[common.Address][]*types.Transaction, m
 func (b testBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
 	panic("implement me")
 }
+func (b testBackend) TxPoolHistory(hash *common.Hash, from *common.Address, limit int) []*txpool.HistoryEntry {
+	panic("implement me")
+}
+func (b testBackend) TxPoolQuotaStats() txpool.QuotaStats {
+	panic("implement me")
+}
 func (b testBackend) SubscribeNewTxsEvent(events chan<- core.NewTxsEvent) event.Subscription {
 	panic("implement me")
 }
@@ -597,6 +608,9 @@ func (b testBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscripti
 func (b testBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	panic("implement me")
 }
+func (b testBackend) SubscribeTxLifecycleEvent(ch chan<- []*txpool.LifecycleEvent) event.Subscription {
+	panic("implement me")
+}
 func (b testBackend) BloomStatus() (uint64, uint64) { panic("implement me") }
 func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	panic("implement me")
@@ -915,6 +929,113 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// Tests that eth_simulateV1 chains the simulated blocks, fills gaps between
+// their numbers and reports the transfers, logs and reverts of the calls.
+func TestSimulateV1(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(1)
+		genesis  = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: genesisT.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(vars.Ether)},
+			},
+		}
+		randomAccounts = newAccounts(3)
+		backend        = newTestBackend(t, 10, genesis, ethash.NewFaker(), nil)
+		api            = NewBlockChainAPI(backend)
+		latest         = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	base, err := backend.HeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("failed to retrieve head: %v", err)
+	}
+	var (
+		number = (*hexutil.Big)(big.NewInt(13))
+		time   = hexutil.Uint64(base.Time + 100)
+		opts   = SimOpts{
+			TraceTransfers: true,
+			BlockStateCalls: []SimBlock{
+				{
+					// Fund an account without any balance
+					Calls: []TransactionArgs{{
+						From:  &accounts[0].addr,
+						To:    &randomAccounts[0].addr,
+						Value: (*hexutil.Big)(big.NewInt(1000)),
+					}},
+				},
+				{
+					// Skip a block and read the overridden timestamp
+					BlockOverrides: &BlockOverrides{Number: number, Time: &time},
+					StateOverrides: &StateOverride{
+						randomAccounts[1].addr: OverrideAccount{Code: hex2Bytes("4260005260206000f3")}, // return TIMESTAMP
+					},
+					Calls: []TransactionArgs{{
+						From: &randomAccounts[0].addr,
+						To:   &randomAccounts[1].addr,
+					}},
+				},
+				{
+					StateOverrides: &StateOverride{
+						randomAccounts[2].addr: OverrideAccount{Code: hex2Bytes("602a60005260206000fd")}, // revert with 42
+					},
+					Calls: []TransactionArgs{{
+						From: &randomAccounts[0].addr,
+						To:   &randomAccounts[2].addr,
+					}},
+				},
+			},
+		}
+	)
+	results, err := api.SimulateV1(context.Background(), opts, &latest)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("block count mismatch: have %d, want %d", len(results), 4)
+	}
+	parent := base.Hash()
+	for i, result := range results {
+		if have, want := result.Block.Number.ToInt().Uint64(), base.Number.Uint64()+uint64(i)+1; have != want {
+			t.Errorf("block %d: number mismatch: have %d, want %d", i, have, want)
+		}
+		if result.Block.ParentHash != parent {
+			t.Errorf("block %d: parent hash mismatch: have %x, want %x", i, result.Block.ParentHash, parent)
+		}
+		parent = *result.Block.Hash
+	}
+	// The transfer is traced as a log
+	transfer := results[0].Calls[0]
+	if transfer.Status != hexutil.Uint64(types.ReceiptStatusSuccessful) {
+		t.Fatalf("transfer failed: %v", transfer.Error)
+	}
+	if len(transfer.Logs) != 1 || transfer.Logs[0].Address != transferAddress || transfer.Logs[0].BlockHash != *results[0].Block.Hash {
+		t.Errorf("transfer log mismatch: %v", transfer.Logs)
+	}
+	// The gap is filled with an empty block
+	if len(results[1].Calls) != 0 {
+		t.Errorf("filler block has %d calls", len(results[1].Calls))
+	}
+	if have := new(big.Int).SetBytes(results[2].Calls[0].ReturnData).Uint64(); have != uint64(time) {
+		t.Errorf("timestamp mismatch: have %d, want %d", have, uint64(time))
+	}
+	if have, want := uint64(results[3].Block.Timestamp), uint64(time)+simulateBlockTime; have != want {
+		t.Errorf("default timestamp mismatch: have %d, want %d", have, want)
+	}
+	revert := results[3].Calls[0]
+	if revert.Status != hexutil.Uint64(types.ReceiptStatusFailed) || revert.Error == nil || revert.Error.Code != 3 {
+		t.Errorf("revert mismatch: status %d, error %v", revert.Status, revert.Error)
+	}
+	if _, err := json.Marshal(results); err != nil {
+		t.Errorf("failed to marshal results: %v", err)
+	}
+	// Blocks must be in order
+	opts.BlockStateCalls[2].BlockOverrides = &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(12))}
+	if _, err := api.SimulateV1(context.Background(), opts, &latest); err == nil {
+		t.Error("expected error for out of order blocks")
+	}
+}
+
 type Account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
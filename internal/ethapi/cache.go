@@ -0,0 +1,358 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	responseCacheHitMeter  = metrics.NewRegisteredMeter("rpc/cache/hit", nil)
+	responseCacheMissMeter = metrics.NewRegisteredMeter("rpc/cache/miss", nil)
+	responseCacheSizeGauge = metrics.NewRegisteredGauge("rpc/cache/size", nil)
+)
+
+// cacheLocator tells how to find the block a cacheable call result belongs to.
+type cacheLocator int
+
+const (
+	locateResult cacheLocator = iota // By the block fields of the result
+	locateBlock                      // By the block number or hash of the first parameter
+	locateTx                         // By the transaction hash of the first parameter
+)
+
+// cacheableMethods are the methods whose results are cached once the block they
+// belong to is deep enough in the canonical chain.
+var cacheableMethods = map[string]cacheLocator{
+	"eth_getBlockByHash":                      locateResult,
+	"eth_getBlockByNumber":                    locateResult,
+	"eth_getTransactionByHash":                locateResult,
+	"eth_getTransactionByBlockHashAndIndex":   locateResult,
+	"eth_getTransactionByBlockNumberAndIndex": locateResult,
+	"eth_getTransactionReceipt":               locateResult,
+	"eth_getBlockReceipts":                    locateBlock,
+	"eth_getBlockTransactionCountByHash":      locateBlock,
+	"eth_getBlockTransactionCountByNumber":    locateBlock,
+	"eth_getUncleByBlockHashAndIndex":         locateBlock,
+	"eth_getUncleByBlockNumberAndIndex":       locateBlock,
+	"eth_getUncleCountByBlockHash":            locateBlock,
+	"eth_getUncleCountByBlockNumber":          locateBlock,
+	"debug_traceBlockByHash":                  locateBlock,
+	"debug_traceBlockByNumber":                locateBlock,
+	"debug_traceTransaction":                  locateTx,
+	"trace_block":                             locateBlock,
+	"trace_transaction":                       locateTx,
+}
+
+// cacheEntry is a cached call result.
+type cacheEntry struct {
+	block  common.Hash
+	result json.RawMessage
+}
+
+// ResponseCache is an rpc.ResponseCache of the results of historical queries,
+// which cannot change once their block is deep enough in the canonical chain.
+// Entries are keyed by method and canonical parameters, and dropped when their
+// block is reorged out of the chain.
+type ResponseCache struct {
+	b       Backend
+	depth   uint64 // Confirmations of a block before its results are cached
+	maxSize int    // Maximum size of the cached results in bytes
+
+	lock    sync.Mutex
+	entries lru.BasicLRU[string, *cacheEntry]
+	blocks  map[common.Hash]map[string]struct{} // Keys of the entries of each block
+	size    int
+
+	sub  event.Subscription
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewResponseCache creates a response cache of the results of the blocks with
+// the given number of confirmations, bounded to the given size in bytes.
+func NewResponseCache(b Backend, depth uint64, maxSize int) *ResponseCache {
+	return &ResponseCache{
+		b:       b,
+		depth:   depth,
+		maxSize: maxSize,
+		entries: lru.NewBasicLRU[string, *cacheEntry](math.MaxInt32),
+		blocks:  make(map[common.Hash]map[string]struct{}),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting to drop the entries of reorged
+// blocks.
+func (c *ResponseCache) Start() error {
+	sideCh := make(chan core.ChainSideEvent, 16)
+	c.sub = c.b.SubscribeChainSideEvent(sideCh)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case ev := <-sideCh:
+				c.drop(ev.Block.Hash())
+			case <-c.sub.Err():
+				return
+			case <-c.quit:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements node.Lifecycle.
+func (c *ResponseCache) Stop() error {
+	close(c.quit)
+	c.sub.Unsubscribe()
+	c.wg.Wait()
+	return nil
+}
+
+// Get implements rpc.ResponseCache.
+func (c *ResponseCache) Get(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, bool) {
+	if _, ok := cacheableMethods[method]; !ok {
+		return nil, false
+	}
+	key, ok := cacheKey(method, params)
+	if !ok {
+		return nil, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		responseCacheMissMeter.Mark(1)
+		return nil, false
+	}
+	responseCacheHitMeter.Mark(1)
+	return entry.result, true
+}
+
+// Put implements rpc.ResponseCache, caching the result if its block is a
+// canonical block with enough confirmations.
+func (c *ResponseCache) Put(ctx context.Context, method string, params json.RawMessage, result json.RawMessage) {
+	locator, ok := cacheableMethods[method]
+	if !ok || len(result) > c.maxSize {
+		return
+	}
+	key, ok := cacheKey(method, params)
+	if !ok {
+		return
+	}
+	number, hash, ok := c.locate(ctx, locator, params, result)
+	if !ok || number+c.depth > c.b.CurrentHeader().Number.Uint64() {
+		return
+	}
+	header, err := c.b.HeaderByNumber(ctx, rpc.BlockNumber(number))
+	if err != nil || header == nil || header.Hash() != hash {
+		return
+	}
+	c.add(key, &cacheEntry{block: hash, result: result})
+}
+
+// add inserts an entry, evicting the least recently used ones beyond the
+// size bound.
+func (c *ResponseCache) add(key string, entry *cacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.entries.Contains(key) {
+		return
+	}
+	c.entries.Add(key, entry)
+	if c.blocks[entry.block] == nil {
+		c.blocks[entry.block] = make(map[string]struct{})
+	}
+	c.blocks[entry.block][key] = struct{}{}
+	c.size += len(key) + len(entry.result)
+
+	for c.size > c.maxSize {
+		key, entry, ok := c.entries.RemoveOldest()
+		if !ok {
+			break
+		}
+		c.forget(key, entry)
+	}
+	responseCacheSizeGauge.Update(int64(c.size))
+}
+
+// drop removes the entries of a block.
+func (c *ResponseCache) drop(block common.Hash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key := range c.blocks[block] {
+		if entry, ok := c.entries.Peek(key); ok {
+			c.entries.Remove(key)
+			c.forget(key, entry)
+		}
+	}
+	responseCacheSizeGauge.Update(int64(c.size))
+}
+
+// forget updates the block index and the size after an entry was removed.
+func (c *ResponseCache) forget(key string, entry *cacheEntry) {
+	delete(c.blocks[entry.block], key)
+	if len(c.blocks[entry.block]) == 0 {
+		delete(c.blocks, entry.block)
+	}
+	c.size -= len(key) + len(entry.result)
+}
+
+// locate returns the number and hash of the block a call result belongs to.
+func (c *ResponseCache) locate(ctx context.Context, locator cacheLocator, params, result json.RawMessage) (uint64, common.Hash, bool) {
+	switch locator {
+	case locateResult:
+		return resultBlock(result)
+
+	case locateBlock:
+		var args []json.RawMessage
+		if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+			return 0, common.Hash{}, false
+		}
+		var hash common.Hash
+		if err := json.Unmarshal(args[0], &hash); err == nil {
+			header, err := c.b.HeaderByHash(ctx, hash)
+			if err != nil || header == nil {
+				return 0, common.Hash{}, false
+			}
+			return header.Number.Uint64(), hash, true
+		}
+		var number rpc.BlockNumber
+		if err := json.Unmarshal(args[0], &number); err != nil || number < 0 {
+			return 0, common.Hash{}, false
+		}
+		header, err := c.b.HeaderByNumber(ctx, number)
+		if err != nil || header == nil {
+			return 0, common.Hash{}, false
+		}
+		return header.Number.Uint64(), header.Hash(), true
+
+	case locateTx:
+		var args []common.Hash
+		if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+			return 0, common.Hash{}, false
+		}
+		tx, block, number, _, err := c.b.GetTransaction(ctx, args[0])
+		if err != nil || tx == nil {
+			return 0, common.Hash{}, false
+		}
+		return number, block, true
+	}
+	return 0, common.Hash{}, false
+}
+
+// resultBlock returns the block of a block, transaction or receipt result, or
+// of the first element of a list of them.
+func resultBlock(result json.RawMessage) (uint64, common.Hash, bool) {
+	if bytes.HasPrefix(bytes.TrimSpace(result), []byte("[")) {
+		var list []json.RawMessage
+		if err := json.Unmarshal(result, &list); err != nil || len(list) == 0 {
+			return 0, common.Hash{}, false
+		}
+		result = list[0]
+	}
+	var fields struct {
+		BlockHash   *common.Hash    `json:"blockHash"`
+		BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+		Hash        *common.Hash    `json:"hash"`
+		Number      *hexutil.Uint64 `json:"number"`
+	}
+	if err := json.Unmarshal(result, &fields); err != nil {
+		return 0, common.Hash{}, false
+	}
+	switch {
+	case fields.BlockHash != nil && fields.BlockNumber != nil:
+		return uint64(*fields.BlockNumber), *fields.BlockHash, true
+	case fields.Hash != nil && fields.Number != nil:
+		return uint64(*fields.Number), *fields.Hash, true
+	}
+	return 0, common.Hash{}, false
+}
+
+// mutableTags are the block tags whose block changes with the chain head, the
+// calls referring to them are never cached.
+var mutableTags = map[string]struct{}{
+	"latest":    {},
+	"pending":   {},
+	"safe":      {},
+	"finalized": {},
+}
+
+// cacheKey returns the cache key of a call, made of the method and of the
+// compacted parameters with lowercase hex strings.
+func cacheKey(method string, params json.RawMessage) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+
+	var args []interface{}
+	if len(params) > 0 {
+		if err := dec.Decode(&args); err != nil {
+			return "", false
+		}
+	}
+	for _, arg := range args {
+		if tag, ok := arg.(string); ok {
+			if _, ok := mutableTags[tag]; ok {
+				return "", false
+			}
+		}
+	}
+	enc, err := json.Marshal(canonicalParam(args))
+	if err != nil {
+		return "", false
+	}
+	return method + string(enc), true
+}
+
+// canonicalParam lowercases the hex strings of a decoded parameter.
+func canonicalParam(param interface{}) interface{} {
+	switch param := param.(type) {
+	case string:
+		if strings.HasPrefix(param, "0x") || strings.HasPrefix(param, "0X") {
+			return strings.ToLower(param)
+		}
+	case []interface{}:
+		for i := range param {
+			param[i] = canonicalParam(param[i])
+		}
+	case map[string]interface{}:
+		for k := range param {
+			param[k] = canonicalParam(param[k])
+		}
+	}
+	return param
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Tests that only the results of blocks with enough confirmations are cached,
+// and that the cache drops the entries of reorged blocks and stays bounded.
+func TestResponseCache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		genesis = &genesisT.Genesis{Config: params.TestChainConfig}
+		backend = newTestBackend(t, 10, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {})
+		cache   = NewResponseCache(backend, 3, 1024*1024)
+		server  = rpc.NewServer()
+	)
+	server.SetResponseCache(cache)
+	if err := server.RegisterName("eth", NewBlockChainAPI(backend)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	call := func(number string) json.RawMessage {
+		t.Helper()
+		var result json.RawMessage
+		if err := client.Call(&result, "eth_getBlockByNumber", number, false); err != nil {
+			t.Fatalf("failed to get block %s: %v", number, err)
+		}
+		return result
+	}
+	// Blocks within the confirmation depth and tags are not cached
+	call("0x9")
+	call("0xa")
+	call("latest")
+	if n := cache.entries.Len(); n != 0 {
+		t.Fatalf("expected no cached entry, have %d", n)
+	}
+	// Confirmed blocks are cached, regardless of the hex case
+	first := call("0x2")
+	if n := cache.entries.Len(); n != 1 {
+		t.Fatalf("expected 1 cached entry, have %d", n)
+	}
+	key, _ := cacheKey("eth_getBlockByNumber", json.RawMessage(`["0X2",false]`))
+	if _, ok := cache.entries.Peek(key); !ok {
+		t.Fatal("expected cache key to ignore hex case")
+	}
+	if second := call("0x2"); string(second) != string(first) {
+		t.Fatalf("cached result mismatch: have %s, want %s", second, first)
+	}
+	// Reorged blocks are dropped
+	cache.drop(backend.chain.GetHeaderByNumber(2).Hash())
+	if n := cache.entries.Len(); n != 0 || cache.size != 0 {
+		t.Fatalf("expected empty cache after reorg, have %d entries of %d bytes", n, cache.size)
+	}
+	// The least recently used entries are evicted beyond the size bound
+	call("0x2")
+	cache.maxSize = cache.size
+	call("0x3")
+	if n := cache.entries.Len(); n != 1 || cache.size > cache.maxSize {
+		t.Fatalf("expected 1 entry within the size bound, have %d entries of %d bytes", n, cache.size)
+	}
+	if len(cache.blocks) != 1 {
+		t.Fatalf("block index mismatch: have %d blocks", len(cache.blocks))
+	}
+}
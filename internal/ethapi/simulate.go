@@ -0,0 +1,505 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks a single simulation may
+	// span, including the empty blocks filling gaps between requested numbers.
+	maxSimulateBlocks = 256
+
+	// simulateBlockTime is the timestamp increment of simulated blocks which
+	// don't override their time.
+	simulateBlockTime = 12
+
+	// errCodeVMError is the error code of calls failing with an EVM error other
+	// than a revert.
+	errCodeVMError = -32015
+)
+
+var (
+	// transferAddress is the pseudo-address emitting the logs of traced ether
+	// transfers.
+	transferAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+	// transferTopic is the ERC-20 Transfer event signature, reused for traced
+	// ether transfers.
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// SimBlock is a block of calls to simulate, along with the block and state
+// overrides to apply before executing them.
+type SimBlock struct {
+	BlockOverrides *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides *StateOverride    `json:"stateOverrides"`
+	Calls          []TransactionArgs `json:"calls"`
+}
+
+// SimOpts are the inputs of eth_simulateV1.
+type SimOpts struct {
+	BlockStateCalls        []SimBlock `json:"blockStateCalls"`
+	TraceTransfers         bool       `json:"traceTransfers"`         // Emit a log for every ether transfer
+	Validation             bool       `json:"validation"`             // Enforce nonces, balances and the base fee
+	ReturnFullTransactions bool       `json:"returnFullTransactions"` // Return full transactions instead of hashes
+}
+
+// SimCallError is the error of a failed simulated call.
+type SimCallError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    string `json:"data,omitempty"`
+}
+
+// SimCallResult is the outcome of a single simulated call.
+type SimCallResult struct {
+	ReturnData hexutil.Bytes          `json:"returnData"`
+	Logs       []*types.Log           `json:"logs"`
+	GasUsed    hexutil.Uint64         `json:"gasUsed"`
+	Status     hexutil.Uint64         `json:"status"`
+	Error      *SimCallError          `json:"error,omitempty"`
+	Receipt    map[string]interface{} `json:"receipt"`
+}
+
+// SimBlockResult is a simulated block along with the results of its calls.
+type SimBlockResult struct {
+	Block *RPCMarshalBlockT
+	Calls []*SimCallResult
+}
+
+// MarshalJSON flattens the calls into the marshalled block.
+func (r *SimBlockResult) MarshalJSON() ([]byte, error) {
+	blob, err := json.Marshal(r.Block)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		return nil, err
+	}
+	if fields["calls"], err = json.Marshal(r.Calls); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// SimulateV1 executes a series of blocks of calls on top of the given block.
+// Every block may override the block header fields and the state it executes
+// on, and sees the state changes of the blocks before it. The simulated blocks
+// are returned with the results and receipts of their calls.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (s *BlockChainAPI) SimulateV1(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimBlockResult, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errors.New("empty input")
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, fmt.Errorf("too many blocks: %d > %d", len(opts.BlockStateCalls), maxSimulateBlocks)
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, base, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// The timeout applies to the simulation as a whole
+	var cancel context.CancelFunc
+	if timeout := s.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	sim := &simulator{
+		b:              s.b,
+		state:          state,
+		base:           base,
+		config:         s.b.ChainConfig(),
+		gasRemaining:   s.b.RPCGasCap(),
+		hashes:         make(map[uint64]common.Hash),
+		traceTransfers: opts.TraceTransfers,
+		validate:       opts.Validation,
+		fullTx:         opts.ReturnFullTransactions,
+	}
+	if sim.gasRemaining == 0 {
+		sim.gasRemaining = math.MaxUint64
+	}
+	return sim.execute(ctx, opts.BlockStateCalls)
+}
+
+// simulator executes the blocks of a single eth_simulateV1 request.
+type simulator struct {
+	b              Backend
+	state          *state.StateDB
+	base           *types.Header
+	config         ctypes.ChainConfigurator
+	gasRemaining   uint64                 // Gas left under the RPC gas cap, shared by all calls
+	hashes         map[uint64]common.Hash // Hashes of the simulated blocks, for BLOCKHASH
+	traceTransfers bool
+	validate       bool
+	fullTx         bool
+}
+
+// execute runs the given blocks one after the other.
+func (sim *simulator) execute(ctx context.Context, blocks []SimBlock) ([]*SimBlockResult, error) {
+	blocks, err := sim.sanitizeBlocks(blocks)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results = make([]*SimBlockResult, 0, len(blocks))
+		parent  = sim.base
+		getHash = core.GetHashFn(sim.base, NewChainContext(ctx, sim.b))
+	)
+	for _, block := range blocks {
+		result, header, err := sim.processBlock(ctx, &block, parent, getHash)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+		parent = header
+	}
+	return results, nil
+}
+
+// sanitizeBlocks assigns a number and timestamp to every block, checks that
+// both are strictly increasing and fills the gaps between non-consecutive
+// block numbers with empty blocks.
+func (sim *simulator) sanitizeBlocks(blocks []SimBlock) ([]SimBlock, error) {
+	var (
+		res        = make([]SimBlock, 0, len(blocks))
+		prevNumber = sim.base.Number
+		prevTime   = sim.base.Time
+	)
+	for _, block := range blocks {
+		// Don't modify the overrides of the caller
+		overrides := new(BlockOverrides)
+		if block.BlockOverrides != nil {
+			*overrides = *block.BlockOverrides
+		}
+		block.BlockOverrides = overrides
+
+		if overrides.Number == nil {
+			overrides.Number = (*hexutil.Big)(new(big.Int).Add(prevNumber, common.Big1))
+		}
+		number := overrides.Number.ToInt()
+		if number.Cmp(prevNumber) <= 0 {
+			return nil, fmt.Errorf("block numbers must be in order: %d <= %d", number, prevNumber)
+		}
+		diff := new(big.Int).Sub(number, prevNumber)
+		if !diff.IsUint64() || diff.Uint64() > uint64(maxSimulateBlocks-len(res)) {
+			return nil, fmt.Errorf("too many blocks: more than %d", maxSimulateBlocks)
+		}
+		for i := uint64(1); i < diff.Uint64(); i++ {
+			prevTime += simulateBlockTime
+			time := hexutil.Uint64(prevTime)
+			res = append(res, SimBlock{BlockOverrides: &BlockOverrides{
+				Number: (*hexutil.Big)(new(big.Int).Add(prevNumber, new(big.Int).SetUint64(i))),
+				Time:   &time,
+			}})
+		}
+		if overrides.Time == nil {
+			time := hexutil.Uint64(prevTime + simulateBlockTime)
+			overrides.Time = &time
+		}
+		if uint64(*overrides.Time) <= prevTime {
+			return nil, fmt.Errorf("block timestamps must be in order: %d <= %d", uint64(*overrides.Time), prevTime)
+		}
+		prevNumber, prevTime = number, uint64(*overrides.Time)
+		res = append(res, block)
+	}
+	return res, nil
+}
+
+// makeHeader assembles the header of a simulated block, inheriting the fields
+// not overridden from its parent.
+func (sim *simulator) makeHeader(overrides *BlockOverrides, parent *types.Header) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   parent.Coinbase,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		GasLimit:   parent.GasLimit,
+		Number:     overrides.Number.ToInt(),
+		Time:       uint64(*overrides.Time),
+	}
+	if sim.config.IsEnabled(sim.config.GetEIP1559Transition, header.Number) {
+		// Without validation, calls don't need to pay any fees
+		if sim.validate {
+			header.BaseFee = eip1559.CalcBaseFee(sim.config, parent)
+		} else {
+			header.BaseFee = new(big.Int)
+		}
+	}
+	if overrides.Difficulty != nil {
+		header.Difficulty = overrides.Difficulty.ToInt()
+	}
+	if overrides.GasLimit != nil {
+		header.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.Coinbase != nil {
+		header.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Random != nil {
+		header.MixDigest = *overrides.Random
+	}
+	if overrides.BaseFee != nil {
+		header.BaseFee = overrides.BaseFee.ToInt()
+	}
+	return header
+}
+
+// processBlock executes the calls of a single block on top of the current
+// simulation state and assembles the resulting block.
+func (sim *simulator) processBlock(ctx context.Context, block *SimBlock, parent *types.Header, getHash vm.GetHashFunc) (*SimBlockResult, *types.Header, error) {
+	if err := block.StateOverrides.Apply(sim.state); err != nil {
+		return nil, nil, err
+	}
+	var (
+		header  = sim.makeHeader(block.BlockOverrides, parent)
+		number  = header.Number.Uint64()
+		base    = sim.base.Number.Uint64()
+		eip161d = sim.config.IsEnabled(sim.config.GetEIP161dTransition, header.Number)
+		eip658  = sim.config.IsEnabled(sim.config.GetEIP658Transition, header.Number)
+
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, sim.b), &header.Coinbase)
+		vmConfig = vm.Config{NoBaseFee: !sim.validate}
+		gp       = new(core.GasPool).AddGas(header.GasLimit)
+
+		txs      = make([]*types.Transaction, 0, len(block.Calls))
+		senders  = make([]common.Address, 0, len(block.Calls))
+		receipts = make([]*types.Receipt, 0, len(block.Calls))
+		calls    = make([]*SimCallResult, 0, len(block.Calls))
+		gasUsed  uint64
+	)
+	block.BlockOverrides.Apply(&blockCtx)
+	blockCtx.GetHash = func(n uint64) common.Hash {
+		switch {
+		case n == base:
+			return sim.base.Hash()
+		case n > base:
+			return sim.hashes[n]
+		default:
+			return getHash(n)
+		}
+	}
+	if sim.traceTransfers {
+		vmConfig.Tracer = new(transferTracer)
+	}
+	for i := range block.Calls {
+		args := block.Calls[i]
+		if err := sim.sanitizeCall(&args, header, gp.Gas()); err != nil {
+			return nil, nil, fmt.Errorf("block %d, call %d: %w", number, i, err)
+		}
+		msg, err := args.ToMessage(0, header.BaseFee)
+		if err != nil {
+			return nil, nil, fmt.Errorf("block %d, call %d: %w", number, i, err)
+		}
+		if sim.validate {
+			msg.Nonce = uint64(*args.Nonce)
+			msg.SkipAccountChecks = false
+		}
+		tx := args.ToTransaction()
+		sim.state.SetTxContext(tx.Hash(), i)
+
+		evm, vmError := sim.b.GetEVM(ctx, msg, sim.state, header, &vmConfig, &blockCtx)
+		go func() {
+			<-ctx.Done()
+			evm.Cancel()
+		}()
+		result, err := core.ApplyMessage(evm, msg, gp)
+		if err := vmError(); err != nil {
+			return nil, nil, err
+		}
+		if evm.Cancelled() {
+			return nil, nil, fmt.Errorf("execution aborted (timeout = %v)", sim.b.RPCEVMTimeout())
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("block %d, call %d: %w", number, i, err)
+		}
+		var root []byte
+		if eip658 {
+			sim.state.Finalise(eip161d)
+		} else {
+			root = sim.state.IntermediateRoot(eip161d).Bytes()
+		}
+		gasUsed += result.UsedGas
+		sim.gasRemaining -= result.UsedGas
+
+		receipt := &types.Receipt{
+			Type:              tx.Type(),
+			PostState:         root,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: gasUsed,
+			TxHash:            tx.Hash(),
+			GasUsed:           result.UsedGas,
+			EffectiveGasPrice: msg.GasPrice,
+			Logs:              sim.state.GetLogs(tx.Hash(), number, common.Hash{}),
+			BlockNumber:       header.Number,
+			TransactionIndex:  uint(i),
+		}
+		if result.Failed() {
+			receipt.Status = types.ReceiptStatusFailed
+		}
+		if msg.To == nil {
+			receipt.ContractAddress = crypto.CreateAddress(msg.From, tx.Nonce())
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+		call := &SimCallResult{
+			ReturnData: result.Return(),
+			Logs:       receipt.Logs,
+			GasUsed:    hexutil.Uint64(result.UsedGas),
+			Status:     hexutil.Uint64(receipt.Status),
+		}
+		if call.Logs == nil {
+			call.Logs = []*types.Log{}
+		}
+		if result.Failed() {
+			if len(result.Revert()) > 0 {
+				revert := newRevertError(result)
+				call.Error = &SimCallError{Message: revert.Error(), Code: revert.ErrorCode(), Data: revert.reason}
+			} else {
+				call.Error = &SimCallError{Message: result.Err.Error(), Code: errCodeVMError}
+			}
+		}
+		txs = append(txs, tx)
+		senders = append(senders, msg.From)
+		receipts = append(receipts, receipt)
+		calls = append(calls, call)
+	}
+	header.GasUsed = gasUsed
+	header.Root = sim.state.IntermediateRoot(eip161d)
+
+	simulated := types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
+	hash := simulated.Hash()
+	sim.hashes[number] = hash
+
+	// The logs and receipts were created before the block hash was known
+	signer := types.MakeSigner(sim.config, header.Number, header.Time)
+	for i, receipt := range receipts {
+		receipt.BlockHash = hash
+		for _, log := range receipt.Logs {
+			log.BlockHash = hash
+		}
+		calls[i].Receipt = marshalReceipt(receipt, hash, number, signer, txs[i], i)
+		calls[i].Receipt["from"] = senders[i]
+	}
+	// Simulated transactions are unsigned, patch in their senders
+	fields := RPCMarshalBlock(simulated, true, sim.fullTx, sim.config)
+	if sim.fullTx {
+		for i, tx := range fields.Transactions {
+			if tx, ok := tx.(*RPCTransaction); ok {
+				tx.From = senders[i]
+			}
+		}
+	}
+	return &SimBlockResult{Block: fields, Calls: calls}, simulated.Header(), nil
+}
+
+// sanitizeCall fills in the nonce and gas of a simulated call and checks them
+// against the gas left in the block and under the RPC gas cap.
+func (sim *simulator) sanitizeCall(args *TransactionArgs, header *types.Header, blockGas uint64) error {
+	if args.Nonce == nil {
+		nonce := hexutil.Uint64(sim.state.GetNonce(args.from()))
+		args.Nonce = &nonce
+	}
+	if args.Gas == nil {
+		gas := hexutil.Uint64(blockGas)
+		if sim.gasRemaining < blockGas {
+			gas = hexutil.Uint64(sim.gasRemaining)
+		}
+		args.Gas = &gas
+	}
+	if uint64(*args.Gas) > blockGas {
+		return fmt.Errorf("block gas limit reached: %d > %d", uint64(*args.Gas), blockGas)
+	}
+	if uint64(*args.Gas) > sim.gasRemaining {
+		return fmt.Errorf("gas cap reached: %d > %d", uint64(*args.Gas), sim.gasRemaining)
+	}
+	if args.ChainID == nil && sim.config.GetChainID() != nil {
+		args.ChainID = (*hexutil.Big)(sim.config.GetChainID())
+	}
+	return nil
+}
+
+// transferTracer emits a log for every ether transfer, following the ERC-20
+// Transfer event layout. The logs are added to the state, so they are reverted
+// along with the call frame that made the transfer.
+type transferTracer struct {
+	env *vm.EVM
+}
+
+func (t *transferTracer) transfer(from, to common.Address, value *big.Int) {
+	if value == nil || value.Sign() == 0 {
+		return
+	}
+	t.env.StateDB.AddLog(&types.Log{
+		Address: transferAddress,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.BigToHash(value).Bytes(),
+	})
+}
+
+func (t *transferTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *transferTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *transferTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.transfer(from, to, value)
+}
+
+func (t *transferTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (t *transferTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// Delegated frames only inherit the value, callcode sends it to the caller itself
+	if typ == vm.DELEGATECALL || typ == vm.CALLCODE {
+		return
+	}
+	t.transfer(from, to, value)
+}
+
+func (t *transferTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *transferTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *transferTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
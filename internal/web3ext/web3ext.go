@@ -32,6 +32,7 @@ var Modules = map[string]string{
 	"les":      LESJs,
 	"vflux":    VfluxJs,
 	"dev":      DevJs,
+	"vecno":    VecnoJs,
 }
 
 const CliqueJs = `
@@ -159,6 +160,11 @@ web3._extend({
 			call: 'admin_maxPeers',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'setBootnodes',
+			call: 'admin_setBootnodes',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'ecbp1100',
 			call: 'admin_ecbp1100',
@@ -215,6 +221,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'bootnodeStatus',
+			getter: 'admin_bootnodeStatus'
+		}),
 	]
 });
 `
@@ -538,6 +548,16 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'eth_sendBundle',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'callBundle',
+			call: 'eth_callBundle',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'signTransaction',
 			call: 'eth_signTransaction',
@@ -617,6 +637,11 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'gasPriceLevels',
+			call: 'eth_gasPriceLevels',
+			params: 0,
+		}),
 		new web3._extend.Method({
 			name: 'getLogs',
 			call: 'eth_getLogs',
@@ -628,6 +653,12 @@ web3._extend({
 			params: 4,
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter, null, null],
 		}),
+		new web3._extend.Method({
+			name: 'simulateV1',
+			call: 'eth_simulateV1',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter],
+		}),
 		new web3._extend.Method({
 			name: 'getBlockReceipts',
 			call: 'eth_getBlockReceipts',
@@ -696,6 +727,30 @@ web3._extend({
 			call: 'miner_setRecommitInterval',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'setPayoutSplit',
+			call: 'miner_setPayoutSplit',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'getPayoutSplit',
+			call: 'miner_getPayoutSplit',
+		}),
+		new web3._extend.Method({
+			name: 'setSealPolicy',
+			call: 'miner_setSealPolicy',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'getSealPolicy',
+			call: 'miner_getSealPolicy',
+		}),
+		new web3._extend.Method({
+			name: 'getSealingStats',
+			call: 'miner_getSealingStats',
+			params: 1,
+			inputFormatter: [null],
+		}),
 		new web3._extend.Method({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
@@ -864,6 +919,11 @@ web3._extend({
 			call: 'txpool_contentFrom',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'history',
+			call: 'txpool_history',
+			params: 1,
+		}),
 	]
 });
 `
@@ -971,3 +1031,18 @@ web3._extend({
 	],
 });
 `
+
+const VecnoJs = `
+web3._extend({
+	property: 'vecno',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getBlockRewards',
+			call: 'vecno_getBlockRewards',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+	],
+});
+`
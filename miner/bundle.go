@@ -0,0 +1,291 @@
+// Copyright 2014 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+const (
+	// maxBundles is the maximum number of bundles tracked by the bundle policy.
+	maxBundles = 1024
+
+	// maxSenderBundles is the maximum number of bundles tracked for the sender
+	// of the first transaction of a bundle.
+	maxSenderBundles = 16
+
+	// maxBundleDistance is the maximum number of blocks a bundle may target
+	// ahead of the current head.
+	maxBundleDistance = 25
+)
+
+var (
+	errBundlesDisabled      = errors.New("transaction bundles require the bundle ordering policy")
+	errEmptyBundle          = errors.New("empty bundle")
+	errBlobBundle           = errors.New("blob transactions are not supported in bundles")
+	errTooManyBundles       = errors.New("too many pending bundles")
+	errTooManySenderBundles = errors.New("too many pending bundles from sender")
+	errBundleTooFarAhead    = errors.New("bundle targets a block too far ahead")
+	errBundleTooOld         = errors.New("bundle targets an already mined block")
+)
+
+// Bundle is an ordered group of transactions which is included into a block
+// atomically: either all of its transactions land, or none of them do.
+type Bundle struct {
+	Txs         types.Transactions // Transactions of the bundle, in inclusion order
+	BlockNumber uint64             // Number of the block the bundle targets
+}
+
+// Hash returns the identifier of the bundle, the hash of its transaction hashes.
+func (b *Bundle) Hash() common.Hash {
+	hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// BundleTxResult is the outcome of executing a single transaction of a bundle.
+type BundleTxResult struct {
+	Hash         common.Hash
+	From         common.Address
+	To           *common.Address
+	GasUsed      uint64
+	GasPrice     *big.Int // Effective miner tip per gas
+	GasFees      *big.Int // Fees paid to the coinbase
+	CoinbaseDiff *big.Int // Balance change of the coinbase, fees and direct payments
+	Err          error    // Error the execution failed with, nil if it succeeded
+	Revert       []byte   // Revert data of the execution, if it reverted
+}
+
+// BundleResult is the outcome of executing all transactions of a bundle.
+type BundleResult struct {
+	Txs          []*BundleTxResult
+	GasUsed      uint64
+	GasFees      *big.Int // Fees paid to the coinbase
+	CoinbaseDiff *big.Int // Balance change of the coinbase, fees and direct payments
+}
+
+// GasPrice returns the coinbase payment per gas of the bundle, the measure by
+// which bundles compete for the top of the block.
+func (r *BundleResult) GasPrice() *big.Int {
+	if r.GasUsed == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Div(r.CoinbaseDiff, new(big.Int).SetUint64(r.GasUsed))
+}
+
+// SimulateBundle executes the transactions of a bundle on top of the given state
+// in the context of the given block header, paying header.Coinbase. An error is
+// returned if any of the transactions is invalid, failed executions are not
+// errors. The execution is aborted once the context is done, and the gas of the
+// whole bundle is capped at gasCap if not zero. The state is modified by the
+// execution.
+func SimulateBundle(ctx context.Context, config ctypes.ChainConfigurator, chain core.ChainContext, header *types.Header, statedb *state.StateDB, txs types.Transactions, vmConfig vm.Config, gasCap uint64) (*BundleResult, error) {
+	var (
+		signer   = types.MakeSigner(config, header.Number, header.Time)
+		coinbase = header.Coinbase
+		gasLimit = header.GasLimit
+		eip161d  = config.IsEnabled(config.GetEIP161dTransition, header.Number)
+		result   = &BundleResult{
+			Txs:          make([]*BundleTxResult, 0, len(txs)),
+			GasFees:      new(big.Int),
+			CoinbaseDiff: new(big.Int),
+		}
+	)
+	if gasCap != 0 && gasCap < gasLimit {
+		gasLimit = gasCap
+	}
+	gasPool := new(core.GasPool).AddGas(gasLimit)
+
+	// Cancel the execution once the context is done
+	evm := vm.NewEVM(core.NewEVMBlockContext(header, chain, &coinbase), vm.TxContext{}, statedb, config, vmConfig)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	for i, tx := range txs {
+		if tx.Type() == types.BlobTxType {
+			return nil, fmt.Errorf("tx %d [%v]: %w", i, tx.Hash(), errBlobBundle)
+		}
+		msg, err := core.TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d [%v]: %w", i, tx.Hash(), err)
+		}
+		tip, err := tx.EffectiveGasTip(header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d [%v]: %w", i, tx.Hash(), err)
+		}
+		balance := new(big.Int).Set(statedb.GetBalance(coinbase))
+
+		statedb.SetTxContext(tx.Hash(), i)
+		evm.Reset(core.NewEVMTxContext(msg), statedb)
+		res, err := core.ApplyMessage(evm, msg, gasPool)
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("bundle execution aborted: %w", err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tx %d [%v]: %w", i, tx.Hash(), err)
+		}
+		statedb.Finalise(eip161d)
+
+		txResult := &BundleTxResult{
+			Hash:         tx.Hash(),
+			From:         msg.From,
+			To:           tx.To(),
+			GasUsed:      res.UsedGas,
+			GasPrice:     tip,
+			GasFees:      new(big.Int).Mul(tip, new(big.Int).SetUint64(res.UsedGas)),
+			CoinbaseDiff: new(big.Int).Sub(statedb.GetBalance(coinbase), balance),
+			Err:          res.Err,
+			Revert:       res.Revert(),
+		}
+		result.Txs = append(result.Txs, txResult)
+		result.GasUsed += res.UsedGas
+		result.GasFees.Add(result.GasFees, txResult.GasFees)
+		result.CoinbaseDiff.Add(result.CoinbaseDiff, txResult.CoinbaseDiff)
+	}
+	return result, nil
+}
+
+// BundlePolicy is an ordering policy which additionally commits bundles at the
+// top of blocks, before any pending transaction of the pool.
+type BundlePolicy interface {
+	OrderingPolicy
+
+	// Bundles returns the bundles to commit into the block with the given
+	// header, in inclusion order.
+	Bundles(header *types.Header) []*Bundle
+}
+
+// bundlePolicy is a bundle policy tracking the bundles submitted to the miner,
+// ordering the pool transactions with a base policy.
+type bundlePolicy struct {
+	OrderingPolicy
+
+	lock    sync.Mutex
+	bundles []*pendingBundle
+	senders map[common.Address]int // Number of tracked bundles per sender
+}
+
+// pendingBundle is a bundle tracked by the bundle policy, along with the sender
+// it's accounted to.
+type pendingBundle struct {
+	*Bundle
+	sender common.Address
+}
+
+func newBundlePolicy(base OrderingPolicy) *bundlePolicy {
+	return &bundlePolicy{
+		OrderingPolicy: base,
+		senders:        make(map[common.Address]int),
+	}
+}
+
+// addBundle tracks a new bundle of the given sender until the block it targets
+// is built. Bundles must target one of the next maxBundleDistance blocks after
+// head.
+func (p *bundlePolicy) addBundle(bundle *Bundle, sender common.Address, head uint64) error {
+	if len(bundle.Txs) == 0 {
+		return errEmptyBundle
+	}
+	for _, tx := range bundle.Txs {
+		if tx.Type() == types.BlobTxType {
+			return errBlobBundle
+		}
+	}
+	if bundle.BlockNumber <= head {
+		return errBundleTooOld
+	}
+	if bundle.BlockNumber > head+maxBundleDistance {
+		return errBundleTooFarAhead
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.evict(head + 1)
+	if len(p.bundles) >= maxBundles {
+		return errTooManyBundles
+	}
+	if p.senders[sender] >= maxSenderBundles {
+		return errTooManySenderBundles
+	}
+	p.bundles = append(p.bundles, &pendingBundle{Bundle: bundle, sender: sender})
+	p.senders[sender]++
+	return nil
+}
+
+// prune drops the bundles targeting blocks before the given number.
+func (p *bundlePolicy) prune(number uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.evict(number)
+}
+
+// evict drops the bundles targeting blocks before the given number. The lock
+// must be held.
+func (p *bundlePolicy) evict(number uint64) {
+	live := p.bundles[:0]
+	for _, bundle := range p.bundles {
+		if bundle.BlockNumber < number {
+			if p.senders[bundle.sender]--; p.senders[bundle.sender] == 0 {
+				delete(p.senders, bundle.sender)
+			}
+			continue
+		}
+		live = append(live, bundle)
+	}
+	for i := len(live); i < len(p.bundles); i++ {
+		p.bundles[i] = nil
+	}
+	p.bundles = live
+}
+
+// Bundles implements BundlePolicy, returning the bundles targeting the block
+// in submission order. Bundles targeting earlier blocks are dropped.
+//
+// The worker further orders the returned bundles by profitability.
+func (p *bundlePolicy) Bundles(header *types.Header) []*Bundle {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	number := header.Number.Uint64()
+	p.evict(number)
+
+	var bundles []*Bundle
+	for _, bundle := range p.bundles {
+		if bundle.BlockNumber == number {
+			bundles = append(bundles, bundle.Bundle)
+		}
+	}
+	return bundles
+}
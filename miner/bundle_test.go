@@ -0,0 +1,181 @@
+// Copyright 2014 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+func TestSimulateBundle(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	w, b := newOrderingTestWorker(t, testConfig, []*ecdsa.PrivateKey{key})
+	defer w.close()
+
+	var (
+		genesis  = b.chain.Genesis().Header()
+		coinbase = common.HexToAddress("0xc0ffee")
+		header   = &types.Header{
+			ParentHash: genesis.Hash(),
+			Number:     big.NewInt(1),
+			GasLimit:   genesis.GasLimit,
+			Difficulty: genesis.Difficulty,
+			Time:       genesis.Time + 1,
+			Coinbase:   coinbase,
+			BaseFee:    eip1559.CalcBaseFee(ethashChainConfig, genesis),
+		}
+		// A regular transfer and a direct payment to the coinbase
+		txs = types.Transactions{
+			newOrderingTestTx(key, 0, testUserAddress, 2),
+			types.MustSignNewTx(key, types.LatestSigner(ethashChainConfig), &types.LegacyTx{
+				Nonce:    1,
+				To:       &coinbase,
+				Value:    big.NewInt(1000),
+				Gas:      vars.TxGas,
+				GasPrice: big.NewInt(3 * vars.InitialBaseFee),
+			}),
+		}
+	)
+	statedb, _ := b.chain.State()
+	result, err := SimulateBundle(context.Background(), ethashChainConfig, b.chain, header, statedb, txs, vm.Config{}, 0)
+	if err != nil {
+		t.Fatalf("failed to simulate bundle: %v", err)
+	}
+	if result.GasUsed != 2*vars.TxGas {
+		t.Errorf("gas used mismatch: have %d, want %d", result.GasUsed, 2*vars.TxGas)
+	}
+	for i, tx := range txs {
+		var (
+			res  = result.Txs[i]
+			tip  = new(big.Int).Sub(tx.GasPrice(), header.BaseFee)
+			fees = new(big.Int).Mul(tip, new(big.Int).SetUint64(vars.TxGas))
+		)
+		if res.Hash != tx.Hash() || res.GasUsed != vars.TxGas || res.Err != nil {
+			t.Errorf("tx %d: result mismatch: %+v", i, res)
+		}
+		if res.GasPrice.Cmp(tip) != 0 || res.GasFees.Cmp(fees) != 0 {
+			t.Errorf("tx %d: fees mismatch: have %v at %v, want %v at %v", i, res.GasFees, res.GasPrice, fees, tip)
+		}
+		want := new(big.Int).Add(fees, tx.Value())
+		if tx.To() != nil && *tx.To() != coinbase {
+			want = fees
+		}
+		if res.CoinbaseDiff.Cmp(want) != 0 {
+			t.Errorf("tx %d: coinbase diff mismatch: have %v, want %v", i, res.CoinbaseDiff, want)
+		}
+	}
+	want := new(big.Int).Add(new(big.Int).Add(result.Txs[0].GasFees, result.Txs[1].GasFees), big.NewInt(1000))
+	if result.CoinbaseDiff.Cmp(want) != 0 {
+		t.Errorf("coinbase diff mismatch: have %v, want %v", result.CoinbaseDiff, want)
+	}
+	if price := result.GasPrice(); price.Cmp(new(big.Int).Div(want, big.NewInt(int64(2*vars.TxGas)))) != 0 {
+		t.Errorf("bundle gas price mismatch: have %v", price)
+	}
+	// Bundles with invalid transactions must be rejected
+	statedb, _ = b.chain.State()
+	if _, err := SimulateBundle(context.Background(), ethashChainConfig, b.chain, header, statedb, txs[1:], vm.Config{}, 0); !errors.Is(err, core.ErrNonceTooHigh) {
+		t.Errorf("invalid bundle error mismatch: have %v, want %v", err, core.ErrNonceTooHigh)
+	}
+	// Bundles exceeding the gas cap must be rejected
+	statedb, _ = b.chain.State()
+	if _, err := SimulateBundle(context.Background(), ethashChainConfig, b.chain, header, statedb, txs, vm.Config{}, vars.TxGas); !errors.Is(err, core.ErrGasLimitReached) {
+		t.Errorf("capped bundle error mismatch: have %v, want %v", err, core.ErrGasLimitReached)
+	}
+	// Cancelled executions must be aborted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	statedb, _ = b.chain.State()
+	if _, err := SimulateBundle(ctx, ethashChainConfig, b.chain, header, statedb, txs, vm.Config{}, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled bundle error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	// Reverted executions report their error and revert data
+	revert := types.MustSignNewTx(key, types.LatestSigner(ethashChainConfig), &types.LegacyTx{
+		Nonce:    2,
+		Gas:      100000,
+		GasPrice: big.NewInt(3 * vars.InitialBaseFee),
+		Data:     common.FromHex("602a60005260206000fd"), // Reverts with 0x2a in a word
+	})
+	statedb, _ = b.chain.State()
+	result, err = SimulateBundle(context.Background(), ethashChainConfig, b.chain, header, statedb, append(txs, revert), vm.Config{}, 0)
+	if err != nil {
+		t.Fatalf("failed to simulate reverting bundle: %v", err)
+	}
+	if res := result.Txs[2]; res.Err != vm.ErrExecutionReverted || !bytes.Equal(res.Revert, common.LeftPadBytes([]byte{0x2a}, 32)) {
+		t.Errorf("revert mismatch: have %v with %x", res.Err, res.Revert)
+	}
+}
+
+func TestBundlePolicyLimits(t *testing.T) {
+	var (
+		policy = newBundlePolicy(pricePolicy{})
+		key, _ = crypto.GenerateKey()
+		tx     = newOrderingTestTx(key, 0, testUserAddress, 2)
+		alice  = common.Address{0x01}
+		bob    = common.Address{0x02}
+	)
+	newBundle := func(number uint64) *Bundle {
+		return &Bundle{Txs: types.Transactions{tx}, BlockNumber: number}
+	}
+	// Bundles must target one of the next maxBundleDistance blocks
+	if err := policy.addBundle(newBundle(10), alice, 10); !errors.Is(err, errBundleTooOld) {
+		t.Errorf("past bundle error mismatch: have %v, want %v", err, errBundleTooOld)
+	}
+	if err := policy.addBundle(newBundle(10+maxBundleDistance+1), alice, 10); !errors.Is(err, errBundleTooFarAhead) {
+		t.Errorf("future bundle error mismatch: have %v, want %v", err, errBundleTooFarAhead)
+	}
+	// Senders may only track a limited number of bundles
+	for i := 0; i < maxSenderBundles; i++ {
+		if err := policy.addBundle(newBundle(11+uint64(i%2)), alice, 10); err != nil {
+			t.Fatalf("bundle %d: failed to add: %v", i, err)
+		}
+	}
+	if err := policy.addBundle(newBundle(11), alice, 10); !errors.Is(err, errTooManySenderBundles) {
+		t.Errorf("sender cap error mismatch: have %v, want %v", err, errTooManySenderBundles)
+	}
+	if err := policy.addBundle(newBundle(11), bob, 10); err != nil {
+		t.Errorf("failed to add bundle of other sender: %v", err)
+	}
+	// A new head evicts the expired bundles and frees their sender slots
+	policy.prune(12)
+	if have, want := len(policy.bundles), maxSenderBundles/2; have != want {
+		t.Errorf("tracked bundles mismatch after prune: have %d, want %d", have, want)
+	}
+	if have, want := policy.senders[alice], maxSenderBundles/2; have != want {
+		t.Errorf("sender bundles mismatch after prune: have %d, want %d", have, want)
+	}
+	if _, ok := policy.senders[bob]; ok {
+		t.Error("sender without bundles still tracked")
+	}
+	if err := policy.addBundle(newBundle(12), alice, 11); err != nil {
+		t.Errorf("failed to add bundle after prune: %v", err)
+	}
+	if have, want := len(policy.Bundles(&types.Header{Number: big.NewInt(12)})), maxSenderBundles/2+1; have != want {
+		t.Errorf("block bundles mismatch: have %d, want %d", have, want)
+	}
+}
@@ -58,6 +58,14 @@ type Config struct {
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
 
 	NewPayloadTimeout time.Duration // The maximum time allowance for creating a new payload
+
+	Ordering          string           `toml:",omitempty"` // Transaction ordering policy: price (default), fifo, priority or bundle
+	PriorityAddresses []common.Address `toml:",omitempty"` // Senders and contracts whose transactions are included first by the priority policy
+
+	MinTxs      uint64        `toml:",omitempty"` // Minimum number of transactions before non-empty work is sealed
+	MinFees     *big.Int      `toml:",omitempty"` // Minimum fees before non-empty work is sealed
+	FillTimeout time.Duration `toml:",omitempty"` // Maximum time spent filling transactions into a sealing block
+	PauseEmpty  bool          `toml:",omitempty"` // Skip sealing empty blocks while transactions are pending
 }
 
 // DefaultConfig contains default settings for miner.
@@ -232,6 +240,45 @@ func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
 
+// AddBundle submits a bundle of transactions to be included atomically at the
+// top of the block it targets. Bundles are only accepted by the bundle ordering
+// policy.
+func (miner *Miner) AddBundle(bundle *Bundle) error {
+	return miner.worker.addBundle(bundle)
+}
+
+// SetPayoutSplit splits the income of the etherbase, its block rewards and the
+// fees of its blocks, among the given weighted recipients. The split is settled
+// with transfers from the etherbase, signed by signFn, at the top of the blocks
+// the miner builds. An empty share list disables the split.
+func (miner *Miner) SetPayoutSplit(shares []PayoutShare, minPayout *big.Int, signFn PayoutSignerFn) error {
+	return miner.worker.setPayoutSplit(shares, minPayout, signFn)
+}
+
+// PayoutSplit returns the configured payout split and the amounts owed.
+func (miner *Miner) PayoutSplit() *PayoutStatus {
+	return miner.worker.payouts.status()
+}
+
+// SealingStats returns the uncle, orphan and reorg statistics of the chain and
+// of the locally sealed blocks, for the given number of epochs up to the one of
+// the chain head, oldest first.
+func (miner *Miner) SealingStats(epochs uint64) []*SealingStats {
+	return miner.worker.stats.recent(epochs)
+}
+
+// SetSealPolicy updates the policy deciding which proof-of-work sealing work
+// is released to the consensus engine.
+func (miner *Miner) SetSealPolicy(policy SealPolicy) error {
+	return miner.worker.setSealPolicy(&policy)
+}
+
+// SealPolicy returns the policy deciding which proof-of-work sealing work is
+// released to the consensus engine.
+func (miner *Miner) SealPolicy() SealPolicy {
+	return *miner.worker.sealPolicy()
+}
+
 // SetGasCeil sets the gaslimit to strive for when mining blocks post 1559.
 // For pre-1559 blocks, it sets the ceiling.
 func (miner *Miner) SetGasCeil(ceil uint64) {
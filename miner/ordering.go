@@ -18,6 +18,7 @@ package miner
 
 import (
 	"container/heap"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -88,6 +89,7 @@ type transactionsByPriceAndNonce struct {
 	heads   txByPriceAndTime                             // Next transaction for each unique account (price heap)
 	signer  types.Signer                                 // Signer for the set of transactions
 	baseFee *big.Int                                     // Current base fee
+	fifo    bool                                         // Whether to ignore the fees, sorting by time only
 }
 
 // newTransactionsByPriceAndNonce creates a transaction set that can retrieve
@@ -96,10 +98,30 @@ type transactionsByPriceAndNonce struct {
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
 func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByPriceAndNonce {
+	return newTransactionsByNonce(signer, txs, baseFee, false)
+}
+
+// newTransactionsByTimeAndNonce creates a transaction set that can retrieve
+// transactions in first-seen order in a nonce-honouring way. Transactions not
+// paying the base fee are still skipped.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func newTransactionsByTimeAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByPriceAndNonce {
+	return newTransactionsByNonce(signer, txs, baseFee, true)
+}
+
+func newTransactionsByNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, fifo bool) *transactionsByPriceAndNonce {
+	set := &transactionsByPriceAndNonce{
+		txs:     txs,
+		signer:  signer,
+		baseFee: baseFee,
+		fifo:    fifo,
+	}
 	// Initialize a price and received time based heap with the head transactions
 	heads := make(txByPriceAndTime, 0, len(txs))
 	for from, accTxs := range txs {
-		wrapped, err := newTxWithMinerFee(accTxs[0], from, baseFee)
+		wrapped, err := set.wrap(accTxs[0], from)
 		if err != nil {
 			delete(txs, from)
 			continue
@@ -108,14 +130,22 @@ func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address]
 		txs[from] = accTxs[1:]
 	}
 	heap.Init(&heads)
+	set.heads = heads
 
-	// Assemble and return the transaction set
-	return &transactionsByPriceAndNonce{
-		txs:     txs,
-		heads:   heads,
-		signer:  signer,
-		baseFee: baseFee,
+	return set
+}
+
+// wrap creates the heap entry of a transaction. In first-seen mode the fees are
+// flattened, so the heap falls back to sorting by time.
+func (t *transactionsByPriceAndNonce) wrap(tx *txpool.LazyTransaction, from common.Address) (*txWithMinerFee, error) {
+	wrapped, err := newTxWithMinerFee(tx, from, t.baseFee)
+	if err != nil {
+		return nil, err
 	}
+	if t.fifo {
+		wrapped.fees = new(big.Int)
+	}
+	return wrapped, nil
 }
 
 // Peek returns the next transaction by price.
@@ -130,7 +160,7 @@ func (t *transactionsByPriceAndNonce) Peek() *txpool.LazyTransaction {
 func (t *transactionsByPriceAndNonce) Shift() {
 	acc := t.heads[0].from
 	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
+		if wrapped, err := t.wrap(txs[0], acc); err == nil {
 			t.heads[0], t.txs[acc] = wrapped, txs[1:]
 			heap.Fix(&t.heads, 0)
 			return
@@ -145,3 +175,127 @@ func (t *transactionsByPriceAndNonce) Shift() {
 func (t *transactionsByPriceAndNonce) Pop() {
 	heap.Pop(&t.heads)
 }
+
+// Transaction ordering policies selectable through Config.Ordering.
+const (
+	OrderingPrice    = "price"    // Highest paying transactions first (default)
+	OrderingFIFO     = "fifo"     // First seen transactions first
+	OrderingPriority = "priority" // Transactions of priority addresses first, the rest by price
+	OrderingBundle   = "bundle"   // Atomic transaction bundles first, the rest by price
+)
+
+// TransactionSet is a set of pending transactions returned in inclusion order,
+// while honouring the nonce order of each account.
+type TransactionSet interface {
+	// Peek returns the next transaction to include, nil if the set is exhausted.
+	Peek() *txpool.LazyTransaction
+
+	// Shift replaces the next transaction with the following one of the same account.
+	Shift()
+
+	// Pop removes the next transaction, along with all following ones of the
+	// same account.
+	Pop()
+}
+
+// OrderingPolicy decides the order in which the pending transactions of the
+// pool are committed into new blocks. Local transactions are always committed
+// before remote ones, each group being ordered separately by the policy.
+type OrderingPolicy interface {
+	// Order returns the nonce-sorted pending transactions of each account as a
+	// set ordered for inclusion into a block with the given base fee.
+	//
+	// Note, the input map is reowned by the returned set.
+	Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet
+}
+
+// newOrderingPolicy creates the transaction ordering policy configured.
+func newOrderingPolicy(config *Config) (OrderingPolicy, error) {
+	switch config.Ordering {
+	case "", OrderingPrice:
+		return pricePolicy{}, nil
+	case OrderingFIFO:
+		return fifoPolicy{}, nil
+	case OrderingPriority:
+		return newPriorityPolicy(config.PriorityAddresses), nil
+	case OrderingBundle:
+		return newBundlePolicy(pricePolicy{}), nil
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering policy %q", config.Ordering)
+	}
+}
+
+// pricePolicy orders transactions by their effective miner tip, breaking ties
+// by the time they were first seen.
+type pricePolicy struct{}
+
+func (pricePolicy) Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet {
+	return newTransactionsByPriceAndNonce(signer, txs, baseFee)
+}
+
+// fifoPolicy orders transactions strictly by the time they were first seen.
+type fifoPolicy struct{}
+
+func (fifoPolicy) Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet {
+	return newTransactionsByTimeAndNonce(signer, txs, baseFee)
+}
+
+// priorityPolicy commits the transactions of a priority lane before all others,
+// both lanes ordered by price. An account is in the priority lane if it's one of
+// the priority addresses, or if its next transaction calls one of them.
+type priorityPolicy struct {
+	addresses map[common.Address]struct{}
+}
+
+func newPriorityPolicy(addresses []common.Address) *priorityPolicy {
+	policy := &priorityPolicy{addresses: make(map[common.Address]struct{}, len(addresses))}
+	for _, addr := range addresses {
+		policy.addresses[addr] = struct{}{}
+	}
+	return policy
+}
+
+func (p *priorityPolicy) Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet {
+	lane := make(map[common.Address][]*txpool.LazyTransaction)
+	for from, accTxs := range txs {
+		if p.prioritized(from, accTxs[0]) {
+			lane[from] = accTxs
+			delete(txs, from)
+		}
+	}
+	return &priorityTransactions{
+		lane: newTransactionsByPriceAndNonce(signer, lane, baseFee),
+		rest: newTransactionsByPriceAndNonce(signer, txs, baseFee),
+	}
+}
+
+// prioritized reports whether the account belongs into the priority lane.
+func (p *priorityPolicy) prioritized(from common.Address, next *txpool.LazyTransaction) bool {
+	if _, ok := p.addresses[from]; ok {
+		return true
+	}
+	if tx := next.Resolve(); tx != nil && tx.To() != nil {
+		_, ok := p.addresses[*tx.To()]
+		return ok
+	}
+	return false
+}
+
+// priorityTransactions is a transaction set draining its priority lane before
+// the rest of the transactions.
+type priorityTransactions struct {
+	lane TransactionSet
+	rest TransactionSet
+}
+
+// current returns the set the next transaction is retrieved from.
+func (t *priorityTransactions) current() TransactionSet {
+	if t.lane.Peek() != nil {
+		return t.lane
+	}
+	return t.rest
+}
+
+func (t *priorityTransactions) Peek() *txpool.LazyTransaction { return t.current().Peek() }
+func (t *priorityTransactions) Shift()                        { t.current().Shift() }
+func (t *priorityTransactions) Pop()                          { t.current().Pop() }
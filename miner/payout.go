@@ -0,0 +1,390 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// payoutDepth is the number of confirmations after which the income of a block
+// is split among the payout recipients. Payouts of shallower blocks are tracked
+// as in flight, so that they are not paid twice.
+const payoutDepth = 7
+
+var (
+	errNoPayoutSigner   = errors.New("payout split requires an etherbase signer")
+	errZeroPayoutWeight = errors.New("payout weight must be positive")
+)
+
+// PayoutShare is a recipient of a weighted share of the etherbase income.
+type PayoutShare struct {
+	Address common.Address
+	Weight  uint64
+}
+
+// PayoutSignerFn signs a payout transaction of the etherbase account, with
+// replay protection for the given chain ID, or without if it's nil.
+type PayoutSignerFn func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+// PayoutStatus is the configured payout split and the amounts still owed to
+// each recipient.
+type PayoutStatus struct {
+	Etherbase common.Address
+	Shares    []PayoutShare
+	MinPayout *big.Int
+	Owed      map[common.Address]*big.Int
+}
+
+// payoutTx is a payout transaction built into a sealing block.
+type payoutTx struct {
+	to     common.Address
+	amount *big.Int // Amount deducted from the owed balance, value and fees
+	number uint64   // Number of the block the payout was built for
+}
+
+// payoutState is the database representation of the payout split state of an
+// etherbase.
+type payoutState struct {
+	Scanned uint64
+	Owed    []payoutDebt
+	Sent    []payoutSent
+}
+
+// payoutDebt is an amount owed to a payout recipient.
+type payoutDebt struct {
+	Recipient common.Address
+	Amount    *big.Int
+}
+
+// payoutSent is a persisted payout transaction, not yet final.
+type payoutSent struct {
+	Hash   common.Hash
+	To     common.Address
+	Amount *big.Int
+	Number uint64
+}
+
+// payoutSplitter splits the income of the etherbase, its block rewards and the
+// fees of the blocks it mined, among weighted recipients. The split is settled
+// by the miner with plain transfers from the etherbase at the top of the blocks
+// it mines, consensus is unaffected.
+//
+// The income is accounted once blocks are payoutDepth deep in the canonical
+// chain. The owed amounts are persisted per etherbase, so they survive restarts
+// and stay due across etherbase changes until paid from that etherbase.
+type payoutSplitter struct {
+	lock      sync.Mutex
+	db        ethdb.KeyValueStore // Database persisting the owed amounts
+	loaded    bool                // Whether the state of the etherbase was loaded
+	etherbase common.Address
+	shares    []PayoutShare
+	total     uint64                      // Sum of the share weights
+	minPayout *big.Int                    // Minimum amount worth a payout transaction
+	signFn    PayoutSignerFn              // Signer of the etherbase payout transactions
+	scanned   uint64                      // Last block accounted for
+	owed      map[common.Address]*big.Int // Amounts owed to the recipients
+	sent      map[common.Hash]*payoutTx   // Payout transactions built, not yet final
+}
+
+func newPayoutSplitter(db ethdb.KeyValueStore) *payoutSplitter {
+	return &payoutSplitter{
+		db:   db,
+		owed: make(map[common.Address]*big.Int),
+		sent: make(map[common.Hash]*payoutTx),
+	}
+}
+
+// set configures the payout split of the given etherbase. If the split wasn't
+// active yet, it resumes from the persisted state of the etherbase, or starts
+// with the income of the blocks following head. An empty share list disables
+// the split, the owed amounts stay due.
+func (p *payoutSplitter) set(etherbase common.Address, shares []PayoutShare, minPayout *big.Int, signFn PayoutSignerFn, head uint64) error {
+	var total uint64
+	seen := make(map[common.Address]bool)
+	for _, share := range shares {
+		if share.Weight == 0 {
+			return fmt.Errorf("%w: %v", errZeroPayoutWeight, share.Address)
+		}
+		if seen[share.Address] {
+			return fmt.Errorf("duplicate payout recipient %v", share.Address)
+		}
+		seen[share.Address] = true
+		if total+share.Weight < total {
+			return errors.New("payout weights overflow")
+		}
+		total += share.Weight
+	}
+	if len(shares) > 0 && signFn == nil {
+		return errNoPayoutSigner
+	}
+	if minPayout == nil {
+		minPayout = new(big.Int)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// The owed amounts of the previous etherbase stay due until paid from it
+	if !p.loaded || etherbase != p.etherbase {
+		if p.loaded {
+			p.store()
+		}
+		p.load(etherbase)
+	}
+	switch {
+	case len(shares) == 0:
+		p.scanned = 0 // the income is not split while disabled
+	case len(p.shares) == 0 && p.scanned == 0:
+		p.scanned = head
+	}
+	p.etherbase = etherbase
+	p.shares = append([]PayoutShare(nil), shares...)
+	p.total = total
+	p.minPayout = new(big.Int).Set(minPayout)
+	p.signFn = signFn
+	p.store()
+	return nil
+}
+
+// load replaces the owed amounts and in flight payouts with the ones persisted
+// for the given etherbase.
+func (p *payoutSplitter) load(etherbase common.Address) {
+	p.loaded = true
+	p.scanned = 0
+	p.owed = make(map[common.Address]*big.Int)
+	p.sent = make(map[common.Hash]*payoutTx)
+
+	blob := rawdb.ReadPayoutState(p.db, etherbase)
+	if len(blob) == 0 {
+		return
+	}
+	var state payoutState
+	if err := rlp.DecodeBytes(blob, &state); err != nil {
+		log.Warn("Failed to decode payout state", "etherbase", etherbase, "err", err)
+		return
+	}
+	p.scanned = state.Scanned
+	for _, debt := range state.Owed {
+		p.owed[debt.Recipient] = debt.Amount
+	}
+	for _, sent := range state.Sent {
+		p.sent[sent.Hash] = &payoutTx{to: sent.To, amount: sent.Amount, number: sent.Number}
+	}
+	if len(p.owed) > 0 {
+		log.Info("Loaded owed payouts", "etherbase", etherbase, "recipients", len(p.owed), "scanned", p.scanned)
+	}
+}
+
+// store persists the owed amounts and in flight payouts of the etherbase.
+func (p *payoutSplitter) store() {
+	if p.scanned == 0 && len(p.owed) == 0 && len(p.sent) == 0 {
+		rawdb.DeletePayoutState(p.db, p.etherbase)
+		return
+	}
+	state := &payoutState{Scanned: p.scanned}
+	for addr, amount := range p.owed {
+		state.Owed = append(state.Owed, payoutDebt{Recipient: addr, Amount: amount})
+	}
+	for hash, sent := range p.sent {
+		state.Sent = append(state.Sent, payoutSent{Hash: hash, To: sent.to, Amount: sent.amount, Number: sent.number})
+	}
+	blob, err := rlp.EncodeToBytes(state)
+	if err != nil {
+		log.Crit("Failed to encode payout state", "err", err)
+	}
+	rawdb.WritePayoutState(p.db, p.etherbase, blob)
+}
+
+// status returns the configured split and the owed amounts.
+func (p *payoutSplitter) status() *PayoutStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	status := &PayoutStatus{
+		Etherbase: p.etherbase,
+		Shares:    append([]PayoutShare(nil), p.shares...),
+		MinPayout: new(big.Int),
+		Owed:      make(map[common.Address]*big.Int, len(p.owed)),
+	}
+	if p.minPayout != nil {
+		status.MinPayout.Set(p.minPayout)
+	}
+	for addr, amount := range p.owed {
+		status.Owed[addr] = new(big.Int).Set(amount)
+	}
+	return status
+}
+
+// payouts accounts the income of the blocks which became final since the last
+// call, and returns the signed payout transactions to include at the top of
+// the block built on top of head, paid from the given etherbase.
+func (p *payoutSplitter) payouts(chain *core.BlockChain, config ctypes.ChainConfigurator, etherbase common.Address, header *types.Header, nonce uint64) types.Transactions {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.shares) == 0 {
+		return nil
+	}
+	if etherbase != p.etherbase {
+		log.Warn("Etherbase changed, disabling payout split", "old", p.etherbase, "new", etherbase, "owed", len(p.owed))
+		p.shares = nil
+		return nil
+	}
+	head := header.Number.Uint64() - 1
+	p.account(chain, config, head)
+	defer p.store()
+
+	// Deduct the payouts included in the chain but not final yet
+	available := make(map[common.Address]*big.Int, len(p.owed))
+	for addr, amount := range p.owed {
+		available[addr] = new(big.Int).Set(amount)
+	}
+	inflight := make(map[common.Hash]bool)
+	for number := head; number > p.scanned && number > 0; number-- {
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			break
+		}
+		for _, tx := range block.Transactions() {
+			if sent, ok := p.sent[tx.Hash()]; ok {
+				if amount := available[sent.to]; amount != nil {
+					amount.Sub(amount, sent.amount)
+				}
+				inflight[tx.Hash()] = true
+			}
+		}
+	}
+	for hash, sent := range p.sent {
+		if sent.number <= head && !inflight[hash] {
+			delete(p.sent, hash) // payout of a block which wasn't mined
+		}
+	}
+	// Pay the owed amounts above the minimum in share order
+	var (
+		gasPrice = new(big.Int)
+		chainID  *big.Int
+		txs      types.Transactions
+	)
+	if header.BaseFee != nil {
+		gasPrice.Set(header.BaseFee)
+	}
+	if config.IsEnabled(config.GetEIP155Transition, header.Number) {
+		chainID = config.GetChainID()
+	}
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(vars.TxGas))
+	for _, share := range p.shares {
+		amount := available[share.Address]
+		if amount == nil || amount.Cmp(fee) <= 0 || amount.Cmp(p.minPayout) < 0 {
+			continue
+		}
+		tx, err := p.signFn(types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasPrice,
+			Gas:      vars.TxGas,
+			To:       &share.Address,
+			Value:    new(big.Int).Sub(amount, fee),
+		}), chainID)
+		if err != nil {
+			log.Warn("Failed to sign payout transaction", "etherbase", etherbase, "recipient", share.Address, "err", err)
+			return txs
+		}
+		p.sent[tx.Hash()] = &payoutTx{to: share.Address, amount: new(big.Int).Set(amount), number: header.Number.Uint64()}
+		txs = append(txs, tx)
+		nonce++
+	}
+	return txs
+}
+
+// account credits the recipients with their shares of the etherbase income of
+// the blocks which became final, and settles the final payouts.
+func (p *payoutSplitter) account(chain *core.BlockChain, config ctypes.ChainConfigurator, head uint64) {
+	for head >= payoutDepth && p.scanned < head-payoutDepth+1 {
+		number := p.scanned + 1
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return
+		}
+		p.scanned = number
+
+		for _, tx := range block.Transactions() {
+			if sent, ok := p.sent[tx.Hash()]; ok {
+				if owed := p.owed[sent.to]; owed != nil {
+					if owed.Sub(owed, sent.amount); owed.Sign() <= 0 {
+						delete(p.owed, sent.to)
+					}
+				}
+				delete(p.sent, tx.Hash())
+			}
+		}
+		income := blockIncome(chain, config, block, p.etherbase)
+		if income.Sign() == 0 {
+			continue
+		}
+		for _, share := range p.shares {
+			if share.Address == p.etherbase {
+				continue // the etherbase keeps its share
+			}
+			amount := new(big.Int).Mul(income, new(big.Int).SetUint64(share.Weight))
+			amount.Div(amount, new(big.Int).SetUint64(p.total))
+			if owed := p.owed[share.Address]; owed != nil {
+				owed.Add(owed, amount)
+			} else {
+				p.owed[share.Address] = amount
+			}
+		}
+		log.Debug("Accounted etherbase income for payout", "number", number, "hash", block.Hash(), "income", income)
+	}
+}
+
+// blockIncome returns the rewards credited to the etherbase by the block, and
+// the transaction fees paid to it if it mined the block.
+func blockIncome(chain *core.BlockChain, config ctypes.ChainConfigurator, block *types.Block, etherbase common.Address) *big.Int {
+	income := new(big.Int)
+	for _, reward := range core.BlockRewards(config, block.Header(), block.Uncles(), block.Transactions()) {
+		if reward.Recipient == etherbase {
+			income.Add(income, reward.Amount)
+		}
+	}
+	if block.Coinbase() != etherbase || len(block.Transactions()) == 0 {
+		return income
+	}
+	receipts := chain.GetReceiptsByHash(block.Hash())
+	if len(receipts) != len(block.Transactions()) {
+		log.Warn("Missing receipts of mined block", "number", block.Number(), "hash", block.Hash())
+		return income
+	}
+	for i, tx := range block.Transactions() {
+		tip, err := tx.EffectiveGasTip(block.BaseFee())
+		if err != nil {
+			continue
+		}
+		income.Add(income, tip.Mul(tip, new(big.Int).SetUint64(receipts[i].GasUsed)))
+	}
+	return income
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// Tests that the etherbase income of final blocks is split by weight, and that
+// payouts included in the chain are not paid twice.
+func TestPayoutSplit(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		etherbase = crypto.PubkeyToAddress(key.PublicKey)
+		alice     = common.Address{0xa}
+		bob       = common.Address{0xb}
+		engine    = ethash.NewFaker()
+		gspec     = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  genesisT.GenesisAlloc{etherbase: {Balance: big.NewInt(vars.Ether)}},
+		}
+		signFn = func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+			return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+		}
+	)
+	genDb, blocks, _ := core.GenerateChainWithGenesis(gspec, engine, 10, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(etherbase)
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	config := chain.Config()
+
+	db := rawdb.NewMemoryDatabase()
+	splitter := newPayoutSplitter(db)
+	if err := splitter.set(etherbase, []PayoutShare{{alice, 1}, {bob, 3}, {etherbase, 4}}, nil, signFn, 0); err != nil {
+		t.Fatalf("failed to set payout split: %v", err)
+	}
+	// owed sums the share of the given weight in the rewards of the blocks
+	owed := func(from, to uint64, weight int64) *big.Int {
+		sum := new(big.Int)
+		for number := from; number <= to; number++ {
+			block := chain.GetBlockByNumber(number)
+			for _, reward := range core.BlockRewards(config, block.Header(), block.Uncles(), block.Transactions()) {
+				if reward.Recipient == etherbase {
+					share := new(big.Int).Mul(reward.Amount, big.NewInt(weight))
+					sum.Add(sum, share.Div(share, big.NewInt(8)))
+				}
+			}
+		}
+		return sum
+	}
+	check := func(txs types.Transactions, baseFee *big.Int, want map[common.Address]*big.Int) {
+		t.Helper()
+		if len(txs) != len(want) {
+			t.Fatalf("payout count mismatch: have %d, want %d", len(txs), len(want))
+		}
+		fee := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(vars.TxGas))
+		for _, tx := range txs {
+			value := new(big.Int).Sub(want[*tx.To()], fee)
+			if tx.Value().Cmp(value) != 0 {
+				t.Errorf("payout to %v mismatch: have %v, want %v", tx.To(), tx.Value(), value)
+			}
+		}
+	}
+	// Blocks 1 to 4 are final with the head at 10
+	statedb, _ := chain.State()
+	header := &types.Header{Number: big.NewInt(11), BaseFee: eip1559.CalcBaseFee(config, chain.CurrentBlock())}
+	txs := splitter.payouts(chain, config, etherbase, header, statedb.GetNonce(etherbase))
+	check(txs, header.BaseFee, map[common.Address]*big.Int{alice: owed(1, 4, 1), bob: owed(1, 4, 3)})
+
+	// Include the payouts, only the share of block 5 is left to pay
+	more, _ := core.GenerateChain(config, blocks[len(blocks)-1], engine, genDb, 1, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(etherbase)
+		for _, tx := range txs {
+			gen.AddTx(tx)
+		}
+	})
+	if _, err := chain.InsertChain(more); err != nil {
+		t.Fatalf("failed to insert payouts: %v", err)
+	}
+	statedb, _ = chain.State()
+	header = &types.Header{Number: big.NewInt(12), BaseFee: eip1559.CalcBaseFee(config, chain.CurrentBlock())}
+	txs = splitter.payouts(chain, config, etherbase, header, statedb.GetNonce(etherbase))
+	check(txs, header.BaseFee, map[common.Address]*big.Int{alice: owed(5, 5, 1), bob: owed(5, 5, 3)})
+
+	if status := splitter.status(); status.Owed[alice].Cmp(owed(1, 5, 1)) != 0 {
+		t.Errorf("owed amount mismatch: have %v, want %v", status.Owed[alice], owed(1, 5, 1))
+	}
+	// The owed amounts and in flight payouts survive a restart
+	restarted := newPayoutSplitter(db)
+	if err := restarted.set(etherbase, []PayoutShare{{alice, 1}, {bob, 3}, {etherbase, 4}}, nil, signFn, 11); err != nil {
+		t.Fatalf("failed to set payout split: %v", err)
+	}
+	if status := restarted.status(); status.Owed[alice].Cmp(owed(1, 5, 1)) != 0 || status.Owed[bob].Cmp(owed(1, 5, 3)) != 0 {
+		t.Errorf("restored owed amounts mismatch: have %v", status.Owed)
+	}
+	txs = restarted.payouts(chain, config, etherbase, header, statedb.GetNonce(etherbase))
+	check(txs, header.BaseFee, map[common.Address]*big.Int{alice: owed(5, 5, 1), bob: owed(5, 5, 3)})
+
+	// The owed amounts stay due across etherbase changes
+	other := common.Address{0xe}
+	if err := restarted.set(other, []PayoutShare{{alice, 1}}, nil, signFn, 11); err != nil {
+		t.Fatalf("failed to set payout split: %v", err)
+	}
+	if status := restarted.status(); len(status.Owed) != 0 {
+		t.Errorf("owed amounts of other etherbase: have %v", status.Owed)
+	}
+	if err := restarted.set(etherbase, []PayoutShare{{alice, 1}, {bob, 3}, {etherbase, 4}}, nil, signFn, 11); err != nil {
+		t.Fatalf("failed to set payout split: %v", err)
+	}
+	if status := restarted.status(); status.Owed[alice].Cmp(owed(1, 5, 1)) != 0 {
+		t.Errorf("owed amount mismatch after etherbase change: have %v, want %v", status.Owed[alice], owed(1, 5, 1))
+	}
+	// Invalid splits are rejected
+	if err := splitter.set(etherbase, []PayoutShare{{alice, 0}}, nil, signFn, 0); err == nil {
+		t.Error("expected error for zero weight")
+	}
+	if err := splitter.set(etherbase, []PayoutShare{{alice, 1}}, nil, nil, 0); err == nil {
+		t.Error("expected error for missing signer")
+	}
+}
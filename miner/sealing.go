@@ -0,0 +1,99 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// SealPolicy controls which proof-of-work sealing tasks the worker releases
+// to the consensus engine.
+//
+// The transaction thresholds hold back non-empty work which doesn't meet any
+// of them, as long as the sealer already has work on the same parent, so the
+// empty block (or the previously released work) keeps being sealed until the
+// pool provides enough. A threshold never leaves the sealer working on a stale
+// parent.
+type SealPolicy struct {
+	MinTxs      uint64        // Minimum number of transactions of released work, 0 to disable
+	MinFees     *big.Int      // Minimum fees paid to the coinbase by released work, nil to disable
+	FillTimeout time.Duration // Maximum time spent filling transactions into a block, 0 for no limit
+	PauseEmpty  bool          // Whether to skip sealing empty blocks while the pool has pending transactions
+}
+
+// newSealPolicy creates the sealing policy of the miner config.
+func newSealPolicy(config *Config) *SealPolicy {
+	policy := &SealPolicy{
+		MinTxs:      config.MinTxs,
+		FillTimeout: config.FillTimeout,
+		PauseEmpty:  config.PauseEmpty,
+	}
+	if config.MinFees != nil {
+		policy.MinFees = new(big.Int).Set(config.MinFees)
+	}
+	return policy
+}
+
+// validate checks the sanity of the policy values.
+func (p *SealPolicy) validate() error {
+	if p.MinFees != nil && p.MinFees.Sign() < 0 {
+		return errors.New("negative minimum fees")
+	}
+	if p.FillTimeout < 0 {
+		return errors.New("negative fill timeout")
+	}
+	return nil
+}
+
+// copy returns a deep copy of the policy.
+func (p *SealPolicy) copy() *SealPolicy {
+	cpy := *p
+	if p.MinFees != nil {
+		cpy.MinFees = new(big.Int).Set(p.MinFees)
+	}
+	return &cpy
+}
+
+// release reports whether work with the given number of transactions and fees
+// meets the thresholds of the policy. Work meets the policy if it reaches any of
+// the configured thresholds, or if none is configured.
+func (p *SealPolicy) release(txs int, fees *big.Int) bool {
+	minFees := p.MinFees != nil && p.MinFees.Sign() > 0
+	if p.MinTxs == 0 && !minFees {
+		return true
+	}
+	if p.MinTxs > 0 && uint64(txs) >= p.MinTxs {
+		return true
+	}
+	return minFees && fees.Cmp(p.MinFees) >= 0
+}
+
+// envFees returns the fees paid to the coinbase by the transactions included
+// into the sealing block.
+func envFees(env *environment) *big.Int {
+	fees := new(big.Int)
+	for i, tx := range env.txs {
+		tip, err := tx.EffectiveGasTip(env.header.BaseFee)
+		if err != nil {
+			continue
+		}
+		fees.Add(fees, tip.Mul(tip, new(big.Int).SetUint64(env.receipts[i].GasUsed)))
+	}
+	return fees
+}
@@ -0,0 +1,381 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// statsEpochLength is the number of blocks of a statistics epoch, the
+	// length of an EthashB3 epoch.
+	statsEpochLength = 43200
+
+	// statsReorgBuckets is the number of reorg depth buckets, the last one
+	// counting the reorgs deeper than the others.
+	statsReorgBuckets = 8
+
+	// statsMaxHeadGap is the maximum number of blocks accounted at once on a
+	// head change. Larger gaps are chain syncs and aren't accounted.
+	statsMaxHeadGap = 1024
+)
+
+var (
+	sealedMinedMeter     = metrics.NewRegisteredMeter("miner/sealed/mined", nil)
+	sealedCanonicalMeter = metrics.NewRegisteredMeter("miner/sealed/canonical", nil)
+	sealedUncleMeter     = metrics.NewRegisteredMeter("miner/sealed/uncle", nil)
+	sealedLostMeter      = metrics.NewRegisteredMeter("miner/sealed/lost", nil)
+	sealedReorgedMeter   = metrics.NewRegisteredMeter("miner/sealed/reorged", nil)
+
+	chainBlockMeter  = metrics.NewRegisteredMeter("miner/chain/blocks", nil)
+	chainUncleMeter  = metrics.NewRegisteredMeter("miner/chain/uncles", nil)
+	chainSideMeter   = metrics.NewRegisteredMeter("miner/chain/sides", nil)
+	chainReorgHist   = metrics.NewRegisteredHistogram("miner/chain/reorgdepth", nil, metrics.NewExpDecaySample(1028, 0.015))
+	uncleRateGauge   = metrics.NewRegisteredGaugeFloat64("miner/chain/unclerate", nil)
+	orphanRateGauge  = metrics.NewRegisteredGaugeFloat64("miner/chain/orphanrate", nil)
+	sealedLostGauge  = metrics.NewRegisteredGaugeFloat64("miner/sealed/lostrate", nil)
+	sealedUncleGauge = metrics.NewRegisteredGaugeFloat64("miner/sealed/unclerate", nil)
+)
+
+// SealingEpochRange returns the first and last block numbers of the given
+// sealing statistics epoch.
+func SealingEpochRange(epoch uint64) (uint64, uint64) {
+	return epoch * statsEpochLength, (epoch+1)*statsEpochLength - 1
+}
+
+// sealedStatus is the outcome of a locally sealed block once it's deep enough
+// in the chain.
+type sealedStatus int
+
+const (
+	sealedCanonical sealedStatus = iota // The block reached the canonical chain
+	sealedUncle                         // The block was included as an uncle
+	sealedLost                          // The block was neither canonical nor an uncle
+)
+
+// chainDbBackend is implemented by mining backends with access to the chain
+// database, where the sealing statistics are persisted.
+type chainDbBackend interface {
+	ChainDb() ethdb.Database
+}
+
+// SealingStats are the statistics of the chain and the blocks sealed locally
+// during an epoch of statsEpochLength blocks.
+type SealingStats struct {
+	Epoch uint64 `rlp:"-"`
+
+	Blocks uint64 // Canonical blocks imported
+	Uncles uint64 // Uncles included by the canonical blocks
+	Sides  uint64 // Side chain blocks imported, including the reorged ones
+
+	Mined     uint64 // Blocks sealed locally
+	Canonical uint64 // Sealed blocks which reached the canonical chain
+	Included  uint64 // Sealed blocks included as uncles
+	Lost      uint64 // Sealed blocks neither canonical nor included as uncles
+	Reorged   uint64 // Sealed blocks removed from the canonical chain by a reorg
+
+	Reorgs []uint64 // Reorg count by depth, starting at one block
+}
+
+// UncleRate returns the share of uncles among the canonical blocks.
+func (s *SealingStats) UncleRate() float64 {
+	if s.Blocks == 0 {
+		return 0
+	}
+	return float64(s.Uncles) / float64(s.Blocks)
+}
+
+// OrphanRate returns the share of the imported blocks which ended up neither
+// canonical nor included as uncles.
+func (s *SealingStats) OrphanRate() float64 {
+	if s.Sides <= s.Uncles {
+		return 0
+	}
+	orphans := s.Sides - s.Uncles
+	return float64(orphans) / float64(s.Blocks+orphans)
+}
+
+// SealedUncleRate returns the share of uncles among the settled sealed blocks.
+func (s *SealingStats) SealedUncleRate() float64 {
+	if settled := s.Canonical + s.Included + s.Lost; settled > 0 {
+		return float64(s.Included) / float64(settled)
+	}
+	return 0
+}
+
+// SealedLostRate returns the share of lost blocks among the settled sealed
+// blocks.
+func (s *SealingStats) SealedLostRate() float64 {
+	if settled := s.Canonical + s.Included + s.Lost; settled > 0 {
+		return float64(s.Lost) / float64(settled)
+	}
+	return 0
+}
+
+// sealingStats tracks the uncle and orphan rates of the chain, the outcome of
+// the locally sealed blocks and the depth of reorgs, persisting the statistics
+// by epoch in the database.
+type sealingStats struct {
+	chain *core.BlockChain
+	db    ethdb.KeyValueStore
+
+	lock   sync.Mutex
+	epochs map[uint64]*SealingStats // Statistics of the epochs loaded
+	head   *types.Header            // Last chain head accounted for
+	sealed map[common.Hash]uint64   // Sealed blocks which aren't settled yet
+}
+
+func newSealingStats(chain *core.BlockChain, db ethdb.KeyValueStore) *sealingStats {
+	return &sealingStats{
+		chain:  chain,
+		db:     db,
+		epochs: make(map[uint64]*SealingStats),
+		sealed: make(map[common.Hash]uint64),
+	}
+}
+
+// epoch returns the statistics of the epoch containing the given block,
+// loading them from the database if needed. The lock must be held.
+func (s *sealingStats) epoch(number uint64) *SealingStats {
+	epoch := number / statsEpochLength
+	if stats := s.epochs[epoch]; stats != nil {
+		return stats
+	}
+	stats := &SealingStats{Epoch: epoch}
+	if blob := rawdb.ReadSealingStats(s.db, epoch); len(blob) > 0 {
+		if err := rlp.DecodeBytes(blob, stats); err != nil {
+			log.Warn("Failed to decode sealing statistics", "epoch", epoch, "err", err)
+			stats = &SealingStats{Epoch: epoch}
+		}
+	}
+	if len(stats.Reorgs) != statsReorgBuckets {
+		stats.Reorgs = append(stats.Reorgs, make([]uint64, statsReorgBuckets)...)[:statsReorgBuckets]
+	}
+	// Only the recent epochs are updated, drop the old ones
+	for old := range s.epochs {
+		if old+1 < epoch {
+			delete(s.epochs, old)
+		}
+	}
+	s.epochs[epoch] = stats
+	return stats
+}
+
+// store persists the statistics of the epoch. The lock must be held.
+func (s *sealingStats) store(stats *SealingStats) {
+	blob, err := rlp.EncodeToBytes(stats)
+	if err != nil {
+		log.Crit("Failed to encode sealing statistics", "err", err)
+	}
+	rawdb.WriteSealingStats(s.db, stats.Epoch, blob)
+}
+
+// updateSealingGauges sets the rate gauges to the statistics of the epoch.
+func updateSealingGauges(stats *SealingStats) {
+	uncleRateGauge.Update(stats.UncleRate())
+	orphanRateGauge.Update(stats.OrphanRate())
+	sealedUncleGauge.Update(stats.SealedUncleRate())
+	sealedLostGauge.Update(stats.SealedLostRate())
+}
+
+// mined records a block sealed locally.
+func (s *sealingStats) mined(number uint64, hash common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sealed[hash] = number
+	stats := s.epoch(number)
+	stats.Mined++
+	s.store(stats)
+	sealedMinedMeter.Mark(1)
+}
+
+// settled records the outcome of a block sealed locally.
+func (s *sealingStats) settled(number uint64, hash common.Hash, status sealedStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.sealed, hash)
+	stats := s.epoch(number)
+	switch status {
+	case sealedCanonical:
+		stats.Canonical++
+		sealedCanonicalMeter.Mark(1)
+	case sealedUncle:
+		stats.Included++
+		sealedUncleMeter.Mark(1)
+	case sealedLost:
+		stats.Lost++
+		sealedLostMeter.Mark(1)
+	}
+	s.store(stats)
+	updateSealingGauges(stats)
+}
+
+// side records a block imported as a side chain block, or removed from the
+// canonical chain by a reorg.
+func (s *sealingStats) side(block *types.Block) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats := s.epoch(block.NumberU64())
+	stats.Sides++
+	s.store(stats)
+	chainSideMeter.Mark(1)
+}
+
+// newHead accounts the blocks added to the canonical chain since the previous
+// head, and the blocks removed from it if the chain was reorganised.
+func (s *sealingStats) newHead(head *types.Header) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	prev := s.head
+	s.head = head
+	if prev == nil || prev.Hash() == head.Hash() {
+		return
+	}
+	// Walk back both heads to their common ancestor
+	var (
+		added   []*types.Header
+		dropped []*types.Header
+		oldHead = prev
+		newHead = head
+	)
+	for newHead != nil && oldHead != nil && newHead.Hash() != oldHead.Hash() {
+		if len(added) > statsMaxHeadGap || len(dropped) > statsMaxHeadGap {
+			log.Debug("Skipping sealing statistics of chain sync", "from", prev.Number, "to", head.Number)
+			return
+		}
+		if newHead.Number.Uint64() >= oldHead.Number.Uint64() {
+			added = append(added, newHead)
+			newHead = s.parent(newHead)
+		} else {
+			dropped = append(dropped, oldHead)
+			oldHead = s.parent(oldHead)
+		}
+	}
+	if newHead == nil || oldHead == nil {
+		return // missing ancestor, nothing reliable to account
+	}
+	touched := make(map[uint64]*SealingStats)
+	for _, header := range added {
+		stats := s.epoch(header.Number.Uint64())
+		stats.Blocks++
+		chainBlockMeter.Mark(1)
+		if header.UncleHash != types.EmptyUncleHash {
+			if block := s.chain.GetBlock(header.Hash(), header.Number.Uint64()); block != nil {
+				stats.Uncles += uint64(len(block.Uncles()))
+				chainUncleMeter.Mark(int64(len(block.Uncles())))
+			}
+		}
+		touched[stats.Epoch] = stats
+	}
+	for _, header := range dropped {
+		stats := s.epoch(header.Number.Uint64())
+		if stats.Blocks > 0 {
+			stats.Blocks--
+		}
+		if _, ok := s.sealed[header.Hash()]; ok {
+			stats.Reorged++
+			sealedReorgedMeter.Mark(1)
+		}
+		touched[stats.Epoch] = stats
+	}
+	if depth := len(dropped); depth > 0 {
+		stats := s.epoch(head.Number.Uint64())
+		bucket := depth - 1
+		if bucket >= statsReorgBuckets {
+			bucket = statsReorgBuckets - 1
+		}
+		stats.Reorgs[bucket]++
+		chainReorgHist.Update(int64(depth))
+		touched[stats.Epoch] = stats
+		log.Debug("Accounted chain reorg", "number", head.Number, "hash", head.Hash(), "depth", depth)
+	}
+	for _, stats := range touched {
+		s.store(stats)
+	}
+	updateSealingGauges(s.epoch(head.Number.Uint64()))
+}
+
+// parent retrieves the parent of the given header, or nil if it's unknown.
+func (s *sealingStats) parent(header *types.Header) *types.Header {
+	if header.Number.Sign() == 0 {
+		return nil
+	}
+	return s.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+}
+
+// recent returns the statistics of the given number of epochs up to the one
+// of the current chain head, oldest first.
+func (s *sealingStats) recent(epochs uint64) []*SealingStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	last := s.chain.CurrentBlock().Number.Uint64() / statsEpochLength
+	first := uint64(0)
+	if epochs <= last {
+		first = last - epochs + 1
+	}
+	var result []*SealingStats
+	for epoch := first; epoch <= last && epochs > 0; epoch++ {
+		stats := *s.epoch(epoch * statsEpochLength)
+		stats.Reorgs = append([]uint64(nil), stats.Reorgs...)
+		result = append(result, &stats)
+	}
+	return result
+}
+
+// statsLoop feeds the chain events into the sealing statistics.
+func (w *worker) statsLoop() {
+	defer w.wg.Done()
+
+	var (
+		headCh  = make(chan core.ChainHeadEvent, chainHeadChanSize)
+		headSub = w.chain.SubscribeChainHeadEvent(headCh)
+		sideCh  = make(chan core.ChainSideEvent, chainSideChanSize)
+		sideSub = w.chain.SubscribeChainSideEvent(sideCh)
+	)
+	defer headSub.Unsubscribe()
+	defer sideSub.Unsubscribe()
+
+	w.stats.newHead(w.chain.CurrentBlock())
+	for {
+		select {
+		case ev := <-headCh:
+			w.stats.newHead(ev.Block.Header())
+		case ev := <-sideCh:
+			w.stats.side(ev.Block)
+		case <-w.exitCh:
+			return
+		case <-headSub.Err():
+			return
+		case <-sideSub.Err():
+			return
+		}
+	}
+}
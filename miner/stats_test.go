@@ -0,0 +1,90 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+// Tests that the sealing statistics account the imported blocks, the reorgs
+// and the sealed blocks lost to them, and that they are persisted.
+func TestSealingStatsReorg(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		gspec  = &genesisT.Genesis{Config: params.TestChainConfig}
+	)
+	_, canon, _ := core.GenerateChainWithGenesis(gspec, engine, 5, func(i int, gen *core.BlockGen) {})
+	_, fork, _ := core.GenerateChainWithGenesis(gspec, engine, 7, func(i int, gen *core.BlockGen) {
+		if i >= 2 {
+			gen.SetCoinbase(common.Address{0x1})
+		}
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	db := rawdb.NewMemoryDatabase()
+	stats := newSealingStats(chain, db)
+	stats.newHead(chain.CurrentBlock())
+
+	if _, err := chain.InsertChain(canon); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	stats.mined(4, canon[3].Hash())
+	stats.newHead(chain.CurrentBlock())
+
+	// The fork replaces the blocks 3 to 5 of the canonical chain
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	stats.newHead(chain.CurrentBlock())
+	stats.settled(4, canon[3].Hash(), sealedLost)
+
+	check := func(s *SealingStats) {
+		t.Helper()
+		if s.Blocks != 7 {
+			t.Errorf("block count mismatch: have %d, want %d", s.Blocks, 7)
+		}
+		if s.Mined != 1 || s.Reorged != 1 || s.Lost != 1 {
+			t.Errorf("sealed block stats mismatch: mined %d, reorged %d, lost %d", s.Mined, s.Reorged, s.Lost)
+		}
+		want := make([]uint64, statsReorgBuckets)
+		want[2] = 1
+		for i := range want {
+			if s.Reorgs[i] != want[i] {
+				t.Errorf("reorg depth %d count mismatch: have %d, want %d", i+1, s.Reorgs[i], want[i])
+			}
+		}
+		if rate := s.SealedLostRate(); rate != 1 {
+			t.Errorf("lost rate mismatch: have %v, want %v", rate, 1)
+		}
+	}
+	check(stats.recent(1)[0])
+
+	// The statistics are reloaded from the database
+	check(newSealingStats(chain, db).recent(1)[0])
+}
@@ -51,6 +51,8 @@ type unconfirmedBlocks struct {
 	depth  uint           // Depth after which to discard previous blocks
 	blocks *ring.Ring     // Block infos to allow canonical chain cross checks
 	lock   sync.Mutex     // Protects the fields from concurrent access
+
+	stats *sealingStats // Optional statistics recording the outcome of the blocks
 }
 
 // newUnconfirmedBlocks returns new data structure to track currently unconfirmed blocks.
@@ -83,6 +85,9 @@ func (set *unconfirmedBlocks) Insert(index uint64, hash common.Hash) {
 	}
 	// Display a log for the user to notify of a new mined block unconfirmed
 	log.Info("🔨 mined potential block", "number", index, "hash", hash)
+	if set.stats != nil {
+		set.stats.mined(index, hash)
+	}
 }
 
 // Shift drops all unconfirmed blocks from the set which exceed the unconfirmed sets depth
@@ -105,6 +110,7 @@ func (set *unconfirmedBlocks) Shift(height uint64) {
 			log.Warn("Failed to retrieve header of mined block", "number", next.index, "hash", next.hash)
 		case header.Hash() == next.hash:
 			log.Info("🔗 block reached canonical chain", "number", next.index, "hash", next.hash)
+			set.settled(next, sealedCanonical)
 		default:
 			// Block is not canonical, check whether we have an uncle or a lost block
 			included := false
@@ -120,8 +126,10 @@ func (set *unconfirmedBlocks) Shift(height uint64) {
 			}
 			if included {
 				log.Info("⑂ block became an uncle", "number", next.index, "hash", next.hash)
+				set.settled(next, sealedUncle)
 			} else {
 				log.Info("😱 block lost", "number", next.index, "hash", next.hash)
+				set.settled(next, sealedLost)
 			}
 		}
 		// Drop the block out of the ring
@@ -134,3 +142,10 @@ func (set *unconfirmedBlocks) Shift(height uint64) {
 		}
 	}
 }
+
+// settled records the outcome of a block in the statistics, if any.
+func (set *unconfirmedBlocks) settled(block *unconfirmedBlock, status sealedStatus) {
+	if set.stats != nil {
+		set.stats.settled(block.index, block.hash, status)
+	}
+}
@@ -18,9 +18,11 @@ package miner
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,10 +33,12 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params/mutations"
@@ -87,6 +91,7 @@ var (
 	errBlockInterruptedByNewHead  = errors.New("new head arrived while building block")
 	errBlockInterruptedByRecommit = errors.New("recommit interrupt while building block")
 	errBlockInterruptedByTimeout  = errors.New("timeout while building block")
+	errReplayProtected            = errors.New("replay protected transaction before EIP-155")
 )
 
 // environment is the worker's current environment and holds all
@@ -238,11 +243,18 @@ type worker struct {
 	localUncles  map[common.Hash]*types.Block // A set of side blocks generated locally as the possible uncle blocks.
 	remoteUncles map[common.Hash]*types.Block // A set of side blocks as the possible uncle blocks.
 	unconfirmed  *unconfirmedBlocks           // A set of locally mined blocks pending canonicalness confirmations.
+	stats        *sealingStats                // Uncle, orphan and reorg statistics of the chain and the mined blocks.
 
 	mu       sync.RWMutex // The lock used to protect the coinbase and extra fields
 	coinbase common.Address
 	extra    []byte
 
+	ordering OrderingPolicy  // Policy deciding the order of the transactions included into blocks
+	payouts  *payoutSplitter // Split of the etherbase income among weighted recipients
+	sealing  *SealPolicy     // Policy deciding which sealing work is released, protected by mu
+
+	released common.Hash // Parent hash of the latest work released for sealing
+
 	pendingMu    sync.RWMutex
 	pendingTasks map[common.Hash]*task
 
@@ -311,6 +323,29 @@ func newWorker(config *Config, chainConfig ctypes.ChainConfigurator, engine cons
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
 	}
+	sealing := newSealPolicy(config)
+	if err := sealing.validate(); err != nil {
+		log.Error("Invalid sealing policy, using defaults", "err", err)
+		sealing = new(SealPolicy)
+	}
+	worker.sealing = sealing
+
+	// Persist the sealing statistics and the owed payouts in the chain database
+	// if available.
+	var db ethdb.KeyValueStore = rawdb.NewMemoryDatabase()
+	if backend, ok := eth.(chainDbBackend); ok {
+		db = backend.ChainDb()
+	}
+	worker.stats = newSealingStats(worker.chain, db)
+	worker.payouts = newPayoutSplitter(db)
+	worker.unconfirmed.stats = worker.stats
+	ordering, err := newOrderingPolicy(config)
+	if err != nil {
+		log.Error("Invalid transaction ordering policy, ordering by price", "err", err)
+		ordering = pricePolicy{}
+	}
+	worker.ordering = ordering
+
 	// Subscribe for transaction insertion events (whether from network or resurrects)
 	worker.txsSub = eth.TxPool().SubscribeTransactions(worker.txsCh, true)
 	// Subscribe events for blockchain
@@ -336,11 +371,12 @@ func newWorker(config *Config, chainConfig ctypes.ChainConfigurator, engine cons
 	}
 	worker.newpayloadTimeout = newpayloadTimeout
 
-	worker.wg.Add(4)
+	worker.wg.Add(5)
 	go worker.mainLoop()
 	go worker.newWorkLoop(recommit)
 	go worker.resultLoop()
 	go worker.taskLoop()
+	go worker.statsLoop()
 
 	// Submit first work to initialize pending state.
 	if init {
@@ -376,6 +412,24 @@ func (w *worker) setExtra(extra []byte) {
 	w.extra = extra
 }
 
+// setSealPolicy updates the policy deciding which sealing work is released.
+func (w *worker) setSealPolicy(policy *SealPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sealing = policy.copy()
+	return nil
+}
+
+// sealPolicy returns a copy of the sealing policy.
+func (w *worker) sealPolicy() *SealPolicy {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.sealing.copy()
+}
+
 // setRecommitInterval updates the interval for miner sealing work recommitting.
 func (w *worker) setRecommitInterval(interval time.Duration) {
 	select {
@@ -514,6 +568,9 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 
 		case head := <-w.chainHeadCh:
 			clearPending(head.Block.NumberU64())
+			if policy, ok := w.ordering.(*bundlePolicy); ok {
+				policy.prune(head.Block.NumberU64() + 1)
+			}
 			timestamp = time.Now().Unix()
 			commit(false, commitInterruptNewHead)
 
@@ -930,7 +987,7 @@ func (w *worker) applyTransaction(env *environment, tx *types.Transaction) (*typ
 	return receipt, err
 }
 
-func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAndNonce, interrupt *atomic.Int32) error {
+func (w *worker) commitTransactions(env *environment, txs TransactionSet, interrupt *atomic.Int32) error {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
@@ -1163,9 +1220,21 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 }
 
 // fillTransactions retrieves the pending transactions from the txpool and fills them
-// into the given sealing block. The transaction selection and ordering strategy can
-// be customized with the plugin in the future.
+// into the given sealing block. The transaction selection and ordering strategy is
+// decided by the configured ordering policy.
 func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) error {
+	// Settle the etherbase income split first, when mining for the etherbase.
+	if etherbase := w.etherbase(); env.coinbase == etherbase {
+		w.commitPayouts(env, etherbase)
+	}
+	// Commit the bundles first if the ordering policy provides any.
+	if policy, ok := w.ordering.(BundlePolicy); ok {
+		for _, bundle := range w.sortBundles(env, policy.Bundles(env.header)) {
+			if err := w.commitBundle(env, bundle, interrupt); err != nil {
+				return err
+			}
+		}
+	}
 	pending := w.eth.TxPool().Pending(true)
 
 	// Split the pending transactions into locals and remotes.
@@ -1179,13 +1248,13 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 
 	// Fill the block with all available pending transactions.
 	if len(localTxs) > 0 {
-		txs := newTransactionsByPriceAndNonce(env.signer, localTxs, env.header.BaseFee)
+		txs := w.ordering.Order(env.signer, localTxs, env.header.BaseFee)
 		if err := w.commitTransactions(env, txs, interrupt); err != nil {
 			return err
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := newTransactionsByPriceAndNonce(env.signer, remoteTxs, env.header.BaseFee)
+		txs := w.ordering.Order(env.signer, remoteTxs, env.header.BaseFee)
 		if err := w.commitTransactions(env, txs, interrupt); err != nil {
 			return err
 		}
@@ -1193,6 +1262,129 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 	return nil
 }
 
+// commitPayouts commits the payout transactions splitting the etherbase income
+// into the sealing block. Failed payouts are skipped, their amounts stay owed.
+func (w *worker) commitPayouts(env *environment, etherbase common.Address) {
+	txs := w.payouts.payouts(w.chain, w.chainConfig, etherbase, env.header, env.state.GetNonce(etherbase))
+	if len(txs) == 0 {
+		return
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, tx := range txs {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			log.Warn("Payout transaction failed", "hash", tx.Hash(), "to", tx.To(), "value", tx.Value(), "err", err)
+			return
+		}
+		env.tcount++
+	}
+}
+
+// setPayoutSplit configures the split of the etherbase income.
+func (w *worker) setPayoutSplit(shares []PayoutShare, minPayout *big.Int, signFn PayoutSignerFn) error {
+	return w.payouts.set(w.etherbase(), shares, minPayout, signFn, w.chain.CurrentBlock().Number.Uint64())
+}
+
+// commitBundle commits all transactions of a bundle into the sealing block, or
+// reverts the block to its previous state if any of them fails. A failed bundle
+// is skipped, only interruptions are returned as error.
+//
+// The bundle is applied on a copy of the state, as the state snapshots don't
+// survive the finalisation of the transactions already applied.
+func (w *worker) commitBundle(env *environment, bundle *Bundle, interrupt *atomic.Int32) error {
+	if interrupt != nil {
+		if signal := interrupt.Load(); signal != commitInterruptNone {
+			return signalToErr(signal)
+		}
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	var (
+		state     = env.state
+		gp        = env.gasPool.Gas()
+		gasUsed   = env.header.GasUsed
+		tcount    = env.tcount
+		ntxs      = len(env.txs)
+		nsidecars = len(env.sidecars)
+		blobs     = env.blobs
+	)
+	env.state = state.Copy()
+	for _, tx := range bundle.Txs {
+		var err error
+		if tx.Protected() && !w.chainConfig.IsEnabled(w.chainConfig.GetEIP155Transition, env.header.Number) {
+			err = errReplayProtected
+		} else {
+			env.state.SetTxContext(tx.Hash(), env.tcount)
+			_, err = w.commitTransaction(env, tx)
+		}
+		if err != nil {
+			log.Debug("Bundle transaction failed, bundle skipped", "hash", bundle.Hash(), "tx", tx.Hash(), "block", bundle.BlockNumber, "err", err)
+
+			env.state = state
+			env.gasPool.SetGas(gp)
+			env.header.GasUsed = gasUsed
+			env.tcount = tcount
+			env.txs, env.receipts = env.txs[:ntxs], env.receipts[:ntxs]
+			env.sidecars, env.blobs = env.sidecars[:nsidecars], blobs
+			return nil
+		}
+		env.tcount++
+	}
+	// The copy only holds an inactive copy of the prefetcher, stop the running one
+	state.StopPrefetcher()
+	return nil
+}
+
+// sortBundles orders bundles by the coinbase payment per gas they yield when
+// executed at the top of the sealing block, dropping the ones failing.
+func (w *worker) sortBundles(env *environment, bundles []*Bundle) []*Bundle {
+	type simulatedBundle struct {
+		bundle *Bundle
+		price  *big.Int
+	}
+	var (
+		simulated = make([]simulatedBundle, 0, len(bundles))
+		header    = types.CopyHeader(env.header)
+	)
+	header.Coinbase = env.coinbase
+	for _, bundle := range bundles {
+		result, err := SimulateBundle(context.Background(), w.chainConfig, w.chain, header, env.state.Copy(), bundle.Txs, *w.chain.GetVMConfig(), 0)
+		if err != nil {
+			log.Debug("Bundle simulation failed, bundle skipped", "hash", bundle.Hash(), "block", bundle.BlockNumber, "err", err)
+			continue
+		}
+		simulated = append(simulated, simulatedBundle{bundle: bundle, price: result.GasPrice()})
+	}
+	sort.SliceStable(simulated, func(i, j int) bool {
+		return simulated[i].price.Cmp(simulated[j].price) > 0
+	})
+	sorted := make([]*Bundle, len(simulated))
+	for i, sim := range simulated {
+		sorted[i] = sim.bundle
+	}
+	return sorted
+}
+
+// addBundle submits a transaction bundle for inclusion into the block it targets.
+// The bundle is accounted to the sender of its first transaction.
+func (w *worker) addBundle(bundle *Bundle) error {
+	policy, ok := w.ordering.(*bundlePolicy)
+	if !ok {
+		return errBundlesDisabled
+	}
+	if len(bundle.Txs) == 0 {
+		return errEmptyBundle
+	}
+	sender, err := types.Sender(types.LatestSigner(w.chainConfig), bundle.Txs[0])
+	if err != nil {
+		return err
+	}
+	return policy.addBundle(bundle, sender, w.chain.CurrentBlock().Number.Uint64())
+}
+
 // generateWork generates a sealing block based on the given parameters.
 func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 	work, err := w.prepareWork(params)
@@ -1249,10 +1441,32 @@ func (w *worker) commitWork(interrupt *atomic.Int32, noempty bool, timestamp int
 	if err != nil {
 		return
 	}
+	policy := w.sealPolicy()
+
 	// Create an empty block based on temporary copied state for
-	// sealing in advance without waiting block execution finished.
+	// sealing in advance without waiting block execution finished,
+	// unless the policy pauses empty blocks while transactions are
+	// pending.
 	if !noempty && !w.noempty.Load() {
-		w.commit(work.copy(), nil, false, start)
+		if pending, _ := w.eth.TxPool().Stats(); policy.PauseEmpty && pending > 0 {
+			log.Debug("Skipping empty sealing work", "number", work.header.Number, "pending", pending)
+		} else {
+			w.commit(work.copy(), nil, false, start)
+			if w.isRunning() {
+				w.released = work.header.ParentHash
+			}
+		}
+	}
+	// Cap the time spent filling transactions if the policy requires it,
+	// the block filled so far is sealed.
+	if interrupt == nil {
+		interrupt = new(atomic.Int32)
+	}
+	if policy.FillTimeout > 0 {
+		timer := time.AfterFunc(policy.FillTimeout, func() {
+			interrupt.CompareAndSwap(commitInterruptNone, commitInterruptTimeout)
+		})
+		defer timer.Stop()
 	}
 	// Fill pending transactions from the txpool into the block.
 	err = w.fillTransactions(interrupt, work)
@@ -1282,9 +1496,22 @@ func (w *worker) commitWork(interrupt *atomic.Int32, noempty bool, timestamp int
 		// which could result in higher uncle rate.
 		work.discard()
 		return
+
+	case errors.Is(err, errBlockInterruptedByTimeout):
+		log.Debug("Block filling reached the time cap", "number", work.header.Number, "txs", work.tcount, "allowance", common.PrettyDuration(policy.FillTimeout))
+	}
+	// Hold the work back if it doesn't meet the policy thresholds, while the
+	// sealer has work on the same parent. Otherwise submit the generated block
+	// for consensus sealing.
+	if fees := envFees(work); !policy.release(work.tcount, fees) && w.released == work.header.ParentHash {
+		log.Debug("Holding back sealing work below threshold", "number", work.header.Number, "txs", work.tcount, "fees", fees)
+		w.updateSnapshot(work)
+	} else {
+		w.commit(work.copy(), w.fullTaskHook, true, start)
+		if w.isRunning() {
+			w.released = work.header.ParentHash
+		}
 	}
-	// Submit the generated block for consensus sealing.
-	w.commit(work.copy(), w.fullTaskHook, true, start)
 
 	// Swap out the old work with the new one, terminating any leftover
 	// prefetcher processes in the mean time and starting a new one.
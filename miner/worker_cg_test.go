@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/params"
@@ -217,3 +218,122 @@ func TestGenerateBlockAndImport_CG1(t *testing.T) {
 		})
 	}
 }
+
+// slowOrdering is a price ordering policy delaying the filling of blocks.
+type slowOrdering struct {
+	delay time.Duration
+}
+
+func (o slowOrdering) Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet {
+	time.Sleep(o.delay)
+	return pricePolicy{}.Order(signer, txs, baseFee)
+}
+
+// newSealPolicyTestWorker creates a worker with the given sealing policy, and
+// returns the channel of the sealing tasks created for the first block.
+func newSealPolicyTestWorker(t *testing.T, policy *SealPolicy) (*worker, *testWorkerBackend, chan *task) {
+	w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	if err := w.setSealPolicy(policy); err != nil {
+		t.Fatalf("failed to set sealing policy: %v", err)
+	}
+	taskCh := make(chan *task, 16)
+	w.newTaskHook = func(task *task) {
+		if task.block.NumberU64() == 1 {
+			select {
+			case taskCh <- task:
+			default:
+			}
+		}
+	}
+	w.skipSealHook = func(task *task) bool { return true }
+	return w, b, taskCh
+}
+
+func waitSealPolicyTask(t *testing.T, taskCh chan *task) *task {
+	t.Helper()
+	select {
+	case task := <-taskCh:
+		return task
+	case <-time.After(3 * time.Second): // Worker needs 1s to include new changes.
+		t.Fatal("new task timeout")
+	}
+	return nil
+}
+
+func TestSealPolicyPauseEmpty(t *testing.T) {
+	w, _, taskCh := newSealPolicyTestWorker(t, &SealPolicy{PauseEmpty: true})
+	defer w.close()
+
+	// The pool has a pending transaction, no empty block is sealed.
+	w.start()
+	if task := waitSealPolicyTask(t, taskCh); len(task.receipts) != 1 {
+		t.Fatalf("receipt number mismatch: have %d, want %d", len(task.receipts), 1)
+	}
+}
+
+func TestSealPolicyMinTxs(t *testing.T) {
+	w, b, taskCh := newSealPolicyTestWorker(t, &SealPolicy{MinTxs: 2})
+	defer w.close()
+
+	// The empty block is sealed, the work with a single transaction is held back.
+	w.start()
+	if task := waitSealPolicyTask(t, taskCh); len(task.receipts) != 0 {
+		t.Fatalf("receipt number mismatch: have %d, want %d", len(task.receipts), 0)
+	}
+	b.txPool.Add(newTxs, true, false)
+	for {
+		task := waitSealPolicyTask(t, taskCh)
+		if len(task.receipts) == 0 {
+			continue // resubmitted empty block
+		}
+		if len(task.receipts) != 2 {
+			t.Fatalf("receipt number mismatch: have %d, want %d", len(task.receipts), 2)
+		}
+		return
+	}
+}
+
+func TestSealPolicyFillTimeout(t *testing.T) {
+	w, _, taskCh := newSealPolicyTestWorker(t, &SealPolicy{FillTimeout: 50 * time.Millisecond})
+	defer w.close()
+
+	// Filling takes longer than allowed, the block is sealed without the
+	// pending transaction.
+	w.disablePreseal()
+	w.ordering = slowOrdering{delay: 200 * time.Millisecond}
+	w.start()
+	if task := waitSealPolicyTask(t, taskCh); len(task.receipts) != 0 {
+		t.Fatalf("receipt number mismatch: have %d, want %d", len(task.receipts), 0)
+	}
+}
+
+func TestSealPolicyRelease(t *testing.T) {
+	cases := []struct {
+		policy SealPolicy
+		txs    int
+		fees   int64
+		want   bool
+	}{
+		{SealPolicy{}, 0, 0, true},
+		{SealPolicy{MinTxs: 2}, 1, 100, false},
+		{SealPolicy{MinTxs: 2}, 2, 0, true},
+		{SealPolicy{MinFees: big.NewInt(100)}, 5, 99, false},
+		{SealPolicy{MinFees: big.NewInt(100)}, 0, 100, true},
+		{SealPolicy{MinTxs: 10, MinFees: big.NewInt(100)}, 1, 100, true},
+		{SealPolicy{MinTxs: 10, MinFees: big.NewInt(100)}, 9, 99, false},
+	}
+	for i, c := range cases {
+		if have := c.policy.release(c.txs, big.NewInt(c.fees)); have != c.want {
+			t.Errorf("case %d: release mismatch: have %v, want %v", i, have, c.want)
+		}
+	}
+	w, _ := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	if err := w.setSealPolicy(&SealPolicy{MinFees: big.NewInt(-1)}); err == nil {
+		t.Error("expected error for negative minimum fees")
+	}
+	if err := w.setSealPolicy(&SealPolicy{FillTimeout: -time.Second}); err == nil {
+		t.Error("expected error for negative fill timeout")
+	}
+}
@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"crypto/ecdsa"
 	"crypto/rand"
 	"errors"
 	"math/big"
@@ -703,3 +704,268 @@ func testGetSealingWork(t *testing.T, chainConfig ctypes.ChainConfigurator, engi
 		}
 	}
 }
+
+// newOrderingTestWorker creates a worker with the given configuration on top of
+// a chain funding the given accounts.
+func newOrderingTestWorker(t *testing.T, config *Config, keys []*ecdsa.PrivateKey) (*worker, *testWorkerBackend) {
+	gspec := &genesisT.Genesis{
+		Config: ethashChainConfig,
+		Alloc:  genesisT.GenesisAlloc{},
+	}
+	for _, key := range keys {
+		gspec.Alloc[crypto.PubkeyToAddress(key.PublicKey)] = genesisT.GenesisAccount{Balance: testBankFunds}
+	}
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true}, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("core.NewBlockChain failed: %v", err)
+	}
+	pool := legacypool.New(testTxPoolConfig, chain)
+	txpool, _ := txpool.New(new(big.Int).SetUint64(testTxPoolConfig.PriceLimit), chain, []txpool.SubPool{pool})
+
+	backend := &testWorkerBackend{db: db, chain: chain, txPool: txpool, genesis: gspec}
+	return newWorker(config, ethashChainConfig, chain.Engine(), backend, new(event.TypeMux), nil, false), backend
+}
+
+// newOrderingTestTx creates a transfer paying the given multiple of the initial
+// base fee per gas.
+func newOrderingTestTx(key *ecdsa.PrivateKey, nonce uint64, to common.Address, price int64) *types.Transaction {
+	return types.MustSignNewTx(key, types.LatestSigner(ethashChainConfig), &types.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Value:    big.NewInt(1),
+		Gas:      vars.TxGas,
+		GasPrice: big.NewInt(price * vars.InitialBaseFee),
+	})
+}
+
+// sealOrderingTestBlock builds a block on top of the genesis with the pending
+// transactions of the worker's pool.
+func sealOrderingTestBlock(t *testing.T, w *worker, b *testWorkerBackend) *types.Block {
+	r := w.getSealingBlock(&generateParams{
+		parentHash: b.chain.Genesis().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   testBankAddress,
+	})
+	if r.err != nil {
+		t.Fatalf("failed to build block: %v", r.err)
+	}
+	return r.block
+}
+
+// Tests that the transactions of the pool are ordered by the configured policy.
+func TestTransactionOrderingPolicies(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys     = make([]*ecdsa.PrivateKey, 3)
+		addrs    = make([]common.Address, 3)
+		contract = common.HexToAddress("0xc0de")
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	tests := []struct {
+		name     string
+		ordering string
+		priority []common.Address
+		locals   []int // Accounts whose transaction is submitted as local
+		want     []int // Accounts in order of inclusion
+	}{
+		{name: "default", want: []int{2, 1, 0}},
+		{name: "price", ordering: OrderingPrice, want: []int{2, 1, 0}},
+		{name: "price-locals", ordering: OrderingPrice, locals: []int{0}, want: []int{0, 2, 1}},
+		{name: "fifo", ordering: OrderingFIFO, want: []int{0, 1, 2}},
+		{name: "fifo-locals", ordering: OrderingFIFO, locals: []int{2}, want: []int{2, 0, 1}},
+		{name: "priority-sender", ordering: OrderingPriority, priority: []common.Address{addrs[0]}, want: []int{0, 2, 1}},
+		{name: "priority-contract", ordering: OrderingPriority, priority: []common.Address{contract}, want: []int{1, 2, 0}},
+		{name: "bundle", ordering: OrderingBundle, want: []int{2, 1, 0}},
+	}
+	for _, tt := range tests {
+		config := *testConfig
+		config.Ordering = tt.ordering
+		config.PriorityAddresses = tt.priority
+
+		w, b := newOrderingTestWorker(t, &config, keys)
+
+		// Accounts are first seen in order, paying ever higher prices. The
+		// second one calls the contract.
+		for i, key := range keys {
+			to := testUserAddress
+			if i == 1 {
+				to = contract
+			}
+			local := false
+			for _, j := range tt.locals {
+				local = local || i == j
+			}
+			if errs := b.txPool.Add([]*types.Transaction{newOrderingTestTx(key, 0, to, int64(i+2))}, local, true); errs[0] != nil {
+				t.Fatalf("%s: failed to add transaction: %v", tt.name, errs[0])
+			}
+			time.Sleep(time.Millisecond)
+		}
+		block := sealOrderingTestBlock(t, w, b)
+		w.close()
+
+		if len(block.Transactions()) != len(tt.want) {
+			t.Fatalf("%s: transaction count mismatch: have %d, want %d", tt.name, len(block.Transactions()), len(tt.want))
+		}
+		signer := types.LatestSigner(ethashChainConfig)
+		for i, tx := range block.Transactions() {
+			if from, _ := types.Sender(signer, tx); from != addrs[tt.want[i]] {
+				t.Errorf("%s: transaction %d sender mismatch: have %x, want %x", tt.name, i, from, addrs[tt.want[i]])
+			}
+		}
+	}
+}
+
+// Tests that bundles are committed atomically at the top of the block they target.
+func TestTransactionOrderingBundles(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	config := *testConfig
+	config.Ordering = OrderingBundle
+
+	w, b := newOrderingTestWorker(t, &config, keys)
+	defer w.close()
+
+	pooled := newOrderingTestTx(keys[0], 0, testUserAddress, 4)
+	if errs := b.txPool.Add([]*types.Transaction{pooled}, false, true); errs[0] != nil {
+		t.Fatalf("failed to add transaction: %v", errs[0])
+	}
+	var (
+		// Bundle failing on its second transaction because of a nonce gap
+		invalid = &Bundle{
+			Txs:         types.Transactions{newOrderingTestTx(keys[1], 0, testUserAddress, 2), newOrderingTestTx(keys[2], 5, testUserAddress, 2)},
+			BlockNumber: 1,
+		}
+		// Bundle reusing the nonce consumed by the reverted invalid bundle
+		valid = &Bundle{
+			Txs:         types.Transactions{newOrderingTestTx(keys[1], 0, common.Address{0x01}, 2), newOrderingTestTx(keys[2], 0, testUserAddress, 2)},
+			BlockNumber: 1,
+		}
+		// Bundle targeting a later block
+		future = &Bundle{
+			Txs:         types.Transactions{newOrderingTestTx(keys[1], 1, testUserAddress, 2)},
+			BlockNumber: 2,
+		}
+	)
+	for _, bundle := range []*Bundle{invalid, valid, future} {
+		if err := w.addBundle(bundle); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	if err := w.addBundle(&Bundle{BlockNumber: 1}); err != errEmptyBundle {
+		t.Errorf("empty bundle error mismatch: have %v, want %v", err, errEmptyBundle)
+	}
+	block := sealOrderingTestBlock(t, w, b)
+
+	want := []common.Hash{valid.Txs[0].Hash(), valid.Txs[1].Hash(), pooled.Hash()}
+	if len(block.Transactions()) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), len(want))
+	}
+	for i, tx := range block.Transactions() {
+		if tx.Hash() != want[i] {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), want[i])
+		}
+	}
+	// Bundles can only be submitted with the bundle ordering policy
+	w, _ = newOrderingTestWorker(t, testConfig, keys)
+	defer w.close()
+
+	if err := w.addBundle(valid); err != errBundlesDisabled {
+		t.Errorf("bundle error mismatch: have %v, want %v", err, errBundlesDisabled)
+	}
+}
+
+// Tests that a bundle conflicting with a previously committed one is reverted,
+// even if some of its transactions were already applied.
+func TestTransactionOrderingBundleConflicts(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	config := *testConfig
+	config.Ordering = OrderingBundle
+
+	w, b := newOrderingTestWorker(t, &config, keys)
+	defer w.close()
+
+	// Transaction of which the nonce is only valid if the conflicting bundle
+	// is fully reverted
+	pooled := newOrderingTestTx(keys[2], 0, testUserAddress, 3)
+	if errs := b.txPool.Add([]*types.Transaction{pooled}, false, true); errs[0] != nil {
+		t.Fatalf("failed to add transaction: %v", errs[0])
+	}
+	var (
+		// Both bundles succeed on their own, the most profitable one first
+		first = &Bundle{
+			Txs:         types.Transactions{newOrderingTestTx(keys[0], 0, testUserAddress, 5), newOrderingTestTx(keys[1], 0, testUserAddress, 5)},
+			BlockNumber: 1,
+		}
+		// Fails on its second transaction once the first bundle consumed the nonce
+		conflict = &Bundle{
+			Txs:         types.Transactions{newOrderingTestTx(keys[2], 0, common.Address{0x01}, 4), newOrderingTestTx(keys[1], 0, common.Address{0x01}, 4)},
+			BlockNumber: 1,
+		}
+	)
+	for _, bundle := range []*Bundle{first, conflict} {
+		if err := w.addBundle(bundle); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	block := sealOrderingTestBlock(t, w, b)
+
+	want := []common.Hash{first.Txs[0].Hash(), first.Txs[1].Hash(), pooled.Hash()}
+	if len(block.Transactions()) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), len(want))
+	}
+	for i, tx := range block.Transactions() {
+		if tx.Hash() != want[i] {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), want[i])
+		}
+	}
+}
+
+// Tests that the bundles paying the coinbase the most per gas are committed first.
+func TestTransactionOrderingBundleProfit(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	config := *testConfig
+	config.Ordering = OrderingBundle
+
+	w, b := newOrderingTestWorker(t, &config, keys)
+	defer w.close()
+
+	var (
+		cheap  = &Bundle{Txs: types.Transactions{newOrderingTestTx(keys[0], 0, testUserAddress, 2)}, BlockNumber: 1}
+		profit = &Bundle{Txs: types.Transactions{newOrderingTestTx(keys[1], 0, testUserAddress, 5)}, BlockNumber: 1}
+	)
+	for _, bundle := range []*Bundle{cheap, profit} {
+		if err := w.addBundle(bundle); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	block := sealOrderingTestBlock(t, w, b)
+
+	want := []common.Hash{profit.Txs[0].Hash(), cheap.Txs[0].Hash()}
+	if len(block.Transactions()) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), len(want))
+	}
+	for i, tx := range block.Transactions() {
+		if tx.Hash() != want[i] {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), want[i])
+		}
+	}
+}
@@ -179,6 +179,8 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			limiter:                api.node.rpcLimiter,
+			cache:                  api.node.rpcCache,
 		},
 	}
 	if cors != nil {
@@ -257,6 +259,8 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			limiter:                api.node.rpcLimiter,
+			cache:                  api.node.rpcCache,
 		},
 	}
 	if apis != nil {
@@ -206,6 +206,10 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCLimits are the API key authentication and the rate limits of the
+	// HTTP and WebSocket RPC endpoints.
+	RPCLimits RPCLimitConfig
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
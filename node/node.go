@@ -66,6 +66,9 @@ type Node struct {
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
+	rpcLimiter *rpcLimiter       // API key and rate limits of the public HTTP and WS endpoints
+	rpcCache   rpc.ResponseCache // Cache of the call results of the public HTTP and WS endpoints
+
 	databases map[*closeTrackingDB]struct{} // All open databases
 
 	inprocOpenRPC   *go_openrpc_reflect.Document
@@ -157,6 +160,9 @@ func New(conf *Config) (*Node, error) {
 	}
 
 	// Configure RPC servers.
+	if node.rpcLimiter, err = newRPCLimiter(conf.RPCLimits); err != nil {
+		return nil, err
+	}
 	node.http = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
@@ -489,6 +495,8 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		limiter:                n.rpcLimiter,
+		cache:                  n.rpcCache,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -673,6 +681,18 @@ func (n *Node) RegisterAPIs(apis []rpc.API) {
 	n.rpcAPIs = append(n.rpcAPIs, apis...)
 }
 
+// SetResponseCache sets the cache of the call results served over the public HTTP
+// and WebSocket endpoints.
+func (n *Node) SetResponseCache(cache rpc.ResponseCache) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.state != initializingState {
+		panic("can't set response cache on running/stopped node")
+	}
+	n.rpcCache = cache
+}
+
 // getAPIs return two sets of APIs, both the ones that do not require
 // authentication, and the complete set
 func (n *Node) getAPIs() (unauthenticated, all []rpc.API) {
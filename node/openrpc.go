@@ -19,10 +19,13 @@ import (
 	go_openrpc_reflect "github.com/etclabscore/go-openrpc-reflect"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/internal/debug"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	meta_schema "github.com/open-rpc/meta-schema"
@@ -34,8 +37,55 @@ type RPCDiscoveryService struct {
 }
 
 // Discover exposes a Discover method to the RPC receiver registration.
-func (r *RPCDiscoveryService) Discover() (*meta_schema.OpenrpcDocument, error) {
-	return r.d.Discover()
+// The document only describes the methods of the given namespaces, if any.
+func (r *RPCDiscoveryService) Discover(namespaces *[]string) (*meta_schema.OpenrpcDocument, error) {
+	doc, err := r.d.Discover()
+	if err != nil || namespaces == nil {
+		return doc, err
+	}
+	return filterOpenRPCNamespaces(doc, *namespaces), nil
+}
+
+// OpenRPCDocument returns the OpenRPC document of all the APIs registered on the
+// node, restricted to the given namespaces if any. The node doesn't need to be
+// started, so the document has no servers.
+func (n *Node) OpenRPCDocument(namespaces []string) (*meta_schema.OpenrpcDocument, error) {
+	n.lock.Lock()
+	apis := n.rpcAPIs
+	n.lock.Unlock()
+
+	d := newOpenRPCDocument()
+	registerOpenRPCAPIs(d, apis)
+	d.WithMeta(metaRegistererForURL(""))
+	doc, err := d.Discover()
+	if err != nil {
+		return nil, err
+	}
+	return filterOpenRPCNamespaces(doc, namespaces), nil
+}
+
+// filterOpenRPCNamespaces removes the methods of the namespaces not listed from
+// the document. An empty list keeps all of them.
+func filterOpenRPCNamespaces(doc *meta_schema.OpenrpcDocument, namespaces []string) *meta_schema.OpenrpcDocument {
+	if len(namespaces) == 0 || doc.Methods == nil {
+		return doc
+	}
+	allowed := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		allowed[namespace] = true
+	}
+	methods := meta_schema.Methods{}
+	for _, method := range *doc.Methods {
+		if method.Name == nil {
+			continue
+		}
+		namespace, _, _ := strings.Cut(string(*method.Name), "_")
+		if allowed[namespace] {
+			methods = append(methods, method)
+		}
+	}
+	doc.Methods = &methods
+	return doc
 }
 
 // sharedMetaRegisterer defines common metadata to all possible servers.
@@ -135,15 +185,11 @@ func newOpenRPCDocument() *go_openrpc_reflect.Document {
 			return false
 		}
 
-		// Exclude methods that handle subscriptions, but do so without adhering to the conventional code pattern.
-		// Eg. *filters.PublicFiltersAPI.SubscribeNewHeads handles eth_subscribe("newHeads"), but there
-		// isn't a method called `eth_subscribeNewHeads`. So we blacklist all these methods and use
-		// the mock subscription receiver type RPCSubscription.
-		// This pattern matches all strings that start with Subscribe and are suffixed with a non-zero
-		// number of A-z characters.
-		pkgStr := method.Type.In(0).String()
-		if isPubSub(method.Type) && pkgStr == "*eth.PublicEthereumAPI" {
-			// This catches MOST of them (except SubscribeSyncStatus)
+		// Exclude methods that handle subscriptions, they aren't callable by their own name.
+		// Eg. *filters.FilterAPI.NewHeads handles eth_subscribe("newHeads"), but there
+		// isn't a method called `eth_newHeads`. So we blacklist all these methods and use
+		// the mock subscription receiver types, like RPCEthSubscription, to describe them.
+		if isPubSub(method.Type) {
 			return false
 		}
 		// Reject all methods that use a channel in the their argument params.
@@ -189,6 +235,18 @@ func newOpenRPCDocument() *go_openrpc_reflect.Document {
 		return go_openrpc_reflect.EthereumReflector.GetContentDescriptorRequired(r, m, field)
 	}
 
+	appReflector.FnGetMethodParamStructure = func(r reflect.Value, m reflect.Method, funcDecl *ast.FuncDecl) (string, error) {
+		// Subscription notifications carry their parameters by name.
+		if m.Name == "Subscription" && isSubscriptionMock(r) {
+			return "by-name", nil
+		}
+		return "by-position", nil
+	}
+
+	appReflector.FnGetMethodErrors = func(r reflect.Value, m reflect.Method, funcDecl *ast.FuncDecl) (*meta_schema.MethodObjectErrors, error) {
+		return openRPCMethodErrors(m)
+	}
+
 	appReflector.FnGetMethodExternalDocs = func(r reflect.Value, m reflect.Method, funcDecl *ast.FuncDecl) (*meta_schema.ExternalDocumentationObject, error) {
 		standard := go_openrpc_reflect.StandardReflector
 		got, err := standard.GetMethodExternalDocs(r, m, funcDecl)
@@ -215,6 +273,8 @@ are documentation-only mocks to represent the otherwise invisible (un-reflected)
 method.
 It is appended to the OpenRPC document when the eth/api/filters.PublicFilterAPI receiver
 is registered, similar logic applies to other modules.
+The Subscription methods describe the notifications sent by the server for the
+events of a subscription, they are not callable.
 */
 type RPCEthSubscription struct{}
 
@@ -226,13 +286,25 @@ func (sub *RPCEthSubscription) Unsubscribe(id rpc.ID) error {
 
 type RPCEthSubscriptionParamsName string
 
+// RPCEthSubscriptionOptions are the options of the eth subscriptions which take some.
+type RPCEthSubscriptionOptions struct{}
+
 // Subscribe creates a subscription to an event channel.
 // Subscriptions are not available over HTTP; they are only available over WS, IPC, and Process connections.
-func (sub *RPCEthSubscription) Subscribe(subscriptionName RPCEthSubscriptionParamsName, subscriptionOptions interface{}) (subscriptionID rpc.ID, err error) {
+func (sub *RPCEthSubscription) Subscribe(subscriptionName RPCEthSubscriptionParamsName, subscriptionOptions RPCEthSubscriptionOptions) (subscriptionID rpc.ID, err error) {
 	// This is a mock function, not the real one.
 	return
 }
 
+// RPCEthSubscriptionResult is the payload of the notifications of the eth subscriptions.
+type RPCEthSubscriptionResult struct{}
+
+// Subscription is the notification sent by the server for each event of a subscription.
+func (sub *RPCEthSubscription) Subscription(subscription rpc.ID, result RPCEthSubscriptionResult) error {
+	// This is a mock function, not the real one.
+	return nil
+}
+
 type RPCDebugSubscription struct{}
 
 // Unsubscribe terminates an existing subscription by ID.
@@ -250,6 +322,15 @@ func (sub *RPCDebugSubscription) Subscribe(subscriptionName RPCDebugSubscription
 	return
 }
 
+// RPCDebugSubscriptionResult is the payload of the notifications of the debug subscriptions.
+type RPCDebugSubscriptionResult struct{}
+
+// Subscription is the notification sent by the server for each event of a subscription.
+func (sub *RPCDebugSubscription) Subscription(subscription rpc.ID, result RPCDebugSubscriptionResult) error {
+	// This is a mock function, not the real one.
+	return nil
+}
+
 type RPCTraceSubscription struct{}
 
 // Unsubscribe terminates an existing subscription by ID.
@@ -267,6 +348,252 @@ func (sub *RPCTraceSubscription) Subscribe(subscriptionName RPCTraceSubscription
 	return
 }
 
+// RPCTraceSubscriptionResult is the payload of the notifications of the trace subscriptions.
+type RPCTraceSubscriptionResult struct{}
+
+// Subscription is the notification sent by the server for each event of a subscription.
+func (sub *RPCTraceSubscription) Subscription(subscription rpc.ID, result RPCTraceSubscriptionResult) error {
+	// This is a mock function, not the real one.
+	return nil
+}
+
+type RPCAdminSubscription struct{}
+
+// Unsubscribe terminates an existing subscription by ID.
+func (sub *RPCAdminSubscription) Unsubscribe(id rpc.ID) error {
+	// This is a mock function, not the real one.
+	return nil
+}
+
+type RPCAdminSubscriptionParamsName string
+
+// Subscribe creates a subscription to an event channel.
+// Subscriptions are not available over HTTP; they are only available over WS, IPC, and Process connections.
+func (sub *RPCAdminSubscription) Subscribe(subscriptionName RPCAdminSubscriptionParamsName) (subscriptionID rpc.ID, err error) {
+	// This is a mock function, not the real one.
+	return
+}
+
+// RPCAdminSubscriptionResult is the payload of the notifications of the admin subscriptions.
+type RPCAdminSubscriptionResult struct{}
+
+// Subscription is the notification sent by the server for each event of a subscription.
+func (sub *RPCAdminSubscription) Subscription(subscription rpc.ID, result RPCAdminSubscriptionResult) error {
+	// This is a mock function, not the real one.
+	return nil
+}
+
+// isSubscriptionMock reports whether the receiver is one of the subscription mocks.
+func isSubscriptionMock(r reflect.Value) bool {
+	switch r.Interface().(type) {
+	case *RPCEthSubscription, *RPCDebugSubscription, *RPCTraceSubscription, *RPCAdminSubscription:
+		return true
+	}
+	return false
+}
+
+// subscriptionTopic describes a subscription topic, the options it takes and the
+// payload of its notifications. Options and results are given either as values
+// of the type to reflect, or as raw JSON schemas.
+type subscriptionTopic struct {
+	name        string
+	description string
+	options     interface{} // Nil if the topic takes no options
+	result      interface{}
+}
+
+// rawSchema is a JSON schema used as is instead of being reflected.
+type rawSchema string
+
+// oneOfSchemas are the alternative schemas of a value, each given as a value of
+// the type to reflect or as a raw schema.
+type oneOfSchemas []interface{}
+
+// subscriptionLog is the notification result of the logs subscription, which
+// carries the cursor to resume the subscription from.
+type subscriptionLog struct {
+	types.Log
+	Cursor filters.LogCursor `json:"cursor"`
+}
+
+var ethSubscriptionTopics = []subscriptionTopic{
+	{
+		name:        "newHeads",
+		description: "Fires a notification each time a new header is appended to the chain, including chain reorganizations.",
+		result:      types.Header{},
+	},
+	{
+		name:        "newSideHeads",
+		description: "Fires a notification each time a new header is appended to the non-canonical (side) chain, including chain reorganizations.",
+		result:      types.Header{},
+	},
+	{
+		name:        "logs",
+		description: "Returns logs that are included in new imported blocks and match the given filter criteria. A cursor resumes the logs from a previous subscription. If the node can't deliver the logs in order anymore, a last notification carries the error and the cursor to resume from.",
+		options:     rawSchema(logsCriteriaD),
+		result:      oneOfSchemas{subscriptionLog{}, filters.LogsEnd{}},
+	},
+	{
+		name:        "newPendingTransactions",
+		description: "Returns the hash for all transactions that are added to the pending state, or the full transactions if requested.",
+		options:     rawSchema(`{"title": "fullTx", "type": "boolean"}`),
+		result:      rawSchema(fmt.Sprintf(`{"oneOf": [%s, {"title": "transaction", "type": "object"}]}`, commonHashD)),
+	},
+	{
+		name:        "txLifecycle",
+		description: "Fires a notification each time a transaction matching the given criteria enters, moves within or leaves the transaction pool.",
+		options:     filters.TxLifecycleCriteria{},
+		result:      txpool.LifecycleEvent{},
+	},
+	{
+		name:        "syncing",
+		description: "Indicates when the node starts or stops synchronizing. The result can either be a boolean indicating that the synchronization has started (true), finished (false) or an object with various progress indicators.",
+		result:      rawSchema(`{"oneOf": [{"type": "boolean"}, {"type": "object", "properties": {"syncing": {"type": "boolean"}, "status": {"type": "object"}}}]}`),
+	},
+}
+
+var debugSubscriptionTopics = []subscriptionTopic{
+	{
+		name:        "traceChain",
+		description: "Returns transaction traces within a range of blocks.",
+		result: rawSchema(fmt.Sprintf(`{
+			"type": "object",
+			"properties": {
+				"block": %s,
+				"hash": %s,
+				"traces": {"type": "array", "items": {"type": "object"}}
+			}
+		}`, hexutilUint64D, commonHashD)),
+	},
+}
+
+var traceSubscriptionTopics = []subscriptionTopic{
+	{
+		name:        "filter",
+		description: "Returns transaction traces for the filtered addresses within a range of blocks.",
+		result:      rawSchema(`{"title": "trace", "type": "object"}`),
+	},
+}
+
+var adminSubscriptionTopics = []subscriptionTopic{
+	{
+		name:        "peerEvents",
+		description: "Fires a notification each time a peer is added or dropped, or sends or receives a message.",
+		result:      p2p.PeerEvent{},
+	},
+}
+
+// subscriptionNameSchema returns the schema of the subscription name parameter
+// of the given topics.
+func subscriptionNameSchema(topics []subscriptionTopic) *jsonschema.Type {
+	schema := &jsonschema.Type{Title: "subscriptionName"}
+	for _, topic := range topics {
+		schema.OneOf = append(schema.OneOf, &jsonschema.Type{
+			Type:        "string",
+			Enum:        []interface{}{topic.name},
+			Description: topic.description,
+		})
+	}
+	return schema
+}
+
+// subscriptionOptionsSchema returns the schema of the options parameter of the
+// given topics, titled by topic.
+func subscriptionOptionsSchema(topics []subscriptionTopic) *jsonschema.Type {
+	schema := &jsonschema.Type{Title: "subscriptionOptions"}
+	for _, topic := range topics {
+		if topic.options != nil {
+			option := openRPCSchema(topic.options)
+			option.Title = topic.name
+			schema.OneOf = append(schema.OneOf, option)
+		}
+	}
+	return schema
+}
+
+// subscriptionResultSchema returns the schema of the notification payloads of
+// the given topics, titled by topic.
+func subscriptionResultSchema(topics []subscriptionTopic) *jsonschema.Type {
+	schema := &jsonschema.Type{Title: "subscriptionResult"}
+	for _, topic := range topics {
+		result := openRPCSchema(topic.result)
+		result.Title = topic.name
+		schema.OneOf = append(schema.OneOf, result)
+	}
+	return schema
+}
+
+// openRPCSchema returns the schema of a raw schema or of alternative schemas, or
+// reflects the schema of the type of the given value.
+func openRPCSchema(v interface{}) *jsonschema.Type {
+	if alternatives, ok := v.(oneOfSchemas); ok {
+		schema := new(jsonschema.Type)
+		for _, alternative := range alternatives {
+			schema.OneOf = append(schema.OneOf, openRPCSchema(alternative))
+		}
+		return schema
+	}
+	if raw, ok := v.(rawSchema); ok {
+		var schema jsonschema.Type
+		if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+			panic(err)
+		}
+		return &schema
+	}
+	reflector := &jsonschema.Reflector{
+		RequiredFromJSONSchemaTags: true,
+		DoNotReference:             true,
+		TypeMapper:                 OpenRPCJSONSchemaTypeMapper,
+	}
+	return reflector.Reflect(v).Type
+}
+
+// openRPCError is an error object returned by the RPC methods.
+type openRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"` // Schema of the error data, if any
+}
+
+var (
+	// commonOpenRPCErrors are the errors any method may return.
+	commonOpenRPCErrors = []openRPCError{
+		{Code: -32602, Message: "invalid argument"},
+		{Code: -32000, Message: "server error"},
+		{Code: -32002, Message: "request timed out"},
+		{Code: -32003, Message: "response too large"},
+		{Code: -32005, Message: "rate limit exceeded"},
+		{Code: -32603, Message: "method handler crashed"},
+	}
+
+	// revertOpenRPCError is returned by the calls reverted by the EVM, with the
+	// revert data.
+	revertOpenRPCError = openRPCError{Code: 3, Message: "execution reverted", Data: json.RawMessage(bytesD)}
+
+	// methodOpenRPCErrors are the additional errors of methods, keyed by receiver
+	// type and method name.
+	methodOpenRPCErrors = map[string][]openRPCError{
+		"*ethapi.BlockChainAPI.Call":        {revertOpenRPCError},
+		"*ethapi.BlockChainAPI.EstimateGas": {revertOpenRPCError},
+	}
+)
+
+// openRPCMethodErrors returns the error objects the method may return.
+func openRPCMethodErrors(m reflect.Method) (*meta_schema.MethodObjectErrors, error) {
+	errs := append([]openRPCError{}, methodOpenRPCErrors[m.Type.In(0).String()+"."+m.Name]...)
+	errs = append(errs, commonOpenRPCErrors...)
+
+	enc, err := json.Marshal(errs)
+	if err != nil {
+		return nil, err
+	}
+	var objects meta_schema.MethodObjectErrors
+	if err := json.Unmarshal(enc, &objects); err != nil {
+		return nil, err
+	}
+	return &objects, nil
+}
+
 // registerOpenRPCAPIs provides a convenience logic that is reused
 // congruent to the rpc package receiver registrations.
 func registerOpenRPCAPIs(doc *go_openrpc_reflect.Document, apis []rpc.API) {
@@ -282,6 +609,8 @@ func registerOpenRPCAPIs(doc *go_openrpc_reflect.Document, apis []rpc.API) {
 			doc.RegisterReceiverName("debug", &RPCDebugSubscription{})
 		case *tracers.TraceAPI:
 			doc.RegisterReceiverName("trace", &RPCTraceSubscription{})
+		case *adminAPI:
+			doc.RegisterReceiverName("admin", &RPCAdminSubscription{})
 		}
 	}
 }
@@ -369,30 +698,22 @@ var blockNumberOrHashD = fmt.Sprintf(`{
           ]
         }`, blockNumberD, commonHashD, requireCanonicalD)
 
-var rpcEthSubscriptionParamsNameD = `{
-		"title": "subscriptionName",
-		"oneOf": [
-			{"type": "string", "enum": ["newHeads"], "description": "Fires a notification each time a new header is appended to the chain, including chain reorganizations."},
-			{"type": "string", "enum": ["newSideHeads"], "description": "Fires a notification each time a new header is appended to the non-canonical (side) chain, including chain reorganizations."},
-			{"type": "string", "enum": ["logs"], "description": "Returns logs that are included in new imported blocks and match the given filter criteria."},
-			{"type": "string", "enum": ["newPendingTransactions"], "description": "Returns the hash for all transactions that are added to the pending state and are signed with a key that is available in the node."},
-			{"type": "string", "enum": ["syncing"], "description": "Indicates when the node starts or stops synchronizing. The result can either be a boolean indicating that the synchronization has started (true), finished (false) or an object with various progress indicators."}
-		]
-	}`
-
-var rpcDebugSubscriptionParamsNameD = `{
-		"title": "subscriptionName",
-		"oneOf": [
-			{"type": "string", "enum": ["traceChain"], "description": "Returns transaction traces within a range of blocks."}
-		]
-	}`
+const enodeIDD = `{
+		"title": "enodeID",
+		"type": "string",
+		"description": "Hex representation of a node identifier",
+		"pattern": "^[a-fA-F\\d]{64}$"
+		}`
 
-var rpcTraceSubscriptionParamsNameD = `{
-		"title": "subscriptionName",
-		"oneOf": [
-			{"type": "string", "enum": ["filter"], "description": "Returns transaction traces for the filtered addresses within a range of blocks."}
-		]
-	}`
+var logsCriteriaD = fmt.Sprintf(`{
+		"title": "logsCriteria",
+		"type": "object",
+		"properties": {
+			"address": {"oneOf": [%s, {"type": "array", "items": %s}]},
+			"topics": {"type": "array", "items": {"oneOf": [{"type": "null"}, %s, {"type": "array", "items": %s}]}},
+			"cursor": %s
+		}
+		}`, commonAddressD, commonAddressD, commonHashD, commonHashD, bytesD)
 
 // schemaDictEntry represents a type association passed to the jsonschema reflector.
 type schemaDictEntry struct {
@@ -421,6 +742,28 @@ func OpenRPCJSONSchemaTypeMapper(ty reflect.Type) *jsonschema.Type {
 		return &jsonschema.Type{AdditionalProperties: []byte("true")}
 	}
 
+	// Then the subscription mocks, whose schemas are built from their topics.
+	switch ty {
+	case reflect.TypeOf(RPCEthSubscriptionParamsName("")):
+		return subscriptionNameSchema(ethSubscriptionTopics)
+	case reflect.TypeOf(RPCEthSubscriptionOptions{}):
+		return subscriptionOptionsSchema(ethSubscriptionTopics)
+	case reflect.TypeOf(RPCEthSubscriptionResult{}):
+		return subscriptionResultSchema(ethSubscriptionTopics)
+	case reflect.TypeOf(RPCDebugSubscriptionParamsName("")):
+		return subscriptionNameSchema(debugSubscriptionTopics)
+	case reflect.TypeOf(RPCDebugSubscriptionResult{}):
+		return subscriptionResultSchema(debugSubscriptionTopics)
+	case reflect.TypeOf(RPCTraceSubscriptionParamsName("")):
+		return subscriptionNameSchema(traceSubscriptionTopics)
+	case reflect.TypeOf(RPCTraceSubscriptionResult{}):
+		return subscriptionResultSchema(traceSubscriptionTopics)
+	case reflect.TypeOf(RPCAdminSubscriptionParamsName("")):
+		return subscriptionNameSchema(adminSubscriptionTopics)
+	case reflect.TypeOf(RPCAdminSubscriptionResult{}):
+		return subscriptionResultSchema(adminSubscriptionTopics)
+	}
+
 	// Second, handle other types.
 	// Use a slice instead of a map because it preserves order, as a logic safeguard/fallback.
 	dict := []schemaDictEntry{
@@ -437,9 +780,9 @@ func OpenRPCJSONSchemaTypeMapper(ty reflect.Type) *jsonschema.Type {
 		{rpc.BlockNumberOrHash{}, blockNumberOrHashD},
 		{rpc.Subscription{}, rpcSubscriptionIDD},
 		{rpc.ID(""), rpcSubscriptionIDD},
-		{RPCEthSubscriptionParamsName(""), rpcEthSubscriptionParamsNameD},
-		{RPCDebugSubscriptionParamsName(""), rpcDebugSubscriptionParamsNameD},
-		{RPCTraceSubscriptionParamsName(""), rpcTraceSubscriptionParamsNameD},
+		{types.Bloom{}, bytesD},
+		{filters.LogCursor{}, bytesD},
+		{enode.ID{}, enodeIDD},
 	}
 
 	for _, d := range dict {
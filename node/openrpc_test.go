@@ -1,8 +1,11 @@
 package node
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
+
+	meta_schema "github.com/open-rpc/meta-schema"
 )
 
 type TestReceiver struct{}
@@ -57,3 +60,104 @@ func TestEligibleReturnSignature(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the subscription mocks are described by the schemas of their topics.
+func TestSubscriptionSchemas(t *testing.T) {
+	names := OpenRPCJSONSchemaTypeMapper(reflect.TypeOf(RPCEthSubscriptionParamsName("")))
+	if len(names.OneOf) != len(ethSubscriptionTopics) {
+		t.Fatalf("name count mismatch: have %d, want %d", len(names.OneOf), len(ethSubscriptionTopics))
+	}
+	results := OpenRPCJSONSchemaTypeMapper(reflect.TypeOf(RPCEthSubscriptionResult{}))
+	if len(results.OneOf) != len(ethSubscriptionTopics) {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results.OneOf), len(ethSubscriptionTopics))
+	}
+	for i, topic := range ethSubscriptionTopics {
+		if enum := names.OneOf[i].Enum; len(enum) != 1 || enum[0] != topic.name {
+			t.Errorf("topic %s: name mismatch: have %v", topic.name, enum)
+		}
+		if title := results.OneOf[i].Title; title != topic.name {
+			t.Errorf("topic %s: result title mismatch: have %q", topic.name, title)
+		}
+	}
+	// Only the topics taking options are listed
+	options := OpenRPCJSONSchemaTypeMapper(reflect.TypeOf(RPCEthSubscriptionOptions{}))
+	var titles []string
+	for _, option := range options.OneOf {
+		titles = append(titles, option.Title)
+	}
+	if want := []string{"logs", "newPendingTransactions", "txLifecycle"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("options mismatch: have %v, want %v", titles, want)
+	}
+	// The logs notifications and the last one ending the subscription carry
+	// their cursor
+	for _, result := range results.OneOf {
+		if result.Title != "logs" {
+			continue
+		}
+		if len(result.OneOf) != 2 {
+			t.Fatalf("logs result alternatives mismatch: have %d, want 2", len(result.OneOf))
+		}
+		for i, alternative := range result.OneOf {
+			if alternative.Properties == nil {
+				t.Fatalf("logs result %d has no properties", i)
+			}
+			if _, ok := alternative.Properties.Get("cursor"); !ok {
+				t.Errorf("logs result %d misses the cursor", i)
+			}
+		}
+		if _, ok := result.OneOf[1].Properties.Get("error"); !ok {
+			t.Error("logs end result misses the error")
+		}
+	}
+}
+
+// Tests that the methods list the common errors, and their own ones first.
+func TestOpenRPCMethodErrors(t *testing.T) {
+	method, _ := reflect.TypeOf(&TestReceiver{}).MethodByName("ReturnSigA")
+	codes := func() []int {
+		objects, err := openRPCMethodErrors(method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, _ := json.Marshal(objects)
+		var errs []openRPCError
+		if err := json.Unmarshal(enc, &errs); err != nil {
+			t.Fatal(err)
+		}
+		var codes []int
+		for _, err := range errs {
+			codes = append(codes, err.Code)
+		}
+		return codes
+	}
+	if have := codes(); len(have) != len(commonOpenRPCErrors) {
+		t.Fatalf("error count mismatch: have %v, want %d", have, len(commonOpenRPCErrors))
+	}
+	methodOpenRPCErrors["*node.TestReceiver.ReturnSigA"] = []openRPCError{revertOpenRPCError}
+	defer delete(methodOpenRPCErrors, "*node.TestReceiver.ReturnSigA")
+
+	if have := codes(); len(have) != len(commonOpenRPCErrors)+1 || have[0] != revertOpenRPCError.Code {
+		t.Fatalf("errors mismatch: have %v, want revert first", have)
+	}
+}
+
+func TestFilterOpenRPCNamespaces(t *testing.T) {
+	var doc meta_schema.OpenrpcDocument
+	input := `{"methods": [{"name": "eth_blockNumber"}, {"name": "admin_peers"}, {"name": "debug_traceBlock"}, {"name": "eth_subscribe"}]}`
+	if err := json.Unmarshal([]byte(input), &doc); err != nil {
+		t.Fatal(err)
+	}
+	filterOpenRPCNamespaces(&doc, nil)
+	if len(*doc.Methods) != 4 {
+		t.Fatalf("unfiltered method count mismatch: have %d, want 4", len(*doc.Methods))
+	}
+	filterOpenRPCNamespaces(&doc, []string{"eth", "debug"})
+
+	var names []string
+	for _, method := range *doc.Methods {
+		names = append(names, string(*method.Name))
+	}
+	if want := []string{"eth_blockNumber", "debug_traceBlock", "eth_subscribe"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("methods mismatch: have %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultAPIKeyHeader is the HTTP header carrying the API key of RPC requests.
+	DefaultAPIKeyHeader = "X-API-Key"
+
+	// rpcLimitBuckets is the maximum number of token buckets tracked at once.
+	// The buckets of the least recently seen clients are dropped beyond it.
+	rpcLimitBuckets = 65536
+
+	// errcodeLimitExceeded is the JSON-RPC error code of calls rejected by the
+	// limits, as defined by EIP-1474.
+	errcodeLimitExceeded = -32005
+)
+
+var (
+	rpcRateLimitedMeter  = metrics.NewRegisteredMeter("rpc/limit/rate", nil)
+	rpcConcurrencyMeter  = metrics.NewRegisteredMeter("rpc/limit/concurrency", nil)
+	rpcUnauthorizedMeter = metrics.NewRegisteredMeter("rpc/limit/unauthorized", nil)
+)
+
+// RPCLimitConfig configures the API key authentication and the rate limits of
+// the public HTTP and WebSocket RPC endpoints, and of the RPC transports served
+// by services wrapping their requests with Node.RPCLimitHandler. Clients are identified by their
+// API key if they send one, by their IP address otherwise.
+type RPCLimitConfig struct {
+	// APIKeys are the static API keys accepted in the key header, or as the
+	// last element of the URL path below the endpoint prefix.
+	APIKeys []string `toml:",omitempty"`
+
+	// RequireKey rejects the requests without a valid API key.
+	RequireKey bool `toml:",omitempty"`
+
+	// KeyHeader is the HTTP header carrying the API key, X-API-Key if empty.
+	KeyHeader string `toml:",omitempty"`
+
+	Rate     float64 `toml:",omitempty"` // Calls per second of each client IP, 0 for no limit
+	Burst    int     `toml:",omitempty"` // Maximum burst of calls of each client IP
+	KeyRate  float64 `toml:",omitempty"` // Calls per second of each API key, 0 for no limit
+	KeyBurst int     `toml:",omitempty"` // Maximum burst of calls of each API key
+
+	// Methods are additional limits of namespaces ("debug") or methods
+	// ("eth_getLogs"). Method limits take precedence over namespace ones.
+	Methods map[string]RPCMethodLimit `toml:",omitempty"`
+}
+
+// RPCMethodLimit is the limit of the calls to a namespace or a method.
+type RPCMethodLimit struct {
+	Rate        float64 `toml:",omitempty"` // Calls per second of each client, 0 for no limit
+	Burst       int     `toml:",omitempty"` // Maximum burst of calls of each client
+	Concurrency int     `toml:",omitempty"` // Maximum number of calls served at once, 0 for no limit
+}
+
+// enabled returns whether the configuration sets any key or limit.
+func (c *RPCLimitConfig) enabled() bool {
+	return len(c.APIKeys) > 0 || c.RequireKey || c.Rate > 0 || c.KeyRate > 0 || len(c.Methods) > 0
+}
+
+// limitError is returned for calls rejected by the RPC limits.
+type limitError struct{ message string }
+
+func (e *limitError) Error() string  { return e.message }
+func (e *limitError) ErrorCode() int { return errcodeLimitExceeded }
+
+// apiKeyContextKey is the context key of the API key of a request.
+type apiKeyContextKey struct{}
+
+// remoteAddrContextKey is the context key of the remote address of a request,
+// identifying the clients of transports not setting the RPC peer info.
+type remoteAddrContextKey struct{}
+
+// limitBucket identifies the token bucket of a client for a limit, the empty
+// rule being the overall limit of the client.
+type limitBucket struct {
+	client string
+	rule   string
+}
+
+// rpcLimiter enforces the RPC limits. It is shared by the HTTP and WebSocket
+// servers of a node and the services' transports, so a client's quota covers
+// all of them.
+type rpcLimiter struct {
+	config RPCLimitConfig
+	keys   map[string]struct{}
+	slots  map[string]chan struct{} // Concurrency semaphores of the rules capping it
+
+	lock    sync.Mutex
+	buckets lru.BasicLRU[limitBucket, *rate.Limiter]
+}
+
+// newRPCLimiter creates the limiter of the given configuration, or returns nil
+// if the configuration doesn't set any key or limit.
+func newRPCLimiter(config RPCLimitConfig) (*rpcLimiter, error) {
+	if !config.enabled() {
+		return nil, nil
+	}
+	if config.RequireKey && len(config.APIKeys) == 0 {
+		return nil, fmt.Errorf("RPC API keys required but none configured")
+	}
+	if config.Rate < 0 || config.KeyRate < 0 {
+		return nil, fmt.Errorf("negative RPC rate limit")
+	}
+	if config.KeyHeader == "" {
+		config.KeyHeader = DefaultAPIKeyHeader
+	}
+	l := &rpcLimiter{
+		config:  config,
+		keys:    make(map[string]struct{}),
+		slots:   make(map[string]chan struct{}),
+		buckets: lru.NewBasicLRU[limitBucket, *rate.Limiter](rpcLimitBuckets),
+	}
+	for _, key := range config.APIKeys {
+		if key == "" || strings.ContainsAny(key, "/?#") {
+			return nil, fmt.Errorf("invalid RPC API key %q", key)
+		}
+		l.keys[key] = struct{}{}
+	}
+	for name, limit := range config.Methods {
+		if limit.Rate < 0 || limit.Concurrency < 0 {
+			return nil, fmt.Errorf("negative RPC limit of %s", name)
+		}
+		if limit.Concurrency > 0 {
+			l.slots[name] = make(chan struct{}, limit.Concurrency)
+		}
+	}
+	return l, nil
+}
+
+// validKey reports whether the key is one of the configured API keys.
+func (l *rpcLimiter) validKey(key string) bool {
+	for k := range l.keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Acquire implements rpc.CallLimiter, checking the limits of the calling client
+// and of the method.
+func (l *rpcLimiter) Acquire(ctx context.Context, method string) (func(), error) {
+	client, keyed := l.client(ctx)
+
+	limit, burst := l.config.Rate, l.config.Burst
+	if keyed {
+		limit, burst = l.config.KeyRate, l.config.KeyBurst
+	}
+	if !l.allow(limitBucket{client: client}, limit, burst) {
+		rpcRateLimitedMeter.Mark(1)
+		return nil, &limitError{"rate limit exceeded"}
+	}
+	name, rule, ok := l.rule(method)
+	if !ok {
+		return func() {}, nil
+	}
+	if !l.allow(limitBucket{client: client, rule: name}, rule.Rate, rule.Burst) {
+		rpcRateLimitedMeter.Mark(1)
+		return nil, &limitError{fmt.Sprintf("rate limit of %s exceeded", name)}
+	}
+	slots := l.slots[name]
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	default:
+		rpcConcurrencyMeter.Mark(1)
+		return nil, &limitError{fmt.Sprintf("too many concurrent calls of %s", name)}
+	}
+}
+
+// client returns the identity of the calling client, its API key if it sent
+// one or its IP address otherwise.
+func (l *rpcLimiter) client(ctx context.Context) (string, bool) {
+	if key, _ := ctx.Value(apiKeyContextKey{}).(string); key != "" {
+		return "key:" + key, true
+	}
+	addr := rpc.PeerInfoFromContext(ctx).RemoteAddr
+	if addr == "" {
+		addr, _ = ctx.Value(remoteAddrContextKey{}).(string)
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "ip:" + addr, false
+}
+
+// rule returns the limit applying to the method, looking up the method first
+// and then its namespace.
+func (l *rpcLimiter) rule(method string) (string, RPCMethodLimit, bool) {
+	if limit, ok := l.config.Methods[method]; ok {
+		return method, limit, true
+	}
+	if i := strings.IndexByte(method, '_'); i > 0 {
+		if limit, ok := l.config.Methods[method[:i]]; ok {
+			return method[:i], limit, true
+		}
+	}
+	return "", RPCMethodLimit{}, false
+}
+
+// allow takes a token from the bucket, created with the given rate and burst
+// if it isn't tracked. A zero rate doesn't limit.
+func (l *rpcLimiter) allow(id limitBucket, limit float64, burst int) bool {
+	if limit <= 0 {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	bucket, ok := l.buckets.Get(id)
+	if !ok {
+		if burst <= 0 {
+			burst = int(math.Ceil(limit))
+		}
+		bucket = rate.NewLimiter(rate.Limit(limit), burst)
+		l.buckets.Add(id, bucket)
+	}
+	return bucket.Allow()
+}
+
+// apiKeyHandler authenticates the API key of the requests to an RPC endpoint
+// and passes it down to the limiter through the request context.
+type apiKeyHandler struct {
+	limiter  *rpcLimiter
+	prefix   string
+	pathKeys bool // Whether the key may be sent as URL path element
+	next     http.Handler
+}
+
+// newAPIKeyHandler creates a http.Handler authenticating the API keys of the
+// limiter. The key may also be sent as URL path element below the prefix.
+func newAPIKeyHandler(limiter *rpcLimiter, prefix string, next http.Handler) http.Handler {
+	return &apiKeyHandler{limiter: limiter, prefix: prefix, pathKeys: true, next: next}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *apiKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get(h.limiter.config.KeyHeader)
+	if path, pathKey, ok := splitKeyPath(r.URL.Path, h.prefix); ok && h.pathKeys && len(h.limiter.keys) > 0 {
+		r = r.Clone(r.Context())
+		r.URL.Path, r.URL.RawPath = path, ""
+		if key == "" {
+			key = pathKey
+		}
+	}
+	switch {
+	case key != "" && !h.limiter.validKey(key):
+		rpcUnauthorizedMeter.Mark(1)
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+		return
+	case key == "" && h.limiter.config.RequireKey:
+		rpcUnauthorizedMeter.Mark(1)
+		http.Error(w, "missing API key", http.StatusUnauthorized)
+		return
+	}
+	ctx := context.WithValue(r.Context(), remoteAddrContextKey{}, r.RemoteAddr)
+	if key != "" {
+		ctx = context.WithValue(ctx, apiKeyContextKey{}, key)
+	}
+	h.next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RPCLimitHandler wraps the handler of an RPC transport served outside of the
+// node's HTTP servers, e.g. gRPC, in the API key authentication of the RPC
+// limits. Keys are only accepted in the key header. The handler is returned as
+// is if no limits are configured.
+func (n *Node) RPCLimitHandler(next http.Handler) http.Handler {
+	if n.rpcLimiter == nil {
+		return next
+	}
+	return &apiKeyHandler{limiter: n.rpcLimiter, next: next}
+}
+
+// RPCCallLimiter returns the limiter of the RPC limits, to be consulted by RPC
+// transports served outside of the node's HTTP servers before serving calls
+// within a request wrapped by RPCLimitHandler. It returns nil if no limits are
+// configured.
+func (n *Node) RPCCallLimiter() rpc.CallLimiter {
+	if n.rpcLimiter == nil {
+		return nil
+	}
+	return n.rpcLimiter
+}
+
+// splitKeyPath splits the API key from a URL path of the form <prefix>/<key>,
+// returning the endpoint path without it.
+func splitKeyPath(path, prefix string) (string, string, bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(path, prefix+"/") {
+		return "", "", false
+	}
+	key := path[len(prefix)+1:]
+	if key == "" || strings.Contains(key, "/") {
+		return "", "", false
+	}
+	if prefix == "" {
+		prefix = "/"
+	}
+	return prefix, key, true
+}
+
+// checkKeyPath checks whether a given request URL carries an API key below the
+// given path prefix, if the limiter accepts API keys.
+func checkKeyPath(r *http.Request, prefix string, limiter *rpcLimiter) bool {
+	if limiter == nil || len(limiter.keys) == 0 {
+		return false
+	}
+	_, _, ok := splitKeyPath(r.URL.Path, prefix)
+	return ok
+}
@@ -56,6 +56,8 @@ type rpcEndpointConfig struct {
 	jwtSecret              []byte // optional JWT secret
 	batchItemLimit         int
 	batchResponseSizeLimit int
+	limiter                *rpcLimiter       // optional API key and rate limits
+	cache                  rpc.ResponseCache // optional cache of call results
 }
 
 type rpcHandler struct {
@@ -198,7 +200,7 @@ func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// check if ws request and serve if ws enabled
 	ws := h.wsHandler.Load().(*rpcHandler)
 	if ws != nil && isWebsocket(r) {
-		if checkPath(r, h.wsConfig.prefix) {
+		if checkPath(r, h.wsConfig.prefix) || checkKeyPath(r, h.wsConfig.prefix, h.wsConfig.limiter) {
 			ws.ServeHTTP(w, r)
 		}
 		return
@@ -217,7 +219,7 @@ func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if checkPath(r, h.httpConfig.prefix) {
+		if checkPath(r, h.httpConfig.prefix) || checkKeyPath(r, h.httpConfig.prefix, h.httpConfig.limiter) {
 			rpc.ServeHTTP(w, r)
 			return
 		}
@@ -307,9 +309,17 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
+	if config.cache != nil {
+		srv.SetResponseCache(config.cache)
+	}
+	var handler http.Handler = srv
+	if config.limiter != nil {
+		srv.SetCallLimiter(config.limiter)
+		handler = newAPIKeyHandler(config.limiter, config.prefix, srv)
+	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: NewHTTPHandlerStack(handler, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
 		server:  srv,
 	})
 	return nil
@@ -339,9 +349,17 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
+	if config.cache != nil {
+		srv.SetResponseCache(config.cache)
+	}
+	handler := srv.WebsocketHandler(config.Origins)
+	if config.limiter != nil {
+		srv.SetCallLimiter(config.limiter)
+		handler = newAPIKeyHandler(config.limiter, config.prefix, handler)
+	}
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
+		Handler: NewWSHandlerStack(handler, config.jwtSecret),
 		server:  srv,
 	})
 	return nil
@@ -18,6 +18,7 @@ package node
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -596,6 +597,109 @@ func TestHTTPWriteTimeout(t *testing.T) {
 	})
 }
 
+// TestRPCLimits tests the API key authentication and the rate limits of the
+// HTTP endpoint.
+func TestRPCLimits(t *testing.T) {
+	limiter, err := newRPCLimiter(RPCLimitConfig{
+		APIKeys: []string{"secret"},
+		Rate:    1,
+		Burst:   1,
+		Methods: map[string]RPCMethodLimit{"test_greet": {Rate: 1, Burst: 2}},
+	})
+	assert.NoError(t, err)
+
+	conf := &httpConfig{rpcEndpointConfig: rpcEndpointConfig{limiter: limiter}}
+	srv := createAndStartServer(t, conf, false, &wsConfig{}, nil)
+	defer srv.stop()
+	url := "http://" + srv.listenAddr()
+
+	limited := func(resp *http.Response) bool {
+		t.Helper()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return strings.Contains(string(body), strconv.Itoa(errcodeLimitExceeded))
+	}
+	// Unknown keys are rejected
+	resp := rpcRequest(t, url, "test_greet", DefaultAPIKeyHeader, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = rpcRequest(t, url+"/wrong", "test_greet")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Anonymous clients are limited by their IP
+	assert.False(t, limited(rpcRequest(t, url, "test_greet")))
+	assert.True(t, limited(rpcRequest(t, url, "test_greet")))
+
+	// Keyed clients are only limited by the method limit, across key transports
+	assert.False(t, limited(rpcRequest(t, url, "test_greet", DefaultAPIKeyHeader, "secret")))
+	assert.False(t, limited(rpcRequest(t, url+"/secret", "test_greet")))
+	assert.True(t, limited(rpcRequest(t, url, "test_greet", DefaultAPIKeyHeader, "secret")))
+	assert.False(t, limited(rpcRequest(t, url, testMethod, DefaultAPIKeyHeader, "secret")))
+}
+
+// TestRPCConcurrencyLimit tests that the calls of a method with a concurrency
+// cap are rejected beyond it.
+func TestRPCConcurrencyLimit(t *testing.T) {
+	limiter, err := newRPCLimiter(RPCLimitConfig{
+		Methods: map[string]RPCMethodLimit{"test": {Concurrency: 1}},
+	})
+	assert.NoError(t, err)
+
+	release, err := limiter.Acquire(context.Background(), "test_sleep")
+	assert.NoError(t, err)
+	if _, err := limiter.Acquire(context.Background(), "test_greet"); err == nil {
+		t.Fatal("expected concurrency limit error")
+	}
+	if _, err := limiter.Acquire(context.Background(), testMethod); err != nil {
+		t.Fatalf("unexpected error for unlimited method: %v", err)
+	}
+	release()
+	if _, err := limiter.Acquire(context.Background(), "test_greet"); err != nil {
+		t.Fatalf("unexpected error after release: %v", err)
+	}
+}
+
+// TestRPCLimitHandler tests the limits of the RPC transports served outside of
+// the node's HTTP servers.
+func TestRPCLimitHandler(t *testing.T) {
+	limiter, err := newRPCLimiter(RPCLimitConfig{
+		APIKeys: []string{"secret"},
+		Rate:    1,
+		Burst:   1,
+	})
+	assert.NoError(t, err)
+
+	n := &Node{rpcLimiter: limiter}
+	srv := httptest.NewServer(n.RPCLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, err := n.RPCCallLimiter().Acquire(r.Context(), "test_greet")
+		if err != nil {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		release()
+	})))
+	defer srv.Close()
+
+	status := func(path string, headers ...string) int {
+		t.Helper()
+		resp := rpcRequest(t, srv.URL+path, "test_greet", headers...)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	// Unknown keys are rejected, keys aren't accepted in the path
+	assert.Equal(t, http.StatusUnauthorized, status("", DefaultAPIKeyHeader, "wrong"))
+	assert.Equal(t, http.StatusOK, status("/secret"))
+
+	// Anonymous clients are limited by their IP, keyed ones aren't
+	assert.Equal(t, http.StatusTooManyRequests, status(""))
+	assert.Equal(t, http.StatusOK, status("", DefaultAPIKeyHeader, "secret"))
+
+	// Nodes without limits have no call limiter
+	if (&Node{}).RPCCallLimiter() != nil {
+		t.Error("unexpected call limiter without limits")
+	}
+}
+
 func apis() []rpc.API {
 	return []rpc.API{
 		{
@@ -0,0 +1,164 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// ParseBootnodes parses and validates a list of bootstrap node URLs (enode:// or
+// enr:). Every entry must be a complete node with an IP address and a discovery
+// port, listed only once.
+func ParseBootnodes(urls []string) ([]*enode.Node, error) {
+	var (
+		nodes = make([]*enode.Node, 0, len(urls))
+		seen  = make(map[enode.ID]int)
+	)
+	for i, url := range urls {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			return nil, fmt.Errorf("bootnode #%d: empty URL", i)
+		}
+		n, err := enode.Parse(enode.ValidSchemes, url)
+		if err != nil {
+			return nil, fmt.Errorf("bootnode #%d (%s): %v", i, url, err)
+		}
+		if err := n.ValidateComplete(); err != nil {
+			return nil, fmt.Errorf("bootnode #%d (%s): %v", i, url, err)
+		}
+		if j, ok := seen[n.ID()]; ok {
+			return nil, fmt.Errorf("bootnode #%d (%s): duplicate of bootnode #%d", i, url, j)
+		}
+		seen[n.ID()] = i
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// BootnodeCheck is the result of probing a bootstrap node.
+type BootnodeCheck struct {
+	Node   *enode.Node   // Bootnode as configured
+	RTT    time.Duration // Round trip time of the ping
+	Record *enode.Node   // Current record of the bootnode, nil if it couldn't be retrieved
+	Err    error         // Reason the bootnode couldn't be pinged, nil if alive
+}
+
+// Bootnodes returns the bootstrap nodes of the running discovery protocols.
+func (srv *Server) Bootnodes() []*enode.Node {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	return append([]*enode.Node(nil), srv.bootnodes...)
+}
+
+// SetBootnodes replaces the bootstrap nodes of the running discovery protocols
+// and re-seeds their tables from them.
+func (srv *Server) SetBootnodes(nodes []*enode.Node) error {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if !srv.running {
+		return errServerStopped
+	}
+	if srv.ntab == nil && srv.DiscV5 == nil {
+		return errors.New("discovery is disabled")
+	}
+	if srv.ntab != nil {
+		if err := srv.ntab.SetBootnodes(nodes); err != nil {
+			return err
+		}
+	}
+	if srv.DiscV5 != nil {
+		if err := srv.DiscV5.SetBootnodes(nodes); err != nil {
+			return err
+		}
+	}
+	srv.bootnodes = append([]*enode.Node(nil), nodes...)
+	srv.log.Info("Updated bootstrap nodes", "count", len(nodes))
+	return nil
+}
+
+// CheckBootnodes pings all bootstrap nodes and requests their current records.
+func (srv *Server) CheckBootnodes() ([]*BootnodeCheck, error) {
+	srv.lock.Lock()
+	var (
+		running = srv.running
+		ntab    = srv.ntab
+		v5      = srv.DiscV5
+		nodes   = srv.bootnodes
+	)
+	srv.lock.Unlock()
+
+	if !running {
+		return nil, errServerStopped
+	}
+	if ntab == nil && v5 == nil {
+		return nil, errors.New("discovery is disabled")
+	}
+	var (
+		checks = make([]*BootnodeCheck, len(nodes))
+		wg     sync.WaitGroup
+	)
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n *enode.Node) {
+			defer wg.Done()
+
+			check := &BootnodeCheck{Node: n}
+			start := time.Now()
+			if ntab != nil {
+				check.Err = ntab.Ping(n)
+			} else {
+				check.Err = v5.Ping(n)
+			}
+			if check.Err == nil {
+				check.RTT = time.Since(start)
+				if ntab != nil {
+					check.Record, _ = ntab.RequestENR(n)
+				} else {
+					check.Record, _ = v5.RequestENR(n)
+				}
+			}
+			checks[i] = check
+		}(i, n)
+	}
+	wg.Wait()
+	return checks, nil
+}
+
+// mergeBootnodes returns the union of the discovery v4 and v5 bootstrap nodes.
+func mergeBootnodes(v4, v5 []*enode.Node) []*enode.Node {
+	var (
+		nodes = make([]*enode.Node, 0, len(v4)+len(v5))
+		seen  = make(map[enode.ID]bool)
+	)
+	for _, list := range [][]*enode.Node{v4, v5} {
+		for _, n := range list {
+			if !seen[n.ID()] {
+				seen[n.ID()] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
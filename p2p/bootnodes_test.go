@@ -0,0 +1,75 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestParseBootnodes(t *testing.T) {
+	const (
+		node1 = "enode://1d3dc091ef0570b1cb8512dbbd0f02d92907597a04bc931ed86c336b699b7b13b12035ed240c5af215e2e910454ceb03185cd12875ea76b02b60e3afd7f5874b@81.167.190.96:30303"
+		node2 = "enode://8a6059cd88ee9ae479ea457049eaf34bce3a76e715daf1c98137c6919f690222a658395a377923dec2d1c24d27389e427df9dd9e16132a21cdbe6eab496bec1f@66.29.155.55:30303"
+	)
+	tests := []struct {
+		urls []string
+		want int
+		err  string
+	}{
+		{urls: nil, want: 0},
+		{urls: []string{node1, " " + node2 + " "}, want: 2},
+		{urls: []string{node1, ""}, err: "bootnode #1: empty URL"},
+		{urls: []string{"enode://1234@1.2.3.4:30303"}, err: "bootnode #0 (enode://1234@1.2.3.4:30303): invalid public key"},
+		{urls: []string{"enode://1d3dc091ef0570b1cb8512dbbd0f02d92907597a04bc931ed86c336b699b7b13b12035ed240c5af215e2e910454ceb03185cd12875ea76b02b60e3afd7f5874b"}, err: "missing IP address"},
+		{urls: []string{node1, node2, node1}, err: "bootnode #2 (" + node1 + "): duplicate of bootnode #0"},
+	}
+	for i, test := range tests {
+		nodes, err := ParseBootnodes(test.urls)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("test %d: error mismatch: have %v, want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		} else if len(nodes) != test.want {
+			t.Errorf("test %d: node count mismatch: have %d, want %d", i, len(nodes), test.want)
+		}
+	}
+}
+
+// Tests that the built-in bootstrap node lists are all valid.
+func TestBuiltinBootnodes(t *testing.T) {
+	lists := map[string][]string{
+		"mainnet": params.MainnetBootnodes,
+		"v5":      params.V5Bootnodes,
+		"classic": params.ClassicBootnodes,
+		"vecno":   params.VecnoBootnodes,
+		"sepolia": params.SepoliaBootnodes,
+		"goerli":  params.GoerliBootnodes,
+		"holesky": params.HoleskyBootnodes,
+	}
+	for name, urls := range lists {
+		if _, err := ParseBootnodes(urls); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
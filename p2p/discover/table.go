@@ -214,7 +214,9 @@ func (tab *Table) setFallbackNodes(nodes []*enode.Node) error {
 		}
 		nursery = append(nursery, wrapNode(n))
 	}
+	tab.mutex.Lock()
 	tab.nursery = nursery
+	tab.mutex.Unlock()
 	return nil
 }
 
@@ -327,7 +329,9 @@ func (tab *Table) doRefresh(done chan struct{}) {
 
 func (tab *Table) loadSeedNodes() {
 	seeds := wrapNodes(tab.db.QuerySeeds(seedCount, seedMaxAge))
+	tab.mutex.Lock()
 	seeds = append(seeds, tab.nursery...)
+	tab.mutex.Unlock()
 	for i := range seeds {
 		seed := seeds[i]
 		age := log.Lazy{Fn: func() interface{} { return time.Since(tab.db.LastPongReceived(seed.ID(), seed.IP())) }}
@@ -206,6 +206,16 @@ func (t *UDPv4) ourEndpoint() v4wire.Endpoint {
 	return v4wire.NewEndpoint(a, uint16(n.TCP()))
 }
 
+// SetBootnodes replaces the bootstrap nodes of the table and starts a refresh,
+// seeding the table from them again.
+func (t *UDPv4) SetBootnodes(nodes []*enode.Node) error {
+	if err := t.tab.setFallbackNodes(nodes); err != nil {
+		return err
+	}
+	t.tab.refresh()
+	return nil
+}
+
 // Ping sends a ping message to the given node.
 func (t *UDPv4) Ping(n *enode.Node) error {
 	_, err := t.ping(n)
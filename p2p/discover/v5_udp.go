@@ -198,6 +198,16 @@ func (t *UDPv5) Close() {
 	})
 }
 
+// SetBootnodes replaces the bootstrap nodes of the table and starts a refresh,
+// seeding the table from them again.
+func (t *UDPv5) SetBootnodes(nodes []*enode.Node) error {
+	if err := t.tab.setFallbackNodes(nodes); err != nil {
+		return err
+	}
+	t.tab.refresh()
+	return nil
+}
+
 // Ping sends a ping message to the given node.
 func (t *UDPv5) Ping(n *enode.Node) error {
 	_, err := t.ping(n)
@@ -192,6 +192,7 @@ type Server struct {
 	localnode *enode.LocalNode
 	ntab      *discover.UDPv4
 	DiscV5    *discover.UDPv5
+	bootnodes []*enode.Node // bootstrap nodes of the running discovery protocols
 	discmix   *enode.FairMix
 	dialsched *dialScheduler
 
@@ -581,6 +582,16 @@ func (srv *Server) setupDiscovery() error {
 		}
 	}
 
+	// Track the bootstrap nodes of the running discovery protocols.
+	var v4, v5 []*enode.Node
+	if srv.ntab != nil {
+		v4 = srv.BootstrapNodes
+	}
+	if srv.DiscV5 != nil {
+		v5 = srv.BootstrapNodesV5
+	}
+	srv.bootnodes = mergeBootnodes(v4, v5)
+
 	// Add protocol-specific discovery sources.
 	added := make(map[string]bool)
 	for _, proto := range srv.Protocols {
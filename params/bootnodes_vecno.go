@@ -9,10 +9,11 @@ var VecnoBootnodes = []string{
 
 	// Communtiy Bootnodes
 	"enode://31d92d58a107fe2abb03eebe0dda2b934a0d736b3a74cf7e94bf4d62898b0f296e0354d2a797241d48b75f609d4a1161b13fc6a68eca4019a12e4bc10506bc5e@203.161.54.144:30303",
-	"",
-	"",
 }
 
-// Once Vecno network has DNS discovery set up,
-// this value can be configured.
-// var VecnoDNSNetwork = "enrtree://@example"
+// VecnoDNSNetwork is the EIP-1459 node tree of the Vecno network, used by
+// default for DNS discovery when running with --vecno. The tree is maintained
+// with `devp2p dns sign -network vecno`, which only admits nodes announcing the
+// Vecno fork ID. DNS discovery stays disabled by default until the operators
+// publish the tree and its URL is set here.
+var VecnoDNSNetwork = ""
@@ -288,6 +288,13 @@ var (
 			273_499_200: big.NewInt(0.01 * vars.Ether),
 		},
 
+		TreasurySchedule: ctypes.Uint64TreasuryMapEncodesHex{
+			0: {
+				Address:    common.HexToAddress("0x53839204723996D9487908b583D0eF92e14eEa17"),
+				Percentage: 10,
+			},
+		},
+
 		RequireBlockHashes: map[uint64]common.Hash{
 			0: common.HexToHash("0xe4886a8ee17318bf3d2145998b0ff4e4fb2628770f89fb11990f12d3a9f254a6"),
 		},
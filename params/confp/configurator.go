@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"sort"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 )
 
@@ -191,6 +192,39 @@ func IsValid(conf ctypes.ChainConfigurator, head *uint64) *ConfigValidError {
 	if conf.GetNetworkID() == nil {
 		return NewValidErr("NetworkID cannot be nil", "!=nil", conf.GetNetworkID())
 	}
+	for activation, t := range conf.GetEthashTreasurySchedule() {
+		if t == nil {
+			continue
+		}
+		if t.Percentage > 100 {
+			return NewValidErr(fmt.Sprintf("Treasury percentage cannot exceed 100 (block %d)", activation), "<=100", t.Percentage)
+		}
+		if t.Percentage > 0 && t.Address == (common.Address{}) {
+			return NewValidErr(fmt.Sprintf("Treasury address cannot be empty (block %d)", activation), "!=0x0", t.Address.Hex())
+		}
+	}
+	for activation, a := range conf.GetEthashDifficultyAlgorithmSchedule() {
+		if a == nil {
+			continue
+		}
+		switch a.Algorithm {
+		case ctypes.DifficultyAlgorithmLegacy:
+			continue
+		case ctypes.DifficultyAlgorithmLWMA:
+			if a.Window < 2 {
+				return NewValidErr(fmt.Sprintf("LWMA window too short (block %d)", activation), ">=2", a.Window)
+			}
+		case ctypes.DifficultyAlgorithmASERT:
+			if a.HalfLife == 0 {
+				return NewValidErr(fmt.Sprintf("ASERT half life cannot be zero (block %d)", activation), ">0", a.HalfLife)
+			}
+		default:
+			return NewValidErr(fmt.Sprintf("Unknown difficulty algorithm (block %d)", activation), "legacy|lwma|asert", a.Algorithm)
+		}
+		if a.TargetBlockTime == 0 {
+			return NewValidErr(fmt.Sprintf("Difficulty algorithm target block time cannot be zero (block %d)", activation), ">0", a.TargetBlockTime)
+		}
+	}
 	if head == nil {
 		return nil
 	}
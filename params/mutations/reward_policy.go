@@ -0,0 +1,123 @@
+// Copyright 2024 The multi-geth Authors
+// This file is part of the multi-geth library.
+//
+// The multi-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The multi-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the multi-geth library. If not, see <http://www.gnu.org/licenses/>.
+package mutations
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+// RewardKind classifies the recipient of a block reward.
+type RewardKind string
+
+const (
+	RewardKindBlock    RewardKind = "block"    // Reward of the block's coinbase
+	RewardKindUncle    RewardKind = "uncle"    // Reward of an included uncle's coinbase
+	RewardKindTreasury RewardKind = "treasury" // Share of the block reward paid to the treasury
+)
+
+// Reward is a single balance credit made when finalizing a block.
+type Reward struct {
+	Recipient common.Address
+	Amount    *big.Int
+	Kind      RewardKind
+}
+
+// RewardPolicy defines the rewards credited when finalizing a block.
+type RewardPolicy interface {
+	// Rewards returns the rewards of the given block, in the order of the block
+	// reward, the uncle rewards in the order of the uncles, and any others.
+	Rewards(config ctypes.ChainConfigurator, header *types.Header, uncles []*types.Header, txs []*types.Transaction) []Reward
+}
+
+// RewardPolicyConfigurator is implemented by chain configurations defining a
+// custom reward policy, overriding the one derived from the configuration's
+// reward features.
+type RewardPolicyConfigurator interface {
+	RewardPolicy() RewardPolicy
+}
+
+var (
+	// EthereumRewardPolicy is the policy of a static block reward per fork
+	// (Frontier, Byzantium, Constantinople or the configured block reward
+	// schedule) and uncle rewards decreasing with the uncle depth.
+	EthereumRewardPolicy RewardPolicy = ethereumRewardPolicy{}
+
+	// ClassicRewardPolicy is the policy of ECIP-1017, the block reward
+	// disinflating by 20% every era.
+	ClassicRewardPolicy RewardPolicy = classicRewardPolicy{}
+
+	// TreasuryRewardPolicy is the policy of chains with a treasury schedule,
+	// paying a share of the block reward to the treasury and the transaction
+	// fees to the miners (eg. Vecno).
+	TreasuryRewardPolicy RewardPolicy = treasuryRewardPolicy{}
+)
+
+// RewardPolicyOf returns the reward policy of the given chain configuration at
+// the given block. The treasury policy applies from the first activation of the
+// treasury schedule on, and the earlier blocks keep the policy of their forks.
+func RewardPolicyOf(config ctypes.ChainConfigurator, number *big.Int) RewardPolicy {
+	if c, ok := config.(RewardPolicyConfigurator); ok {
+		if policy := c.RewardPolicy(); policy != nil {
+			return policy
+		}
+	}
+	if ctypes.EthashTreasuryActivated(config, number) {
+		return TreasuryRewardPolicy
+	}
+	if config.IsEnabled(config.GetEthashECIP1017Transition, number) {
+		return ClassicRewardPolicy
+	}
+	return EthereumRewardPolicy
+}
+
+// minerRewards assembles the rewards of the block and uncle coinbases.
+func minerRewards(header *types.Header, uncles []*types.Header, minerReward *big.Int, uncleRewards []*big.Int) []Reward {
+	rewards := make([]Reward, 0, 1+len(uncles))
+	rewards = append(rewards, Reward{Recipient: header.Coinbase, Amount: minerReward, Kind: RewardKindBlock})
+	for i, uncle := range uncles {
+		rewards = append(rewards, Reward{Recipient: uncle.Coinbase, Amount: uncleRewards[i], Kind: RewardKindUncle})
+	}
+	return rewards
+}
+
+type ethereumRewardPolicy struct{}
+
+func (ethereumRewardPolicy) Rewards(config ctypes.ChainConfigurator, header *types.Header, uncles []*types.Header, txs []*types.Transaction) []Reward {
+	minerReward, uncleRewards := GetRewards(config, header, uncles)
+	return minerRewards(header, uncles, minerReward, uncleRewards)
+}
+
+type classicRewardPolicy struct{}
+
+func (classicRewardPolicy) Rewards(config ctypes.ChainConfigurator, header *types.Header, uncles []*types.Header, txs []*types.Transaction) []Reward {
+	minerReward, uncleRewards := ecip1017BlockReward(config, header, uncles)
+	return minerRewards(header, uncles, minerReward, uncleRewards)
+}
+
+type treasuryRewardPolicy struct{}
+
+func (treasuryRewardPolicy) Rewards(config ctypes.ChainConfigurator, header *types.Header, uncles []*types.Header, txs []*types.Transaction) []Reward {
+	minerReward, devReward, uncleRewards := GetRewardsVecno(config, header, uncles, txs)
+	rewards := minerRewards(header, uncles, minerReward, uncleRewards)
+	if treasury := ctypes.EthashBlockTreasury(config, header.Number); treasury != nil {
+		rewards = append(rewards, Reward{Recipient: treasury.Address, Amount: devReward, Kind: RewardKindTreasury})
+	}
+	return rewards
+}
@@ -18,7 +18,6 @@ package mutations
 import (
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
@@ -62,30 +61,12 @@ func GetRewards(config ctypes.ChainConfigurator, header *types.Header, uncles []
 }
 
 // AccumulateRewards credits the coinbase of the given block with the mining
-// reward. The coinbase of each uncle block is also rewarded.
+// reward. The coinbase of each uncle block is also rewarded, along with any
+// other recipients of the chain's reward policy.
 func AccumulateRewards(config ctypes.ChainConfigurator, state *state.StateDB, header *types.Header, uncles []*types.Header, txs []*types.Transaction) {
-	var minerReward *big.Int
-	var devReward *big.Int
-	var uncleRewards []*big.Int
-
-	// Determine which reward calculation method to use based on chain ID
-	if config.GetChainID().Uint64() == params.VecnoChainId {
-		// For Vecno chain, use specific reward calculation method
-		minerReward, devReward, uncleRewards = GetRewardsVecno(config, header, uncles, txs)
-	} else {
-		// For other chains, use default reward calculation method
-		minerReward, uncleRewards = GetRewards(config, header, uncles)
+	for _, reward := range RewardPolicyOf(config, header.Number).Rewards(config, header, uncles, txs) {
+		state.AddBalance(reward.Recipient, reward.Amount)
 	}
-
-	// Distribute uncle rewards
-	for i, uncle := range uncles {
-		state.AddBalance(uncle.Coinbase, uncleRewards[i])
-	}
-
-	// Distribute miner reward
-	state.AddBalance(header.Coinbase, minerReward)
-
-	state.AddBalance(common.HexToAddress(DevWalletAddress), devReward)
 }
 
 // As of "Era 2" (zero-index era 1), uncle miners and winners are rewarded equally for each included block.
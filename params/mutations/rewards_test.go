@@ -463,6 +463,53 @@ func TestAccumulateRewards(t *testing.T) {
 	}
 }
 
+// Tests that a treasury forked into a chain leaves the rewards of the blocks
+// before its activation unchanged.
+func TestAccumulateRewardsTreasuryFork(t *testing.T) {
+	treasury := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	forked := *params.ClassicChainConfig
+	forked.TreasurySchedule = ctypes.Uint64TreasuryMapEncodesHex{
+		12000000: {Address: treasury, Percentage: 10},
+	}
+	txs := []*types.Transaction{types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1e9), nil)}
+
+	// accumulate returns the balances of the block and uncle coinbases and of
+	// the treasury after crediting the rewards of the block.
+	accumulate := func(config ctypes.ChainConfigurator, number int64) []*big.Int {
+		db := rawdb.NewMemoryDatabase()
+		defer db.Close()
+		stateDB, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+		if err != nil {
+			t.Fatalf("could not open statedb: %v", err)
+		}
+		header := &types.Header{Number: big.NewInt(number), Coinbase: WinnerCoinbase}
+		uncles := []*types.Header{
+			{Number: big.NewInt(number - 1), Coinbase: Uncle1Coinbase},
+			{Number: big.NewInt(number - 2), Coinbase: Uncle2Coinbase},
+		}
+		AccumulateRewards(config, stateDB, header, uncles, txs)
+
+		var balances []*big.Int
+		for _, addr := range []common.Address{WinnerCoinbase, Uncle1Coinbase, Uncle2Coinbase, treasury} {
+			balances = append(balances, new(big.Int).Set(stateDB.GetBalance(addr)))
+		}
+		return balances
+	}
+	for _, number := range []int64{1, 5000000, 5000001, 10000000, 11999999} {
+		want := accumulate(params.ClassicChainConfig, number)
+		have := accumulate(&forked, number)
+		for i := range want {
+			if have[i].Cmp(want[i]) != 0 {
+				t.Errorf("block %d: balance %d mismatch, want: %v, got: %v", number, i, want[i], have[i])
+			}
+		}
+	}
+	// The treasury is credited from its activation on.
+	if have := accumulate(&forked, 12000000); have[3].Sign() == 0 {
+		t.Error("treasury not credited at its activation")
+	}
+}
+
 func TestGetBlockEra(t *testing.T) {
 	blockNum := big.NewInt(11700000)
 	eraLength := big.NewInt(5000000)
@@ -535,3 +582,156 @@ func TestGetRewardForUncle(t *testing.T) {
 		t.Error("Should return uncleReward 64000000000000000", "reward", uncleReward)
 	}
 }
+
+func TestAccumulateRewardsTreasury(t *testing.T) {
+	treasury1 := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	treasury2 := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	config := &coregeth.CoreGethChainConfig{
+		EthashB3: new(ctypes.EthashB3Config),
+		BlockRewardSchedule: ctypes.Uint64BigMapEncodesHex{
+			0: big.NewInt(1e+18),
+		},
+		TreasurySchedule: ctypes.Uint64TreasuryMapEncodesHex{
+			0:   {Address: treasury1, Percentage: 10},
+			100: {Address: treasury2, Percentage: 20},
+			200: {Address: treasury2, Percentage: 0},
+		},
+	}
+
+	cases := []struct {
+		block         int64
+		wantTreasury1 *big.Int
+		wantTreasury2 *big.Int
+	}{
+		{0, big.NewInt(1e+17), big.NewInt(0)},
+		{99, big.NewInt(1e+17), big.NewInt(0)},
+		{100, big.NewInt(0), big.NewInt(2e+17)},
+		{199, big.NewInt(0), big.NewInt(2e+17)},
+		{200, big.NewInt(0), big.NewInt(0)},
+	}
+	for _, c := range cases {
+		db := rawdb.NewMemoryDatabase()
+		stateDB, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+		if err != nil {
+			t.Fatalf("could not open statedb: %v", err)
+		}
+		header := &types.Header{
+			Number:   big.NewInt(c.block),
+			Coinbase: common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		}
+		AccumulateRewards(config, stateDB, header, nil, nil)
+
+		if got := stateDB.GetBalance(header.Coinbase); got.Cmp(big.NewInt(1e+18)) != 0 {
+			t.Errorf("block %d: miner balance mismatch, want: %v, got: %v", c.block, big.NewInt(1e+18), got)
+		}
+		if got := stateDB.GetBalance(treasury1); got.Cmp(c.wantTreasury1) != 0 {
+			t.Errorf("block %d: treasury1 balance mismatch, want: %v, got: %v", c.block, c.wantTreasury1, got)
+		}
+		if got := stateDB.GetBalance(treasury2); got.Cmp(c.wantTreasury2) != 0 {
+			t.Errorf("block %d: treasury2 balance mismatch, want: %v, got: %v", c.block, c.wantTreasury2, got)
+		}
+		db.Close()
+	}
+}
+
+// fixedRewardPolicy is a custom reward policy paying a fixed amount to a single
+// recipient.
+type fixedRewardPolicy struct {
+	recipient common.Address
+	amount    *big.Int
+}
+
+func (p fixedRewardPolicy) Rewards(config ctypes.ChainConfigurator, header *types.Header, uncles []*types.Header, txs []*types.Transaction) []Reward {
+	return []Reward{{Recipient: p.recipient, Amount: p.amount, Kind: RewardKindBlock}}
+}
+
+// customRewardConfig is a chain configuration overriding its reward policy.
+type customRewardConfig struct {
+	*coregeth.CoreGethChainConfig
+	policy RewardPolicy
+}
+
+func (c *customRewardConfig) RewardPolicy() RewardPolicy { return c.policy }
+
+func TestRewardPolicyOf(t *testing.T) {
+	classic := &coregeth.CoreGethChainConfig{
+		Ethash:              new(ctypes.EthashConfig),
+		ECIP1017FBlock:      big.NewInt(100),
+		ECIP1017EraRounds:   big.NewInt(5000000),
+		BlockRewardSchedule: ctypes.Uint64BigMapEncodesHex{0: big.NewInt(5e+18)},
+	}
+	vecno := &coregeth.CoreGethChainConfig{
+		EthashB3:         new(ctypes.EthashB3Config),
+		TreasurySchedule: ctypes.Uint64TreasuryMapEncodesHex{0: {Address: common.HexToAddress("0x42"), Percentage: 10}},
+	}
+	// A treasury forked in after the ECIP-1017 transition, leaving the earlier
+	// blocks to the classic policy.
+	forked := &coregeth.CoreGethChainConfig{
+		Ethash:              new(ctypes.EthashConfig),
+		ECIP1017FBlock:      big.NewInt(100),
+		ECIP1017EraRounds:   big.NewInt(5000000),
+		BlockRewardSchedule: ctypes.Uint64BigMapEncodesHex{0: big.NewInt(5e+18)},
+		TreasurySchedule: ctypes.Uint64TreasuryMapEncodesHex{
+			200: {Address: common.HexToAddress("0x42"), Percentage: 10},
+			300: {Address: common.HexToAddress("0x42"), Percentage: 0},
+		},
+	}
+	custom := &customRewardConfig{
+		CoreGethChainConfig: vecno,
+		policy:              fixedRewardPolicy{recipient: common.HexToAddress("0x42"), amount: big.NewInt(1)},
+	}
+	cases := []struct {
+		config ctypes.ChainConfigurator
+		block  int64
+		want   RewardPolicy
+	}{
+		{classic, 99, EthereumRewardPolicy},
+		{classic, 100, ClassicRewardPolicy},
+		{vecno, 0, TreasuryRewardPolicy},
+		{forked, 99, EthereumRewardPolicy},
+		{forked, 199, ClassicRewardPolicy},
+		{forked, 200, TreasuryRewardPolicy},
+		{forked, 300, TreasuryRewardPolicy},
+		{custom, 0, custom.policy},
+	}
+	for i, c := range cases {
+		if got := RewardPolicyOf(c.config, big.NewInt(c.block)); got != c.want {
+			t.Errorf("case %d: policy mismatch, want: %T, got: %T", i, c.want, got)
+		}
+	}
+}
+
+func TestTreasuryRewardPolicy(t *testing.T) {
+	treasury := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	config := &coregeth.CoreGethChainConfig{
+		EthashB3:            new(ctypes.EthashB3Config),
+		BlockRewardSchedule: ctypes.Uint64BigMapEncodesHex{0: big.NewInt(1e+18)},
+		TreasurySchedule: ctypes.Uint64TreasuryMapEncodesHex{
+			0:   {Address: treasury, Percentage: 10},
+			100: nil,
+		},
+	}
+	header := &types.Header{Number: big.NewInt(1), Coinbase: WinnerCoinbase}
+	uncles := []*types.Header{{Number: big.NewInt(0), Coinbase: Uncle1Coinbase}}
+
+	want := []Reward{
+		{Recipient: WinnerCoinbase, Amount: big.NewInt(1e+18), Kind: RewardKindBlock},
+		{Recipient: Uncle1Coinbase, Amount: big.NewInt(0), Kind: RewardKindUncle},
+		{Recipient: treasury, Amount: big.NewInt(1e+17), Kind: RewardKindTreasury},
+	}
+	got := RewardPolicyOf(config, header.Number).Rewards(config, header, uncles, nil)
+	if len(got) != len(want) {
+		t.Fatalf("reward count mismatch, want: %d, got: %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Recipient != want[i].Recipient || got[i].Amount.Cmp(want[i].Amount) != 0 || got[i].Kind != want[i].Kind {
+			t.Errorf("reward %d mismatch, want: %v, got: %v", i, want[i], got[i])
+		}
+	}
+
+	// No treasury entry once the treasury is deactivated.
+	header.Number = big.NewInt(100)
+	if got := RewardPolicyOf(config, header.Number).Rewards(config, header, nil, nil); len(got) != 1 {
+		t.Errorf("reward count mismatch, want: 1, got: %d", len(got))
+	}
+}
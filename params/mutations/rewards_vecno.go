@@ -23,23 +23,27 @@ import (
 )
 
 var (
-	UncleBlockReward    = big.NewInt(0)
-	DevRewardPercentage = 10 // Set the developer reward percentage (10%)
-	DevWalletAddress    = "0x53839204723996D9487908b583D0eF92e14eEa17"
+	UncleBlockReward = big.NewInt(0)
 )
 
-// Function to calculate the developer reward based on the block reward
-func calculateDevReward(blockReward *big.Int) *big.Int {
-	devReward := new(big.Int).Mul(blockReward, big.NewInt(int64(DevRewardPercentage)))
+// calculateDevReward calculates the treasury (developer) reward as a percentage of the block reward.
+func calculateDevReward(blockReward *big.Int, percentage uint64) *big.Int {
+	devReward := new(big.Int).Mul(blockReward, new(big.Int).SetUint64(percentage))
 	devReward = new(big.Int).Div(devReward, big.NewInt(100))
 	return devReward
 }
 
+// GetRewardsVecno calculates the mining, treasury and uncle rewards for chains
+// configured with a treasury schedule.
+// The treasury reward is zero if no treasury is active at the header's height.
 func GetRewardsVecno(config ctypes.ChainConfigurator, header *types.Header, uncles []*types.Header, txs []*types.Transaction) (*big.Int, *big.Int, []*big.Int) {
 	// Select the correct block minerReward based on chain progression
 	blockReward := ctypes.EthashBlockReward(config, header.Number)
 	minerReward := new(big.Int).Set(blockReward)
-	devReward := calculateDevReward(blockReward)
+	devReward := new(big.Int)
+	if treasury := ctypes.EthashBlockTreasury(config, header.Number); treasury != nil {
+		devReward = calculateDevReward(blockReward, treasury.Percentage)
+	}
 	uncleReward := new(big.Int).Set(UncleBlockReward)
 	uncleCount := new(big.Int).SetUint64(uint64(len(uncles)))
 	blockFeeReward := new(big.Int)
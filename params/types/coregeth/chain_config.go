@@ -274,6 +274,14 @@ type CoreGethChainConfig struct {
 	DifficultyBombDelaySchedule ctypes.Uint64BigMapEncodesHex `json:"difficultyBombDelays,omitempty"` // JSON tag matches Parity's
 	BlockRewardSchedule         ctypes.Uint64BigMapEncodesHex `json:"blockReward,omitempty"`          // JSON tag matches Parity's
 
+	// TreasurySchedule defines the block-scheduled recipient and share of the block reward
+	// credited to the chain's development treasury.
+	TreasurySchedule ctypes.Uint64TreasuryMapEncodesHex `json:"treasury,omitempty"`
+
+	// DifficultyAlgorithmSchedule defines the block-scheduled difficulty adjustment algorithms
+	// and their target block times.
+	DifficultyAlgorithmSchedule ctypes.Uint64DifficultyAlgorithmMapEncodesHex `json:"difficultyAlgorithms,omitempty"`
+
 	RequireBlockHashes map[uint64]common.Hash `json:"requireBlockHashes"`
 }
 
@@ -1355,6 +1355,37 @@ func (c *CoreGethChainConfig) SetEthashBlockRewardSchedule(m ctypes.Uint64BigMap
 	return nil
 }
 
+func (c *CoreGethChainConfig) GetEthashTreasurySchedule() ctypes.Uint64TreasuryMapEncodesHex {
+	engineType := c.GetConsensusEngineType()
+	if engineType != ctypes.ConsensusEngineT_Ethash && engineType != ctypes.ConsensusEngineT_EthashB3 {
+		return nil
+	}
+	return c.TreasurySchedule
+}
+
+func (c *CoreGethChainConfig) SetEthashTreasurySchedule(m ctypes.Uint64TreasuryMapEncodesHex) error {
+	if c.Ethash == nil && c.EthashB3 == nil {
+		return ctypes.ErrUnsupportedConfigFatal
+	}
+	c.TreasurySchedule = m
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetEthashDifficultyAlgorithmSchedule() ctypes.Uint64DifficultyAlgorithmMapEncodesHex {
+	if c.GetConsensusEngineType() != ctypes.ConsensusEngineT_EthashB3 {
+		return nil
+	}
+	return c.DifficultyAlgorithmSchedule
+}
+
+func (c *CoreGethChainConfig) SetEthashDifficultyAlgorithmSchedule(m ctypes.Uint64DifficultyAlgorithmMapEncodesHex) error {
+	if c.EthashB3 == nil {
+		return ctypes.ErrUnsupportedConfigFatal
+	}
+	c.DifficultyAlgorithmSchedule = m
+	return nil
+}
+
 func (c *CoreGethChainConfig) GetCliquePeriod() uint64 {
 	if c.Clique == nil {
 		return 0
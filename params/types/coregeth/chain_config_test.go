@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params/confp"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 )
 
@@ -89,3 +90,52 @@ func TestCoreGethChainConfig_ECBP1100Deactivate(t *testing.T) {
 		t.Errorf("ECBP1100 should be deactivated at block %d", n)
 	}
 }
+
+func TestCoreGethChainConfig_TreasuryValid(t *testing.T) {
+	cases := []struct {
+		treasury ctypes.Uint64TreasuryMapEncodesHex
+		valid    bool
+	}{
+		{nil, true},
+		{ctypes.Uint64TreasuryMapEncodesHex{0: {Address: common.HexToAddress("0x42"), Percentage: 10}}, true},
+		{ctypes.Uint64TreasuryMapEncodesHex{0: {Address: common.HexToAddress("0x42"), Percentage: 10}, 100: {}}, true},
+		{ctypes.Uint64TreasuryMapEncodesHex{0: {Address: common.HexToAddress("0x42"), Percentage: 101}}, false},
+		{ctypes.Uint64TreasuryMapEncodesHex{0: {Percentage: 10}}, false},
+	}
+	for i, c := range cases {
+		conf := &CoreGethChainConfig{
+			NetworkID:        1,
+			EthashB3:         new(ctypes.EthashB3Config),
+			TreasurySchedule: c.treasury,
+		}
+		if err := confp.IsValid(conf, nil); (err == nil) != c.valid {
+			t.Errorf("case %d: want valid: %v, got: %v", i, c.valid, err)
+		}
+	}
+}
+
+func TestCoreGethChainConfig_DifficultyAlgorithmsValid(t *testing.T) {
+	cases := []struct {
+		algorithms ctypes.Uint64DifficultyAlgorithmMapEncodesHex
+		valid      bool
+	}{
+		{nil, true},
+		{ctypes.Uint64DifficultyAlgorithmMapEncodesHex{100: {Algorithm: "lwma", TargetBlockTime: 13, Window: 60}}, true},
+		{ctypes.Uint64DifficultyAlgorithmMapEncodesHex{100: {Algorithm: "asert", TargetBlockTime: 13, HalfLife: 3600}}, true},
+		{ctypes.Uint64DifficultyAlgorithmMapEncodesHex{100: {Algorithm: "lwma", TargetBlockTime: 13, Window: 60}, 200: {Algorithm: "legacy"}}, true},
+		{ctypes.Uint64DifficultyAlgorithmMapEncodesHex{100: {Algorithm: "lwma", TargetBlockTime: 13, Window: 1}}, false},
+		{ctypes.Uint64DifficultyAlgorithmMapEncodesHex{100: {Algorithm: "asert", TargetBlockTime: 13}}, false},
+		{ctypes.Uint64DifficultyAlgorithmMapEncodesHex{100: {Algorithm: "lwma", Window: 60}}, false},
+		{ctypes.Uint64DifficultyAlgorithmMapEncodesHex{100: {Algorithm: "digishield", TargetBlockTime: 13}}, false},
+	}
+	for i, c := range cases {
+		conf := &CoreGethChainConfig{
+			NetworkID:                   1,
+			EthashB3:                    new(ctypes.EthashB3Config),
+			DifficultyAlgorithmSchedule: c.algorithms,
+		}
+		if err := confp.IsValid(conf, nil); (err == nil) != c.valid {
+			t.Errorf("case %d: want valid: %v, got: %v", i, c.valid, err)
+		}
+	}
+}
@@ -333,6 +333,16 @@ type EthashConfigurator interface {
 	SetEthashDifficultyBombDelaySchedule(m Uint64BigMapEncodesHex) error
 	GetEthashBlockRewardSchedule() Uint64BigMapEncodesHex
 	SetEthashBlockRewardSchedule(m Uint64BigMapEncodesHex) error
+
+	// GetEthashTreasurySchedule returns the block-scheduled treasury (dev-fund) settings.
+	// When non-empty, the block reward is distributed according to the Vecno reward schema.
+	GetEthashTreasurySchedule() Uint64TreasuryMapEncodesHex
+	SetEthashTreasurySchedule(m Uint64TreasuryMapEncodesHex) error
+
+	// GetEthashDifficultyAlgorithmSchedule returns the block-scheduled difficulty adjustment
+	// algorithms, replacing the engine's built-in adjustment from their activation on.
+	GetEthashDifficultyAlgorithmSchedule() Uint64DifficultyAlgorithmMapEncodesHex
+	SetEthashDifficultyAlgorithmSchedule(m Uint64DifficultyAlgorithmMapEncodesHex) error
 }
 
 type CliqueConfigurator interface {
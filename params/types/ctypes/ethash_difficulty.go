@@ -0,0 +1,47 @@
+// Copyright 2024 The multi-geth Authors
+// This file is part of the multi-geth library.
+//
+// The multi-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The multi-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the multi-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package ctypes
+
+import (
+	"math/big"
+)
+
+// EthashDifficultyAlgorithm returns the difficulty algorithm active at block n,
+// along with the block it was activated at. It returns nil if no algorithm is
+// scheduled, or the active one is the engine's legacy adjustment.
+func EthashDifficultyAlgorithm(c ChainConfigurator, n *big.Int) (*DifficultyAlgorithmConfig, uint64) {
+	if c == nil || n == nil {
+		return nil, 0
+	}
+	var algorithm *DifficultyAlgorithmConfig
+
+	// Because the map is not necessarily sorted low-high, we
+	// have to ensure that we're walking upwards only.
+	var lastActivation uint64
+	for activation, a := range c.GetEthashDifficultyAlgorithmSchedule() {
+		if activation <= n.Uint64() { // Is forked
+			if activation >= lastActivation {
+				lastActivation = activation
+				algorithm = a
+			}
+		}
+	}
+	if algorithm == nil || algorithm.Algorithm == DifficultyAlgorithmLegacy {
+		return nil, 0
+	}
+	return algorithm, lastActivation
+}
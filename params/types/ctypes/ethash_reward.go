@@ -49,3 +49,43 @@ func EthashBlockReward(c ChainConfigurator, n *big.Int) *big.Int {
 
 	return blockReward
 }
+
+// EthashBlockTreasury returns the treasury configuration active at block n,
+// or nil if no treasury is scheduled or the active one credits nothing.
+func EthashBlockTreasury(c ChainConfigurator, n *big.Int) *TreasuryConfig {
+	if c == nil || n == nil {
+		return nil
+	}
+	var treasury *TreasuryConfig
+
+	// Because the map is not necessarily sorted low-high, we
+	// have to ensure that we're walking upwards only.
+	var lastActivation uint64
+	for activation, t := range c.GetEthashTreasurySchedule() {
+		if activation <= n.Uint64() { // Is forked
+			if activation >= lastActivation {
+				lastActivation = activation
+				treasury = t
+			}
+		}
+	}
+	if treasury == nil || treasury.Percentage == 0 {
+		return nil
+	}
+	return treasury
+}
+
+// EthashTreasuryActivated reports whether a treasury schedule entry is active at
+// block n, ie. whether n is at or past the first activation of the schedule.
+// It holds even if the active entry credits nothing.
+func EthashTreasuryActivated(c ChainConfigurator, n *big.Int) bool {
+	if c == nil || n == nil {
+		return false
+	}
+	for activation := range c.GetEthashTreasurySchedule() {
+		if activation <= n.Uint64() {
+			return true
+		}
+	}
+	return false
+}
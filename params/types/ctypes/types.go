@@ -164,6 +164,93 @@ func (b Uint64BigMapEncodesHex) MarshalJSON() ([]byte, error) {
 	return json.Marshal(mm)
 }
 
+// TreasuryConfig defines the recipient and the share of the block reward
+// credited to a chain's development treasury.
+type TreasuryConfig struct {
+	Address    common.Address `json:"address"`
+	Percentage uint64         `json:"percentage"` // Share of the block reward, in percent (0-100)
+}
+
+// Uint64TreasuryMapEncodesHex is a map of activation blocks to treasury configurations.
+// Its keys encode and decode w/ JSON hex format, like Uint64BigMapEncodesHex.
+type Uint64TreasuryMapEncodesHex map[uint64]*TreasuryConfig
+
+// UnmarshalJSON implements the json Unmarshaler interface.
+func (m *Uint64TreasuryMapEncodesHex) UnmarshalJSON(input []byte) error {
+	mm := make(map[math.HexOrDecimal64]*TreasuryConfig)
+	if err := json.Unmarshal(input, &mm); err != nil {
+		return err
+	}
+	mp := make(Uint64TreasuryMapEncodesHex)
+	for k, v := range mm {
+		if v != nil {
+			mp[uint64(k)] = v
+		}
+	}
+	*m = mp
+	return nil
+}
+
+// MarshalJSON implements the json Marshaler interface.
+func (m Uint64TreasuryMapEncodesHex) MarshalJSON() ([]byte, error) {
+	mm := make(map[math.HexOrDecimal64]*TreasuryConfig)
+	for k, v := range m {
+		if v == nil {
+			continue // should never happen
+		}
+		mm[math.HexOrDecimal64(k)] = v
+	}
+	return json.Marshal(mm)
+}
+
+// Difficulty adjustment algorithms which may be scheduled by a chain configuration.
+const (
+	DifficultyAlgorithmLegacy = "legacy" // The engine's built-in EIP-100 style adjustment
+	DifficultyAlgorithmLWMA   = "lwma"   // Linearly weighted moving average of recent solve times
+	DifficultyAlgorithmASERT  = "asert"  // Absolutely scheduled exponentially rising targets
+)
+
+// DifficultyAlgorithmConfig selects the difficulty adjustment algorithm of a
+// proof-of-work chain, along with its tuning parameters.
+type DifficultyAlgorithmConfig struct {
+	Algorithm       string `json:"algorithm"`
+	TargetBlockTime uint64 `json:"targetBlockTime,omitempty"` // Target time between blocks, in seconds
+	Window          uint64 `json:"window,omitempty"`          // LWMA: number of blocks the solve times are averaged over
+	HalfLife        uint64 `json:"halfLife,omitempty"`        // ASERT: schedule drift, in seconds, which halves or doubles the difficulty
+}
+
+// Uint64DifficultyAlgorithmMapEncodesHex is a map of activation blocks to difficulty algorithms.
+// Its keys encode and decode w/ JSON hex format, like Uint64BigMapEncodesHex.
+type Uint64DifficultyAlgorithmMapEncodesHex map[uint64]*DifficultyAlgorithmConfig
+
+// UnmarshalJSON implements the json Unmarshaler interface.
+func (m *Uint64DifficultyAlgorithmMapEncodesHex) UnmarshalJSON(input []byte) error {
+	mm := make(map[math.HexOrDecimal64]*DifficultyAlgorithmConfig)
+	if err := json.Unmarshal(input, &mm); err != nil {
+		return err
+	}
+	mp := make(Uint64DifficultyAlgorithmMapEncodesHex)
+	for k, v := range mm {
+		if v != nil {
+			mp[uint64(k)] = v
+		}
+	}
+	*m = mp
+	return nil
+}
+
+// MarshalJSON implements the json Marshaler interface.
+func (m Uint64DifficultyAlgorithmMapEncodesHex) MarshalJSON() ([]byte, error) {
+	mm := make(map[math.HexOrDecimal64]*DifficultyAlgorithmConfig)
+	for k, v := range m {
+		if v == nil {
+			continue // should never happen
+		}
+		mm[math.HexOrDecimal64(k)] = v
+	}
+	return json.Marshal(mm)
+}
+
 func (b Uint64BigMapEncodesHex) SetValueTotalForHeight(n *uint64, val *big.Int) {
 	if n == nil || val == nil {
 		return
@@ -151,6 +151,78 @@ func TestBigMapEncodesHex_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestUint64TreasuryMapEncodesHex_UnmarshalJSON(t *testing.T) {
+	type conf struct {
+		Treasury Uint64TreasuryMapEncodesHex `json:"treasury"`
+	}
+	input := []byte(`{"treasury": {
+		"0x0": {"address": "0x53839204723996d9487908b583d0ef92e14eea17", "percentage": 10},
+		"1000": {"address": "0x0000000000000000000000000000000000000042", "percentage": 5}
+	}}`)
+	c := conf{}
+	if err := json.Unmarshal(input, &c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Treasury) != 2 {
+		t.Fatalf("want 2 entries, got: %d", len(c.Treasury))
+	}
+	if c.Treasury[0].Address != common.HexToAddress("0x53839204723996d9487908b583d0ef92e14eea17") || c.Treasury[0].Percentage != 10 {
+		t.Errorf("mismatch @ 0: %v", c.Treasury[0])
+	}
+	if c.Treasury[1000].Address != common.HexToAddress("0x42") || c.Treasury[1000].Percentage != 5 {
+		t.Errorf("mismatch @ 1000: %v", c.Treasury[1000])
+	}
+
+	// Round trip.
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := conf{}
+	if err := json.Unmarshal(b, &c2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, c2) {
+		t.Errorf("round trip mismatch, got: %v, want: %v", c2, c)
+	}
+}
+
+func TestUint64DifficultyAlgorithmMapEncodesHex_UnmarshalJSON(t *testing.T) {
+	type conf struct {
+		Algorithms Uint64DifficultyAlgorithmMapEncodesHex `json:"difficultyAlgorithms"`
+	}
+	input := []byte(`{"difficultyAlgorithms": {
+		"0x3e8": {"algorithm": "lwma", "targetBlockTime": 13, "window": 60},
+		"2000": {"algorithm": "asert", "targetBlockTime": 13, "halfLife": 3600},
+		"3000": {"algorithm": "legacy"}
+	}}`)
+	c := conf{}
+	if err := json.Unmarshal(input, &c); err != nil {
+		t.Fatal(err)
+	}
+	want := Uint64DifficultyAlgorithmMapEncodesHex{
+		1000: {Algorithm: DifficultyAlgorithmLWMA, TargetBlockTime: 13, Window: 60},
+		2000: {Algorithm: DifficultyAlgorithmASERT, TargetBlockTime: 13, HalfLife: 3600},
+		3000: {Algorithm: DifficultyAlgorithmLegacy},
+	}
+	if !reflect.DeepEqual(c.Algorithms, want) {
+		t.Fatalf("mismatch, got: %v, want: %v", c.Algorithms, want)
+	}
+
+	// Round trip.
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := conf{}
+	if err := json.Unmarshal(b, &c2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, c2) {
+		t.Errorf("round trip mismatch, got: %v, want: %v", c2, c)
+	}
+}
+
 func TestUint64BigMapEncodesHex_SetValueTotalForHeight(t *testing.T) {
 	newMG := func() Uint64BigMapEncodesHex {
 		v := Uint64BigMapEncodesHex{}
@@ -1108,6 +1108,22 @@ func (g *Genesis) SetEthashBlockRewardSchedule(m ctypes.Uint64BigMapEncodesHex)
 	return g.Config.SetEthashBlockRewardSchedule(m)
 }
 
+func (g *Genesis) GetEthashTreasurySchedule() ctypes.Uint64TreasuryMapEncodesHex {
+	return g.Config.GetEthashTreasurySchedule()
+}
+
+func (g *Genesis) SetEthashTreasurySchedule(m ctypes.Uint64TreasuryMapEncodesHex) error {
+	return g.Config.SetEthashTreasurySchedule(m)
+}
+
+func (g *Genesis) GetEthashDifficultyAlgorithmSchedule() ctypes.Uint64DifficultyAlgorithmMapEncodesHex {
+	return g.Config.GetEthashDifficultyAlgorithmSchedule()
+}
+
+func (g *Genesis) SetEthashDifficultyAlgorithmSchedule(m ctypes.Uint64DifficultyAlgorithmMapEncodesHex) error {
+	return g.Config.SetEthashDifficultyAlgorithmSchedule(m)
+}
+
 func (g *Genesis) GetCliquePeriod() uint64 {
 	return g.Config.GetCliquePeriod()
 }
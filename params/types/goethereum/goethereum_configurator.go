@@ -1182,6 +1182,22 @@ func (c *ChainConfig) SetEthashBlockRewardSchedule(m ctypes.Uint64BigMapEncodesH
 	return ctypes.ErrUnsupportedConfigNoop
 }
 
+func (c *ChainConfig) GetEthashTreasurySchedule() ctypes.Uint64TreasuryMapEncodesHex {
+	return nil
+}
+
+func (c *ChainConfig) SetEthashTreasurySchedule(m ctypes.Uint64TreasuryMapEncodesHex) error {
+	return ctypes.ErrUnsupportedConfigNoop
+}
+
+func (c *ChainConfig) GetEthashDifficultyAlgorithmSchedule() ctypes.Uint64DifficultyAlgorithmMapEncodesHex {
+	return nil
+}
+
+func (c *ChainConfig) SetEthashDifficultyAlgorithmSchedule(m ctypes.Uint64DifficultyAlgorithmMapEncodesHex) error {
+	return ctypes.ErrUnsupportedConfigNoop
+}
+
 func (c *ChainConfig) GetCliquePeriod() uint64 {
 	if c.Clique == nil {
 		return 0
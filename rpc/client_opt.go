@@ -41,6 +41,8 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	callLimiter        CallLimiter
+	responseCache      ResponseCache
 }
 
 func (cfg *clientConfig) initHeaders() {
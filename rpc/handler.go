@@ -62,6 +62,8 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	limiter              CallLimiter
+	cache                ResponseCache
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -491,6 +493,13 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if h.limiter != nil && !msg.isUnsubscribe() {
+		release, err := h.limiter.Acquire(cp.ctx, msg.Method)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -503,6 +512,12 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	cacheable := h.cache != nil && callb != h.unsubscribeCb
+	if cacheable {
+		if result, ok := h.cache.Get(cp.ctx, msg.Method, msg.Params); ok {
+			return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: result}
+		}
+	}
 
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
@@ -510,6 +525,9 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	}
 	start := time.Now()
 	answer := h.runMethod(cp.ctx, msg, callb, args)
+	if cacheable && answer.Error == nil {
+		h.cache.Put(cp.ctx, msg.Method, msg.Params, answer.Result)
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
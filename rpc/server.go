@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"sync"
 	"sync/atomic"
@@ -51,6 +52,26 @@ type Server struct {
 	run                atomic.Bool
 	batchItemLimit     int
 	batchResponseLimit int
+	callLimiter        CallLimiter
+	responseCache      ResponseCache
+}
+
+// CallLimiter gates the method calls served by a Server, e.g. to rate limit its
+// clients.
+type CallLimiter interface {
+	// Acquire is called before a method call is served. It returns an error to
+	// reject the call, or a function to call once the call has been served.
+	Acquire(ctx context.Context, method string) (release func(), err error)
+}
+
+// ResponseCache caches the results of method calls served by a Server. The cache
+// decides which calls are cacheable, e.g. the ones whose results cannot change.
+type ResponseCache interface {
+	// Get returns the cached result of a call, if any.
+	Get(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, bool)
+
+	// Put offers the result of a successful call to the cache.
+	Put(ctx context.Context, method string, params json.RawMessage, result json.RawMessage)
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -78,6 +99,22 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetCallLimiter sets the limiter consulted before serving method calls.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetCallLimiter(limiter CallLimiter) {
+	s.callLimiter = limiter
+}
+
+// SetResponseCache sets the cache consulted before serving method calls.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetResponseCache(cache ResponseCache) {
+	s.responseCache = cache
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -103,6 +140,8 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		callLimiter:        s.callLimiter,
+		responseCache:      s.responseCache,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -138,6 +177,8 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.limiter = s.callLimiter
+	h.cache = s.responseCache
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -47,6 +47,12 @@ type ServerCodec interface {
 	jsonWriter
 }
 
+// requestContexter is implemented by server codecs whose connection was established
+// by a request, e.g. a WebSocket upgrade, to serve calls within the request context.
+type requestContexter interface {
+	requestContext() context.Context
+}
+
 // jsonWriter can write JSON messages to its underlying connection.
 // Implementations must be safe for concurrent use.
 type jsonWriter interface {
@@ -61,6 +61,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			return
 		}
 		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
+		codec.(*websocketCodec).ctx = r.Context()
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -281,6 +282,7 @@ type websocketCodec struct {
 	*jsonCodec
 	conn *websocket.Conn
 	info PeerInfo
+	ctx  context.Context // context of the upgrade request, nil on the client side
 
 	wg           sync.WaitGroup
 	pingReset    chan struct{}
@@ -328,6 +330,10 @@ func (wc *websocketCodec) peerInfo() PeerInfo {
 	return wc.info
 }
 
+func (wc *websocketCodec) requestContext() context.Context {
+	return wc.ctx
+}
+
 func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}, isError bool) error {
 	err := wc.jsonCodec.writeJSON(ctx, v, isError)
 	if err == nil {